@@ -0,0 +1,126 @@
+package gorker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// ScaleReasonAdaptive is the reason of resizes by WithAdaptiveConcurrency
+const ScaleReasonAdaptive = "adaptive"
+
+// AdaptiveConfig configures the AIMD controller of WithAdaptiveConcurrency
+type AdaptiveConfig struct {
+	// Min and Max bound the worker count, Min defaults to 1 and Max to 100
+	Min int
+	Max int
+	// TargetLatency is the reported latency above which the worker count decreases,
+	// 0 ignores latency
+	TargetLatency time.Duration
+	// MaxErrorRate is the share of failed jobs above which the worker count decreases,
+	// 0 ignores errors
+	MaxErrorRate float64
+	// Interval is how often the worker count is adjusted, 1s by default
+	Interval time.Duration
+	// Increase is the number of workers added per interval while healthy, 1 by default
+	Increase int
+	// Decrease is the factor applied to the worker count on overload, 0.75 by default
+	Decrease float64
+}
+
+type adaptive struct {
+	cfg AdaptiveConfig
+	// latency sums the reported latencies in nanoseconds since the last adjustment
+	latency  int64
+	reports  int64
+	finished uint64
+	failed   uint64
+}
+
+// WithAdaptiveConcurrency tunes the worker count with additive increase and multiplicative
+// decrease: while the latency reported by ReportLatency stays below cfg.TargetLatency and the
+// share of failed jobs below cfg.MaxErrorRate, workers are added as long as jobs are waiting,
+// otherwise the worker count shrinks by cfg.Decrease. The worker count starts at cfg.Min.
+func WithAdaptiveConcurrency(cfg AdaptiveConfig) Option {
+	return func(d *Dispatcher) {
+		if cfg.Min < 1 {
+			cfg.Min = 1
+		}
+		if cfg.Max < 1 {
+			cfg.Max = 100
+		}
+		if cfg.Max < cfg.Min {
+			cfg.Max = cfg.Min
+		}
+		if cfg.Interval <= 0 {
+			cfg.Interval = time.Second
+		}
+		if cfg.Increase < 1 {
+			cfg.Increase = 1
+		}
+		if cfg.Decrease <= 0 || cfg.Decrease >= 1 {
+			cfg.Decrease = 0.75
+		}
+		d.adaptive = &adaptive{cfg: cfg}
+		d.workerCount = cfg.Min
+		d.workers = make([]*worker, cfg.Min)
+		for i := range d.workers {
+			d.workers[i] = newWorker(d)
+		}
+		d.resetBuffer()
+	}
+}
+
+func ReportLatency(latency time.Duration) {
+	instance.ReportLatency(latency)
+}
+
+// ReportLatency reports the latency of a downstream call made by a job to the controller
+// of WithAdaptiveConcurrency, it is a no-op without it
+func (d *Dispatcher) ReportLatency(latency time.Duration) {
+	if a := d.adaptive; a != nil {
+		atomic.AddInt64(&a.latency, int64(latency))
+		atomic.AddInt64(&a.reports, 1)
+	}
+}
+
+func (a *adaptive) run(ctx context.Context, d *Dispatcher) {
+	ticker := d.clock.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	a.finished, a.failed = d.finishedJobs(), atomic.LoadUint64(&d.failed)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			a.adjust(d)
+		}
+	}
+}
+
+// adjust applies one AIMD step from the reports and completions since the last one
+func (a *adaptive) adjust(d *Dispatcher) {
+	sum, reports := atomic.SwapInt64(&a.latency, 0), atomic.SwapInt64(&a.reports, 0)
+	finished, failed := d.finishedJobs(), atomic.LoadUint64(&d.failed)
+	dfinished, dfailed := finished-a.finished, failed-a.failed
+	a.finished, a.failed = finished, failed
+
+	d.mu.RLock()
+	n := d.workerCount
+	d.mu.RUnlock()
+	cfg := a.cfg
+	overloaded := cfg.TargetLatency > 0 && reports > 0 && time.Duration(sum/reports) > cfg.TargetLatency
+	if cfg.MaxErrorRate > 0 && dfinished > 0 && float64(dfailed)/float64(dfinished) > cfg.MaxErrorRate {
+		overloaded = true
+	}
+	switch {
+	case overloaded:
+		if next := max(cfg.Min, int(float64(n)*cfg.Decrease)); next < n {
+			d.downScale(next, ScaleReasonAdaptive)
+		}
+	case d.queueDepth() > 0:
+		if next := min(cfg.Max, n+cfg.Increase); next > n {
+			d.upScale(next, ScaleReasonAdaptive)
+		}
+	}
+}
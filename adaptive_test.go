@@ -0,0 +1,46 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAdaptive_adjust(t *testing.T) {
+	d := New(1, WithAdaptiveConcurrency(AdaptiveConfig{
+		Min:           2,
+		Max:           5,
+		TargetLatency: 100 * time.Millisecond,
+		MaxErrorRate:  0.5,
+		Increase:      2,
+		Decrease:      0.5,
+	}))
+	d.enqueue(&job{})
+
+	fail := errors.New("fail")
+	tests := []struct {
+		name    string
+		latency []time.Duration
+		errs    []error
+		workers int
+	}{
+		{name: "increase while jobs wait", workers: 4},
+		{name: "bounded by max", latency: []time.Duration{50 * time.Millisecond}, workers: 5},
+		{name: "decrease on latency", latency: []time.Duration{50 * time.Millisecond, 250 * time.Millisecond}, workers: 2},
+		{name: "bounded by min", latency: []time.Duration{time.Second}, workers: 2},
+		{name: "tolerated errors", errs: []error{nil, fail}, workers: 4},
+		{name: "decrease on errors", errs: []error{fail, fail, nil}, workers: 2},
+	}
+	for _, tt := range tests {
+		for _, l := range tt.latency {
+			d.ReportLatency(l)
+		}
+		for _, err := range tt.errs {
+			d.complete(&job{id: d.track()}, err)
+		}
+		d.adaptive.adjust(d)
+		if got := len(d.workers); got != tt.workers {
+			t.Errorf("%s: workers = %d, want %d", tt.name, got, tt.workers)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrNoWorker is returned by jobs pinned to a worker index the Dispatcher does not have
+var ErrNoWorker = errors.New("gorker: no such worker")
+
+// WithAffinity pins jobs to workers, a job for which hash returns true runs on the worker
+// at hash modulo the worker count, so related jobs share the caches of a worker.
+// Other jobs use the shared queue. Pinned jobs fall back to the shared queue with
+// on demand workers and when their worker is removed by a down scale.
+func WithAffinity(hash func(JobInfo) (uint64, bool)) Option {
+	return func(d *Dispatcher) {
+		d.affinity = hash
+	}
+}
+
+func AddToWorker(idx int, fn func() error, opts ...JobOption) chan error {
+	return instance.AddToWorker(idx, fn, opts...)
+}
+
+// AddToWorker submits fn to the inbox of the worker at idx, the job fails with
+// ErrNoWorker when idx is not below the worker count
+func (d *Dispatcher) AddToWorker(idx int, fn func() error, opts ...JobOption) chan error {
+	return d.submit(&job{
+		fn: func(context.Context) error {
+			return fn()
+		},
+		pinned: true,
+		worker: idx,
+	}, opts)
+}
+
+// pin hands j to the inbox of its worker and reports whether it did
+func (d *Dispatcher) pin(j *job) (bool, error) {
+	var hash uint64
+	hashed := false
+	if !j.pinned {
+		if d.affinity == nil {
+			return false, nil
+		}
+		if hash, hashed = d.affinity(j.info()); !hashed {
+			return false, nil
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.onDemand != nil || len(d.workers) == 0 {
+		return false, nil
+	}
+	idx := j.worker
+	if hashed {
+		idx = int(hash % uint64(len(d.workers)))
+	}
+	if idx < 0 || idx >= len(d.workers) {
+		return false, ErrNoWorker
+	}
+	w := d.workers[idx]
+	w.inbox = append(w.inbox, j)
+	atomic.AddInt32(&w.pinned, 1)
+	d.notify()
+	return true, nil
+}
+
+// takePinned returns the next job of the inbox of w, nil when empty or paused
+func (w *worker) takePinned() *job {
+	if atomic.LoadInt32(&w.pinned) == 0 {
+		return nil
+	}
+	d := w.dis
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.State().dispatching() || len(w.inbox) == 0 {
+		return nil
+	}
+	j := w.inbox[0]
+	w.inbox[0] = nil
+	w.inbox = w.inbox[1:]
+	atomic.AddInt32(&w.pinned, -1)
+	return j
+}
+
+// unpin moves the inbox of a removed worker to the shared queue, d.mu must be held
+func (d *Dispatcher) unpin(w *worker) {
+	if len(w.inbox) == 0 {
+		return
+	}
+	for _, j := range w.inbox {
+		d.queue = d.push(d.queue, j)
+	}
+	w.inbox = nil
+	atomic.StoreInt32(&w.pinned, 0)
+	d.notify()
+}
+
+// pinnedDepth counts the jobs waiting in worker inboxes, d.mu must be held
+func (d *Dispatcher) pinnedDepth() int {
+	n := 0
+	for _, w := range d.workers {
+		n += len(w.inbox)
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"testing"
+)
+
+func TestWithAffinity(t *testing.T) {
+	hash := func(info JobInfo) (uint64, bool) {
+		if len(info.Tags) == 0 {
+			return 0, false
+		}
+		h := fnv.New64a()
+		h.Write([]byte(info.Tags[0]))
+		return h.Sum64(), true
+	}
+	d := New(4, WithAffinity(hash)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var mu sync.Mutex
+	workers := make(map[string]map[uint64]bool)
+	for i := 0; i < 200; i++ {
+		key := []string{"a", "b", "c", "d", "e"}[i%5]
+		d.AddJob(func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if workers[key] == nil {
+				workers[key] = make(map[uint64]bool)
+			}
+			workers[key][WorkerScopeFrom(ctx).ID] = true
+			return nil
+		}, WithTags(key))
+	}
+	unpinned := d.Add(func() error { return nil })
+	d.Wait()
+	if err := <-unpinned; err != nil {
+		t.Fatal(err)
+	}
+	for key, ids := range workers {
+		if len(ids) != 1 {
+			t.Errorf("jobs tagged %s ran on %d workers, want 1", key, len(ids))
+		}
+	}
+}
+
+func TestDispatcher_AddToWorker(t *testing.T) {
+	tests := []struct {
+		name string
+		idx  int
+		want error
+	}{
+		{name: "first worker", idx: 0},
+		{name: "last worker", idx: 2},
+		{name: "out of range", idx: 3, want: ErrNoWorker},
+		{name: "negative", idx: -1, want: ErrNoWorker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(3).QueueRunner().Start()
+			defer d.Stop(true)
+			if err := <-d.AddToWorker(tt.idx, func() error { return nil }); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_AddToWorker_DownScale(t *testing.T) {
+	d := New(2).QueueRunner().Start().Pause()
+	defer d.Stop(true)
+
+	ech := d.AddToWorker(1, func() error { return nil })
+	if got := d.Stats().QueueDepth; got != 1 {
+		t.Fatalf("queue depth = %d, want 1", got)
+	}
+	// the inbox of the removed worker moves to the shared queue
+	d.DownScale(1)
+	d.Resume()
+	if err := <-ech; err != nil {
+		t.Fatal(err)
+	}
+}
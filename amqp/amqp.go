@@ -0,0 +1,213 @@
+// Package amqp dispatches the deliveries of a RabbitMQ queue to a gorker Dispatcher.
+//
+// The prefetch count of the channel is tied to the worker count of the Dispatcher, so
+// the broker only hands out as many unacknowledged deliveries as the pool can run.
+// A delivery is acknowledged when its Handler succeeded and negatively acknowledged
+// otherwise, transient errors requeue it while permanent ones let the broker drop it or
+// route it to the dead letter exchange of the queue. The package does not depend on an
+// AMQP client, Source is implemented on top of the client in use.
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kpango/gorker"
+)
+
+// Acknowledger settles deliveries, it matches the Acknowledger of amqp091-go
+type Acknowledger interface {
+	Ack(tag uint64, multiple bool) error
+	Nack(tag uint64, multiple, requeue bool) error
+	Reject(tag uint64, requeue bool) error
+}
+
+// Delivery is a message consumed from a queue
+type Delivery struct {
+	Acknowledger Acknowledger
+	DeliveryTag  uint64
+	Redelivered  bool
+	Exchange     string
+	RoutingKey   string
+	ContentType  string
+	MessageID    string
+	Headers      map[string]any
+	Body         []byte
+}
+
+// Source is a channel consuming from a queue
+type Source interface {
+	// Qos sets the number of unacknowledged deliveries the broker sends
+	Qos(prefetch int) error
+	// Consume starts the consumer, the channel is closed when the consumer was canceled
+	Consume(ctx context.Context) (<-chan Delivery, error)
+}
+
+// Handler processes a delivery
+type Handler func(ctx context.Context, d Delivery) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as permanent, the default requeue policy does not requeue its delivery
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsTransient is the default requeue policy, it requeues every error not marked by Permanent
+func IsTransient(err error) bool {
+	var pe *permanentError
+	return !errors.As(err, &pe)
+}
+
+// AckError is returned by Run when a delivery could not be settled
+type AckError struct {
+	DeliveryTag uint64
+	Err         error
+}
+
+func (e *AckError) Error() string {
+	return fmt.Sprintf("amqp: settling delivery %d: %v", e.DeliveryTag, e.Err)
+}
+
+func (e *AckError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Consumer
+type Option func(*Consumer)
+
+// WithPrefetch sets the prefetch count instead of the worker count of the Dispatcher
+func WithPrefetch(n int) Option {
+	return func(c *Consumer) {
+		if n > 0 {
+			c.prefetch = n
+		}
+	}
+}
+
+// WithRequeue sets the policy deciding whether a failed delivery is requeued, see IsTransient
+func WithRequeue(transient func(error) bool) Option {
+	return func(c *Consumer) {
+		if transient != nil {
+			c.transient = transient
+		}
+	}
+}
+
+// WithJobOptions applies opts to the job of every delivery
+func WithJobOptions(opts ...gorker.JobOption) Option {
+	return func(c *Consumer) {
+		c.jobOpts = append(c.jobOpts, opts...)
+	}
+}
+
+// Consumer consumes deliveries from a Source and handles them on a Dispatcher
+type Consumer struct {
+	dis       *gorker.Dispatcher
+	src       Source
+	handler   Handler
+	prefetch  int
+	transient func(error) bool
+	jobOpts   []gorker.JobOption
+}
+
+type result struct {
+	delivery Delivery
+	err      error
+}
+
+// New returns a Consumer handling the deliveries of src with h on d
+func New(d *gorker.Dispatcher, src Source, h Handler, opts ...Option) *Consumer {
+	c := &Consumer{
+		dis:       d,
+		src:       src,
+		handler:   h,
+		transient: IsTransient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run consumes deliveries until ctx is canceled, the delivery channel is closed or a
+// delivery could not be settled. Running deliveries are awaited and settled before Run
+// returns, it returns nil when the delivery channel was closed.
+func (c *Consumer) Run(ctx context.Context) error {
+	prefetch := c.prefetch
+	if prefetch < 1 {
+		prefetch = c.dis.GetWorkerCount()
+	}
+	if err := c.src.Qos(prefetch); err != nil {
+		return err
+	}
+	cctx, stop := context.WithCancel(ctx)
+	defer stop()
+	deliveries, err := c.src.Consume(cctx)
+	if err != nil {
+		return err
+	}
+
+	results := make(chan result, prefetch)
+	done := ctx.Done()
+	running := 0
+	for deliveries != nil || running > 0 {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				deliveries = nil
+				continue
+			}
+			running++
+			ech := c.dis.AddJob(func(ctx context.Context) error {
+				return c.handler(ctx, d)
+			}, c.jobOpts...)
+			go func() {
+				results <- result{delivery: d, err: <-ech}
+			}()
+		case res := <-results:
+			running--
+			if serr := c.settle(res); serr != nil && err == nil {
+				err = serr
+				stop()
+				// unsettled deliveries are requeued by the broker once the consumer is canceled
+				deliveries = nil
+			}
+		case <-done:
+			if err == nil {
+				err = ctx.Err()
+			}
+			done, deliveries = nil, nil
+		}
+	}
+	return err
+}
+
+// settle acknowledges a handled delivery
+func (c *Consumer) settle(res result) error {
+	d := res.delivery
+	var err error
+	if res.err == nil {
+		err = d.Acknowledger.Ack(d.DeliveryTag, false)
+	} else {
+		err = d.Acknowledger.Nack(d.DeliveryTag, false, c.transient(res.err))
+	}
+	if err != nil {
+		return &AckError{DeliveryTag: d.DeliveryTag, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/kpango/gorker"
+)
+
+type settlement struct {
+	acked, requeued bool
+}
+
+// fakeChannel delivers its bodies and records how each delivery was settled
+type fakeChannel struct {
+	mu       sync.Mutex
+	bodies   []string
+	prefetch int
+	settled  map[uint64]settlement
+	ackErr   error
+	keepOpen bool
+}
+
+func (c *fakeChannel) Qos(prefetch int) error {
+	c.prefetch = prefetch
+	return nil
+}
+
+func (c *fakeChannel) Consume(ctx context.Context) (<-chan Delivery, error) {
+	ch := make(chan Delivery)
+	go func() {
+		defer close(ch)
+		for i, body := range c.bodies {
+			select {
+			case ch <- Delivery{Acknowledger: c, DeliveryTag: uint64(i + 1), Body: []byte(body)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if c.keepOpen {
+			<-ctx.Done()
+		}
+	}()
+	return ch, nil
+}
+
+func (c *fakeChannel) Ack(tag uint64, _ bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settled[tag] = settlement{acked: true}
+	return c.ackErr
+}
+
+func (c *fakeChannel) Nack(tag uint64, _, requeue bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settled[tag] = settlement{requeued: requeue}
+	return c.ackErr
+}
+
+func (c *fakeChannel) Reject(tag uint64, requeue bool) error {
+	return c.Nack(tag, false, requeue)
+}
+
+func TestConsumer_Run(t *testing.T) {
+	errTransient := errors.New("unavailable")
+	handler := func(_ context.Context, d Delivery) error {
+		switch string(d.Body) {
+		case "transient":
+			return errTransient
+		case "permanent":
+			return Permanent(errors.New("malformed"))
+		}
+		return nil
+	}
+	tests := []struct {
+		name     string
+		opts     []Option
+		prefetch int
+		want     map[uint64]settlement
+	}{
+		{
+			name:     "default policy",
+			prefetch: 3,
+			want: map[uint64]settlement{
+				1: {acked: true},
+				2: {requeued: true},
+				3: {},
+			},
+		},
+		{
+			name:     "custom policy and prefetch",
+			opts:     []Option{WithPrefetch(7), WithRequeue(func(error) bool { return false })},
+			prefetch: 7,
+			want: map[uint64]settlement{
+				1: {acked: true},
+				2: {},
+				3: {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := gorker.New(3).QueueRunner().Start()
+			defer d.Stop(true)
+
+			ch := &fakeChannel{
+				bodies:  []string{"ok", "transient", "permanent"},
+				settled: make(map[uint64]settlement),
+			}
+			if err := New(d, ch, handler, tt.opts...).Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if ch.prefetch != tt.prefetch {
+				t.Errorf("prefetch = %d, want %d", ch.prefetch, tt.prefetch)
+			}
+			for tag, want := range tt.want {
+				if got, ok := ch.settled[tag]; !ok || got != want {
+					t.Errorf("delivery %d settled as %+v, want %+v", tag, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConsumer_RunErrors(t *testing.T) {
+	d := gorker.New(1).QueueRunner().Start()
+	defer d.Stop(true)
+	ok := func(context.Context, Delivery) error { return nil }
+
+	errAck := errors.New("channel closed")
+	ch := &fakeChannel{bodies: []string{"a"}, settled: make(map[uint64]settlement), ackErr: errAck, keepOpen: true}
+	var ae *AckError
+	if err := New(d, ch, ok).Run(context.Background()); !errors.As(err, &ae) || !errors.Is(err, errAck) {
+		t.Errorf("Run = %v, want an AckError", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ch = &fakeChannel{settled: make(map[uint64]settlement), keepOpen: true}
+	if err := New(d, ch, ok).Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,164 @@
+package gorker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var backfillPollInterval = 10 * time.Millisecond
+
+// BackfillConfig controls how fast a backfill is fed into a Dispatcher
+type BackfillConfig struct {
+	// Rate caps backfill submissions per second, 0 means unlimited
+	Rate float64
+	// MaxPending is the queue depth above which backfill submissions pause
+	// so that live traffic is served first, 0 means the worker count
+	MaxPending int
+	// Class is the priority class of the backfill jobs on a Dispatcher with priority
+	// classes, the lowest class when empty
+	Class string
+}
+
+// BackfillProgress is a point in time view of a running backfill
+type BackfillProgress struct {
+	Total     int64 `json:"total"`
+	Submitted int64 `json:"submitted"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+}
+
+// Backfill is a throttled low priority batch running on a Dispatcher
+type Backfill struct {
+	total     int64
+	submitted int64
+	succeeded int64
+	failed    int64
+	firstErr  error
+	errOnce   sync.Once
+	ctxErr    error
+	wg        *sync.WaitGroup
+	done      chan struct{}
+}
+
+// Backfill feeds jobs into d at the rate and depth allowed by cfg.
+// Submissions pause while the queue is deeper than cfg.MaxPending, and on a Dispatcher
+// with priority classes the jobs are queued in the lowest class, so the batch only uses
+// the capacity left over by live traffic. Jobs rejected by d count as failed.
+func (d *Dispatcher) Backfill(ctx context.Context, cfg BackfillConfig, jobs ...func() error) *Backfill {
+	b := &Backfill{
+		total: int64(len(jobs)),
+		wg:    new(sync.WaitGroup),
+		done:  make(chan struct{}),
+	}
+	go b.feed(ctx, d, cfg, jobs)
+	return b
+}
+
+func (b *Backfill) feed(ctx context.Context, d *Dispatcher, cfg BackfillConfig, jobs []func() error) {
+	defer close(b.done)
+
+	var tick <-chan time.Time
+	if cfg.Rate > 0 {
+		ticker := d.clock.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+		defer ticker.Stop()
+		tick = ticker.C()
+	}
+
+	var opts []JobOption
+	if d.classes != nil {
+		class := cfg.Class
+		if class == "" {
+			class = d.classes.names[len(d.classes.names)-1]
+		}
+		opts = append(opts, WithClass(class))
+	}
+	opts = append(opts, onDone(func(err error) {
+		if err != nil {
+			atomic.AddInt64(&b.failed, 1)
+			b.errOnce.Do(func() {
+				b.firstErr = err
+			})
+		} else {
+			atomic.AddInt64(&b.succeeded, 1)
+		}
+		b.wg.Done()
+	}))
+
+loop:
+	for _, fn := range jobs {
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				b.ctxErr = ctx.Err()
+				break loop
+			case <-tick:
+			}
+		}
+		for d.queueDepth() >= b.maxPending(d, cfg) {
+			timer := d.clock.NewTimer(backfillPollInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				b.ctxErr = ctx.Err()
+				break loop
+			case <-timer.C():
+			}
+		}
+		if ctx.Err() != nil {
+			b.ctxErr = ctx.Err()
+			break
+		}
+		b.wg.Add(1)
+		atomic.AddInt64(&b.submitted, 1)
+		d.Add(fn, opts...)
+	}
+	b.wg.Wait()
+}
+
+func (b *Backfill) maxPending(d *Dispatcher, cfg BackfillConfig) int {
+	if cfg.MaxPending > 0 {
+		return cfg.MaxPending
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.workerCount > 0 {
+		return d.workerCount
+	}
+	return 1
+}
+
+// Progress returns the current progress of the backfill
+func (b *Backfill) Progress() BackfillProgress {
+	return BackfillProgress{
+		Total:     b.total,
+		Submitted: atomic.LoadInt64(&b.submitted),
+		Succeeded: atomic.LoadInt64(&b.succeeded),
+		Failed:    atomic.LoadInt64(&b.failed),
+	}
+}
+
+// Done is closed when the backfill has finished or was canceled
+func (b *Backfill) Done() <-chan struct{} {
+	return b.done
+}
+
+// Wait blocks until the backfill has finished and reports failed jobs or cancellation
+func (b *Backfill) Wait() error {
+	<-b.done
+	if b.ctxErr != nil {
+		return b.ctxErr
+	}
+	if b.firstErr != nil {
+		return fmt.Errorf("gorker: %d of %d backfill jobs failed: %w", atomic.LoadInt64(&b.failed), b.total, b.firstErr)
+	}
+	return nil
+}
+
+func (d *Dispatcher) queueDepth() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.queue) + len(d.qin) + len(d.qout) + d.pinnedDepth()
+}
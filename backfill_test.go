@@ -0,0 +1,105 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Backfill(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	jobs := make([]func() error, 20)
+	for i := range jobs {
+		jobs[i] = func() error {
+			return nil
+		}
+	}
+	jobs[5] = func() error {
+		return errors.New("fail")
+	}
+
+	start := time.Now()
+	b := d.Backfill(context.Background(), BackfillConfig{Rate: 200}, jobs...)
+	err := b.Wait()
+	if err == nil {
+		t.Error("Wait() = nil, want error")
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("backfill finished in %v, rate limit not applied", elapsed)
+	}
+	want := BackfillProgress{Total: 20, Submitted: 20, Succeeded: 19, Failed: 1}
+	if got := b.Progress(); got != want {
+		t.Errorf("Progress() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDispatcher_Backfill_Canceled(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := make([]func() error, 1000)
+	for i := range jobs {
+		jobs[i] = func() error {
+			return nil
+		}
+	}
+	b := d.Backfill(ctx, BackfillConfig{Rate: 100}, jobs...)
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	if err := b.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want %v", err, context.Canceled)
+	}
+	if p := b.Progress(); p.Submitted == p.Total {
+		t.Errorf("all jobs submitted after cancel: %+v", p)
+	}
+}
+
+func TestDispatcher_Backfill_Rejected(t *testing.T) {
+	d := New(1).Start().Kill()
+
+	b := d.Backfill(context.Background(), BackfillConfig{MaxPending: 10}, func() error { return nil })
+	select {
+	case <-b.Done():
+	case <-time.After(time.Second):
+		t.Fatal("backfill with rejected jobs did not finish")
+	}
+	if err := b.Wait(); !errors.Is(err, ErrStopped) {
+		t.Errorf("Wait() = %v, want %v", err, ErrStopped)
+	}
+	want := BackfillProgress{Total: 1, Submitted: 1, Failed: 1}
+	if got := b.Progress(); got != want {
+		t.Errorf("Progress() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDispatcher_Backfill_Class(t *testing.T) {
+	d := New(1, WithPriorityClasses("live", "bulk")).QueueRunner().Start().Pause()
+	defer d.Stop(true)
+
+	var order []string
+	record := func(name string) func() error {
+		return func() error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	b := d.Backfill(context.Background(), BackfillConfig{MaxPending: 10}, record("backfill"), record("backfill"))
+	waitQueued(t, d, 2)
+	live := d.Add(record("live"), WithClass("live"))
+	waitQueued(t, d, 3)
+	d.Resume()
+	<-live
+	if err := b.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 3 || order[0] != "live" {
+		t.Errorf("order = %v, want the live job first", order)
+	}
+	if got := d.ClassStats()["bulk"].Succeeded; got != 2 {
+		t.Errorf("bulk class succeeded = %d, want 2", got)
+	}
+}
@@ -0,0 +1,128 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// tracker records the submitted jobs which did not complete yet. Job IDs grow
+// with every submission, so the jobs submitted before a point are those with a
+// lower ID and a Barrier only waits for the lowest pending ID to pass its own.
+type tracker struct {
+	mu sync.Mutex
+	// pending maps the pending IDs to the channels of their WaitFor callers
+	pending map[JobID][]chan error
+	// last is the highest tracked ID, low the lowest pending one or last+1
+	last    JobID
+	low     JobID
+	waiters []*Barrier
+	// failed keeps the errors of the last failures for WaitFor in the order of failures,
+	// forgotten is the highest ID whose error was dropped
+	failed    map[JobID]error
+	failures  []JobID
+	forgotten JobID
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		pending: make(map[JobID][]chan error),
+		failed:  make(map[JobID]error),
+		low:     1,
+	}
+}
+
+// track assigns the next job ID of d and records it as pending
+func (d *Dispatcher) track() JobID {
+	t := d.tracker
+	t.mu.Lock()
+	id := JobID(atomic.AddUint64(&d.jobSeq, 1))
+	t.pending[id] = nil
+	if t.low > t.last {
+		t.low = id
+	}
+	t.last = id
+	t.mu.Unlock()
+	return id
+}
+
+// withID submits a job under an ID taken by track before, so that the barriers
+// created while the job was held back wait for it
+func withID(id JobID) JobOption {
+	return func(j *job) {
+		j.id = id
+	}
+}
+
+// untrack records id as completed with err and releases the barriers and WaitFor callers it held
+func (t *tracker) untrack(id JobID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ch := range t.pending[id] {
+		ch <- err
+	}
+	delete(t.pending, id)
+	if err != nil {
+		t.fail(id, err)
+	}
+	if id != t.low {
+		return
+	}
+	for t.low <= t.last {
+		if _, ok := t.pending[t.low]; ok {
+			break
+		}
+		t.low++
+	}
+	n := 0
+	for ; n < len(t.waiters) && t.waiters[n].id < t.low; n++ {
+		close(t.waiters[n].done)
+		t.waiters[n] = nil
+	}
+	t.waiters = t.waiters[n:]
+}
+
+// Barrier waits for the jobs submitted to a Dispatcher before it was created
+type Barrier struct {
+	id   JobID
+	done chan struct{}
+}
+
+func NewBarrier() *Barrier {
+	return instance.Barrier()
+}
+
+// Barrier returns a Barrier for the jobs submitted to d so far. Jobs submitted
+// afterwards, also concurrently with the call, are not waited for.
+func (d *Dispatcher) Barrier() *Barrier {
+	t := d.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &Barrier{id: t.last, done: make(chan struct{})}
+	if b.id < t.low {
+		close(b.done)
+	} else {
+		t.waiters = append(t.waiters, b)
+	}
+	return b
+}
+
+// Done is closed once every job submitted before b completed
+func (b *Barrier) Done() <-chan struct{} {
+	return b.done
+}
+
+// Wait blocks until every job submitted before b completed
+func (b *Barrier) Wait() {
+	<-b.done
+}
+
+// WaitContext is Wait bounded by ctx, it returns ctx.Err() when ctx is done first
+func (b *Barrier) WaitContext(ctx context.Context) error {
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
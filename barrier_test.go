@@ -0,0 +1,67 @@
+package gorker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Barrier(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	select {
+	case <-d.Barrier().Done():
+	default:
+		t.Fatal("barrier of an idle dispatcher is not done")
+	}
+
+	before, after := make(chan struct{}), make(chan struct{})
+	d.Add(func() error {
+		<-before
+		return nil
+	})
+	b := d.Barrier()
+	later := d.Add(func() error {
+		<-after
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("WaitContext = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(before)
+	select {
+	case <-b.Done():
+	case <-time.After(time.Second):
+		t.Fatal("barrier waited for a job submitted after it")
+	}
+	close(after)
+	if err := <-later; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	d := New(1)
+	ids := []JobID{d.track(), d.track(), d.track()}
+	b := d.Barrier()
+	d.tracker.untrack(ids[0], nil)
+	d.tracker.untrack(ids[2], nil)
+	select {
+	case <-b.Done():
+		t.Fatal("barrier done with a pending job")
+	default:
+	}
+	d.tracker.untrack(ids[1], nil)
+	select {
+	case <-b.Done():
+	default:
+		t.Fatal("barrier not done after every job completed")
+	}
+	if len(d.tracker.pending) != 0 || len(d.tracker.waiters) != 0 {
+		t.Errorf("tracker not empty: %d pending, %d waiters", len(d.tracker.pending), len(d.tracker.waiters))
+	}
+}
@@ -0,0 +1,165 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// ErrSkipped is matched by errors.Is for jobs which were not executed
+// because an earlier job of the same batch failed
+var ErrSkipped = errors.New("gorker: job skipped")
+
+// SkippedError is returned by batch jobs skipped after the batch failed
+type SkippedError struct {
+	Cause error
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("gorker: job skipped after batch failure: %v", e.Cause)
+}
+
+func (e *SkippedError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *SkippedError) Is(target error) bool {
+	return target == ErrSkipped
+}
+
+// BatchOption configures a Batch
+type BatchOption func(*Batch)
+
+// CancelOnFirstError makes the first failed job of a batch cancel the remaining
+// jobs of that batch only, the Dispatcher and other batches keep running.
+func CancelOnFirstError() BatchOption {
+	return func(b *Batch) {
+		b.cancelOnError = true
+	}
+}
+
+// BatchResult is the result of a job of a Batch, Index is the position of its Add call
+type BatchResult struct {
+	Index int
+	Err   error
+}
+
+// Batch is a group of jobs submitted to a Dispatcher and awaited together
+type Batch struct {
+	dis           *Dispatcher
+	ctx           context.Context
+	cancel        context.CancelCauseFunc
+	cancelOnError bool
+	wg            *sync.WaitGroup
+	mu            *sync.Mutex
+	errs          []error
+	// results are in completion order, changed is closed when one is appended
+	added   int
+	results []BatchResult
+	changed chan struct{}
+}
+
+// NewBatch creates a Batch whose jobs run on d
+func (d *Dispatcher) NewBatch(opts ...BatchOption) *Batch {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	b := &Batch{
+		dis:     d,
+		ctx:     ctx,
+		cancel:  cancel,
+		wg:      new(sync.WaitGroup),
+		mu:      new(sync.Mutex),
+		changed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Add submits fn as a member of the batch.
+// fn's context is also canceled when the batch is canceled by a failure.
+func (b *Batch) Add(fn JobFunc) chan error {
+	b.wg.Add(1)
+	b.mu.Lock()
+	index := b.added
+	b.added++
+	b.mu.Unlock()
+	return b.dis.AddJob(func(ctx context.Context) error {
+		if b.ctx.Err() != nil {
+			return &SkippedError{Cause: context.Cause(b.ctx)}
+		}
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		stop := context.AfterFunc(b.ctx, func() {
+			cancel(context.Cause(b.ctx))
+		})
+		defer stop()
+
+		return fn(ctx)
+	}, b.member(index))
+}
+
+// member records the result of the job at index, including jobs rejected by the Dispatcher
+func (b *Batch) member(index int) JobOption {
+	return onDone(func(err error) {
+		if err != nil && !errors.Is(err, ErrSkipped) {
+			b.fail(err)
+		}
+		b.record(index, err)
+		b.wg.Done()
+	})
+}
+
+func (b *Batch) record(index int, err error) {
+	b.mu.Lock()
+	b.results = append(b.results, BatchResult{Index: index, Err: err})
+	close(b.changed)
+	b.changed = make(chan struct{})
+	b.mu.Unlock()
+}
+
+// Results iterates over the results of the jobs of the batch in completion order,
+// blocking until the jobs added before the iteration reached them finished
+func (b *Batch) Results() iter.Seq[BatchResult] {
+	return func(yield func(BatchResult) bool) {
+		for i := 0; ; i++ {
+			b.mu.Lock()
+			for i == len(b.results) && i < b.added {
+				changed := b.changed
+				b.mu.Unlock()
+				<-changed
+				b.mu.Lock()
+			}
+			if i == len(b.results) {
+				b.mu.Unlock()
+				return
+			}
+			res := b.results[i]
+			b.mu.Unlock()
+			if !yield(res) {
+				return
+			}
+		}
+	}
+}
+
+func (b *Batch) fail(err error) {
+	b.mu.Lock()
+	b.errs = append(b.errs, err)
+	b.mu.Unlock()
+	if b.cancelOnError {
+		b.cancel(err)
+	}
+}
+
+// Wait blocks until every job of the batch has finished or was skipped
+// and returns the joined errors of the failed jobs
+func (b *Batch) Wait() error {
+	b.wg.Wait()
+	b.cancel(nil)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return errors.Join(b.errs...)
+}
@@ -0,0 +1,100 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBatch_CancelOnFirstError(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	want := errors.New("fail")
+	failing := d.NewBatch(CancelOnFirstError())
+	other := d.NewBatch(CancelOnFirstError())
+
+	first := failing.Add(func(context.Context) error {
+		return want
+	})
+	skipped := failing.Add(func(context.Context) error {
+		return nil
+	})
+	independent := other.Add(func(context.Context) error {
+		return nil
+	})
+
+	if err := <-first; !errors.Is(err, want) {
+		t.Errorf("first job error = %v, want %v", err, want)
+	}
+	err := <-skipped
+	if !errors.Is(err, ErrSkipped) {
+		t.Errorf("skipped job error = %v, want %v", err, ErrSkipped)
+	}
+	var se *SkippedError
+	if !errors.As(err, &se) || !errors.Is(se.Cause, want) {
+		t.Errorf("skipped job cause = %v, want %v", err, want)
+	}
+	if err := <-independent; err != nil {
+		t.Errorf("other batch job error = %v, want nil", err)
+	}
+	if err := failing.Wait(); !errors.Is(err, want) {
+		t.Errorf("Wait() = %v, want %v", err, want)
+	}
+	if err := other.Wait(); err != nil {
+		t.Errorf("other Wait() = %v, want nil", err)
+	}
+}
+
+func TestBatch_Results(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	b := d.NewBatch()
+	errFail := errors.New("fail")
+	for i := 0; i < 5; i++ {
+		b.Add(func(context.Context) error {
+			if i == 3 {
+				return errFail
+			}
+			return nil
+		})
+	}
+	seen := make(map[int]error)
+	for res := range b.Results() {
+		seen[res.Index] = res.Err
+	}
+	if len(seen) != 5 {
+		t.Fatalf("got %d results, want 5", len(seen))
+	}
+	for i, err := range seen {
+		if (i == 3) != errors.Is(err, errFail) {
+			t.Errorf("result %d = %v", i, err)
+		}
+	}
+
+	// a rejected job still yields a result
+	d.Stop(true)
+	b = d.NewBatch()
+	b.Add(func(context.Context) error { return nil })
+	for res := range b.Results() {
+		if !errors.Is(res.Err, ErrStopped) {
+			t.Errorf("rejected job result = %v, want ErrStopped", res.Err)
+		}
+	}
+}
+
+func TestBatch_Rejected(t *testing.T) {
+	d := New(1).Start().Kill()
+	b := d.NewBatch(CancelOnFirstError())
+	ech := b.Add(func(context.Context) error { return nil })
+	if err := <-ech; !errors.Is(err, ErrStopped) {
+		t.Fatalf("job error = %v, want %v", err, ErrStopped)
+	}
+	if err := b.Wait(); !errors.Is(err, ErrStopped) {
+		t.Errorf("Wait() = %v, want %v", err, ErrStopped)
+	}
+	if cause := context.Cause(b.ctx); !errors.Is(cause, ErrStopped) {
+		t.Errorf("batch cancel cause = %v, want %v", cause, ErrStopped)
+	}
+}
@@ -0,0 +1,124 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batcher accumulates items and runs a handler on the Dispatcher with slices of them,
+// once a batch reached its size or its oldest item waited for the max wait
+type Batcher[T any] struct {
+	d       *Dispatcher
+	size    int
+	wait    time.Duration
+	handler func(context.Context, []T) error
+	opts    []JobOption
+
+	mu      sync.Mutex
+	items   []T
+	echs    []chan error
+	id      JobID
+	timer   Timer
+	closed  bool
+	batches uint64
+}
+
+// NewBatcher creates a Batcher running handler on d with up to size items, a batch is
+// also submitted maxWait after its first item was added. A size below 1 only batches
+// by time and a maxWait of 0 only by size. opts are applied to every batch job.
+func NewBatcher[T any](d *Dispatcher, size int, maxWait time.Duration, handler func(ctx context.Context, items []T) error, opts ...JobOption) *Batcher[T] {
+	return &Batcher[T]{
+		d:       d,
+		size:    size,
+		wait:    maxWait,
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+// Add adds item to the open batch and returns a channel receiving the result of the
+// handler for that batch. Items added after Close fail with ErrStopped.
+// Wait and Barrier also wait for a batch which is still open.
+func (b *Batcher[T]) Add(item T) chan error {
+	ech := make(chan error, 1)
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		ech <- ErrStopped
+		return ech
+	}
+	if len(b.items) == 0 {
+		// the job ID is taken now so that Wait and Barrier cover the open batch
+		b.id = b.d.track()
+		if b.wait > 0 {
+			batch := b.batches
+			b.timer = b.d.clock.AfterFunc(b.wait, func() {
+				b.flushBatch(batch)
+			})
+		}
+	}
+	b.items = append(b.items, item)
+	b.echs = append(b.echs, ech)
+	if b.size > 0 && len(b.items) >= b.size {
+		b.submit()
+		return ech
+	}
+	b.mu.Unlock()
+	return ech
+}
+
+// Flush submits the open batch without waiting for it to fill up
+func (b *Batcher[T]) Flush() {
+	b.mu.Lock()
+	if len(b.items) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	b.submit()
+}
+
+// Close submits the open batch and makes later calls to Add fail
+func (b *Batcher[T]) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.Flush()
+}
+
+// flushBatch submits the batch with the sequence number batch when it is still open
+func (b *Batcher[T]) flushBatch(batch uint64) {
+	b.mu.Lock()
+	if b.batches != batch || len(b.items) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	b.submit()
+}
+
+// submit takes the open batch and submits it, b.mu must be held and is released
+func (b *Batcher[T]) submit() {
+	items, echs, id := b.items, b.echs, b.id
+	b.items, b.echs = nil, nil
+	b.batches++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+
+	opts := append(append([]JobOption(nil), b.opts...), withID(id), func(j *job) {
+		done := j.done
+		j.done = func(err error) {
+			if done != nil {
+				done(err)
+			}
+			for _, ech := range echs {
+				ech <- err
+			}
+		}
+	})
+	b.d.AddJob(func(ctx context.Context) error {
+		return b.handler(ctx, items)
+	}, opts...)
+}
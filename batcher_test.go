@@ -0,0 +1,89 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		wait  time.Duration
+		items int
+		want  []int
+	}{
+		{name: "by size", size: 3, items: 7, want: []int{3, 3, 1}},
+		{name: "by time", wait: 10 * time.Millisecond, items: 4, want: []int{4}},
+		{name: "size and time", size: 3, wait: 10 * time.Millisecond, items: 4, want: []int{3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1).QueueRunner().Start()
+			defer d.Stop(true)
+
+			var (
+				mu    sync.Mutex
+				sizes []int
+			)
+			b := NewBatcher(d, tt.size, tt.wait, func(_ context.Context, items []int) error {
+				mu.Lock()
+				sizes = append(sizes, len(items))
+				mu.Unlock()
+				return nil
+			})
+			echs := make([]chan error, tt.items)
+			for i := range echs {
+				echs[i] = b.Add(i)
+			}
+			if tt.wait == 0 {
+				b.Flush()
+			}
+			for _, ech := range echs {
+				if err := <-ech; err != nil {
+					t.Fatal(err)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(sizes) != len(tt.want) {
+				t.Fatalf("batches = %v, want %v", sizes, tt.want)
+			}
+			for i := range sizes {
+				if sizes[i] != tt.want[i] {
+					t.Fatalf("batches = %v, want %v", sizes, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatcher_Close(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	errBulk := errors.New("bulk failed")
+	b := NewBatcher(d, 10, time.Hour, func(context.Context, []string) error {
+		return errBulk
+	})
+	first, second := b.Add("a"), b.Add("b")
+	// Wait covers the open batch
+	done := make(chan struct{})
+	go func() {
+		d.Wait()
+		close(done)
+	}()
+	b.Close()
+	<-done
+	for _, ech := range []chan error{first, second} {
+		if err := <-ech; !errors.Is(err, errBulk) {
+			t.Errorf("item error = %v, want %v", err, errBulk)
+		}
+	}
+	if err := <-b.Add("c"); !errors.Is(err, ErrStopped) {
+		t.Errorf("Add after Close = %v, want ErrStopped", err)
+	}
+}
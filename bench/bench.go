@@ -0,0 +1,157 @@
+// Package bench measures the throughput of a gorker Dispatcher.
+//
+// Run executes a single scenario and SelfTest runs a fixed one which tells how many
+// jobs per second the current machine sustains, so that performance affecting changes
+// can be compared on CI and by users. ComparisonBench runs a scenario in every Mode to
+// tell what the queue of a Dispatcher costs over direct dispatch and raw goroutines.
+// The benchmarks of the package cover Scenarios.
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+// Mode selects how a scenario runs its jobs
+type Mode string
+
+const (
+	// ModeQueued submits the jobs through the queue of a Dispatcher, it is the default
+	ModeQueued Mode = "queued"
+	// ModeDirect submits the jobs to a Dispatcher created with gorker.WithDirectDispatch
+	ModeDirect Mode = "direct"
+	// ModeGoroutines starts a goroutine per job instead of using a Dispatcher
+	ModeGoroutines Mode = "goroutines"
+)
+
+// Modes are the modes compared by ComparisonBench
+var Modes = []Mode{ModeGoroutines, ModeDirect, ModeQueued}
+
+// Config describes a benchmark scenario
+type Config struct {
+	// Mode is how the jobs run, ModeQueued when empty
+	Mode Mode
+	// Workers is the worker count of the Dispatcher, GOMAXPROCS when 0
+	Workers int
+	// Jobs is the number of jobs submitted, 100000 when 0
+	Jobs int
+	// JobDuration is how long each job sleeps, jobs return immediately when 0
+	JobDuration time.Duration
+	// QueueDepth bounds the submitted but unfinished jobs, unbounded when 0
+	QueueDepth int
+	// Options are applied to the Dispatcher
+	Options []gorker.Option
+}
+
+// Result is the outcome of a scenario
+type Result struct {
+	Config     Config
+	Elapsed    time.Duration
+	JobsPerSec float64
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("mode=%s workers=%d jobs=%d duration=%s depth=%d: %.0f jobs/s in %s",
+		r.Config.Mode, r.Config.Workers, r.Config.Jobs, r.Config.JobDuration, r.Config.QueueDepth, r.JobsPerSec, r.Elapsed)
+}
+
+// Scenarios are the scenarios covered by the benchmarks of the package
+var Scenarios = []Config{
+	{Workers: 1, Jobs: 10000},
+	{Workers: 4, Jobs: 10000},
+	{Workers: 64, Jobs: 10000},
+	{Workers: 4, Jobs: 10000, QueueDepth: 16},
+	{Workers: 64, Jobs: 10000, QueueDepth: 16},
+	{Workers: 16, Jobs: 1000, JobDuration: time.Millisecond},
+	{Workers: 256, Jobs: 1000, JobDuration: time.Millisecond},
+}
+
+func (c Config) withDefaults() Config {
+	if c.Mode == "" {
+		c.Mode = ModeQueued
+	}
+	if c.Workers < 1 {
+		c.Workers = runtime.GOMAXPROCS(0)
+	}
+	if c.Jobs < 1 {
+		c.Jobs = 100000
+	}
+	return c
+}
+
+// Run executes the scenario on a new Dispatcher and measures how fast its jobs complete.
+// In ModeGoroutines the jobs run on goroutines of their own and Workers is ignored.
+func Run(cfg Config) Result {
+	cfg = cfg.withDefaults()
+	submit := func(fn func()) {
+		go fn()
+	}
+	if cfg.Mode != ModeGoroutines {
+		opts := cfg.Options
+		if cfg.Mode == ModeDirect {
+			opts = append(opts[:len(opts):len(opts)], gorker.WithDirectDispatch())
+		}
+		d := gorker.New(cfg.Workers, opts...).QueueRunner().Start()
+		defer d.StopQueueRunner()
+		defer d.Stop(true)
+		submit = d.Go
+	}
+
+	var sem chan struct{}
+	if cfg.QueueDepth > 0 {
+		sem = make(chan struct{}, cfg.QueueDepth)
+	}
+	var wg sync.WaitGroup
+	wg.Add(cfg.Jobs)
+	job := func() {
+		if cfg.JobDuration > 0 {
+			time.Sleep(cfg.JobDuration)
+		}
+		if sem != nil {
+			<-sem
+		}
+		wg.Done()
+	}
+
+	start := time.Now()
+	for i := 0; i < cfg.Jobs; i++ {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+		submit(job)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	return Result{
+		Config:     cfg,
+		Elapsed:    elapsed,
+		JobsPerSec: float64(cfg.Jobs) / elapsed.Seconds(),
+	}
+}
+
+// SelfTest measures the jobs per second of empty jobs with a worker per CPU.
+// It runs the scenario three times and returns the fastest run.
+func SelfTest() Result {
+	var best Result
+	for i := 0; i < 3; i++ {
+		if r := Run(Config{}); r.JobsPerSec > best.JobsPerSec {
+			best = r
+		}
+	}
+	return best
+}
+
+// ComparisonBench runs the scenario once in every mode of Modes and returns the results
+// in that order, the Mode of cfg is ignored
+func ComparisonBench(cfg Config) []Result {
+	results := make([]Result, 0, len(Modes))
+	for _, mode := range Modes {
+		cfg.Mode = mode
+		results = append(results, Run(cfg))
+	}
+	return results
+}
@@ -0,0 +1,96 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "defaults", cfg: Config{Jobs: 1000}},
+		{name: "bounded depth", cfg: Config{Workers: 2, Jobs: 100, QueueDepth: 4}},
+		{name: "sleeping jobs", cfg: Config{Workers: 10, Jobs: 50, JobDuration: time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Run(tt.cfg)
+			if r.Config.Workers < 1 || r.Config.Jobs < 1 {
+				t.Errorf("defaults not applied: %+v", r.Config)
+			}
+			if r.Elapsed <= 0 || r.JobsPerSec <= 0 {
+				t.Errorf("result = %v", r)
+			}
+			if tt.cfg.JobDuration > 0 {
+				min := tt.cfg.JobDuration * time.Duration(tt.cfg.Jobs/tt.cfg.Workers)
+				if r.Elapsed < min {
+					t.Errorf("elapsed = %s, want at least %s", r.Elapsed, min)
+				}
+			}
+		})
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("self test runs 300000 jobs")
+	}
+	r := SelfTest()
+	if r.JobsPerSec <= 0 {
+		t.Fatalf("result = %v", r)
+	}
+	t.Log(r)
+}
+
+func BenchmarkScenarios(b *testing.B) {
+	for _, cfg := range Scenarios {
+		name := fmt.Sprintf("workers=%d/duration=%s/depth=%d", cfg.Workers, cfg.JobDuration, cfg.QueueDepth)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var jobs float64
+			var elapsed time.Duration
+			for i := 0; i < b.N; i++ {
+				r := Run(cfg)
+				jobs += float64(r.Config.Jobs)
+				elapsed += r.Elapsed
+			}
+			b.ReportMetric(jobs/elapsed.Seconds(), "jobs/s")
+		})
+	}
+}
+
+func TestComparisonBench(t *testing.T) {
+	results := ComparisonBench(Config{Workers: 4, Jobs: 1000, QueueDepth: 16})
+	if len(results) != len(Modes) {
+		t.Fatalf("results = %d, want %d", len(results), len(Modes))
+	}
+	for i, r := range results {
+		if r.Config.Mode != Modes[i] {
+			t.Errorf("result %d mode = %s, want %s", i, r.Config.Mode, Modes[i])
+		}
+		if r.JobsPerSec <= 0 {
+			t.Errorf("result = %v", r)
+		}
+	}
+}
+
+func BenchmarkComparison(b *testing.B) {
+	cfg := Config{Workers: 4, Jobs: 10000}
+	for _, mode := range Modes {
+		cfg.Mode = mode
+		b.Run(string(mode), func(b *testing.B) {
+			b.ReportAllocs()
+			var jobs float64
+			var elapsed time.Duration
+			for i := 0; i < b.N; i++ {
+				r := Run(cfg)
+				jobs += float64(r.Config.Jobs)
+				elapsed += r.Elapsed
+			}
+			b.ReportMetric(jobs/elapsed.Seconds(), "jobs/s")
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by jobs rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("gorker: circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerConfig configures the circuit breakers of a Dispatcher
+type BreakerConfig struct {
+	// Key returns the breaker group of a job, jobs with an empty key bypass the breakers
+	Key func(JobInfo) string
+	// FailureRate opens the breaker of a group when its failure ratio within Window reaches it
+	FailureRate float64
+	// MinRequests is the number of results within Window required before the breaker may open
+	MinRequests int
+	// Window is the period over which failures are counted
+	Window time.Duration
+	// Cooldown is how long an open breaker rejects jobs before letting a probe through
+	Cooldown time.Duration
+	// Park requeues rejected jobs after the cooldown instead of failing them with ErrCircuitOpen
+	Park bool
+	// OnStateChange is called on every breaker state transition
+	OnStateChange func(key string, from, to BreakerState)
+}
+
+type breakers struct {
+	cfg BreakerConfig
+	mu  sync.Mutex
+	m   map[string]*breaker
+}
+
+type breaker struct {
+	state    BreakerState
+	since    time.Time
+	requests int
+	failures int
+	probing  bool
+}
+
+// WithCircuitBreaker guards job execution with one circuit breaker per job group
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(d *Dispatcher) {
+		if cfg.Key == nil {
+			return
+		}
+		if cfg.MinRequests < 1 {
+			cfg.MinRequests = 1
+		}
+		if cfg.Window <= 0 {
+			cfg.Window = 10 * time.Second
+		}
+		if cfg.Cooldown <= 0 {
+			cfg.Cooldown = 5 * time.Second
+		}
+		d.breakers = &breakers{
+			cfg: cfg,
+			m:   make(map[string]*breaker),
+		}
+	}
+}
+
+// allow reports whether a job of key may run now, and otherwise how long until the next probe
+func (bs *breakers) allow(key string, now time.Time) (bool, time.Duration) {
+	bs.mu.Lock()
+	b, ok := bs.m[key]
+	if !ok {
+		b = &breaker{
+			state: BreakerClosed,
+			since: now,
+		}
+		bs.m[key] = b
+	}
+	var from BreakerState
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.since.Add(bs.cfg.Cooldown).Sub(now); wait > 0 {
+			bs.mu.Unlock()
+			return false, wait
+		}
+		from = b.state
+		b.state = BreakerHalfOpen
+		b.since = now
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			bs.mu.Unlock()
+			return false, bs.cfg.Cooldown
+		}
+		b.probing = true
+	case BreakerClosed:
+		if now.Sub(b.since) > bs.cfg.Window {
+			b.since = now
+			b.requests = 0
+			b.failures = 0
+		}
+	}
+	bs.mu.Unlock()
+	if from != "" {
+		bs.changed(key, from, BreakerHalfOpen)
+	}
+	return true, 0
+}
+
+func (bs *breakers) record(key string, err error, now time.Time) {
+	bs.mu.Lock()
+	b := bs.m[key]
+	from := b.state
+	switch b.state {
+	case BreakerHalfOpen:
+		b.probing = false
+		b.since = now
+		b.requests = 0
+		b.failures = 0
+		if err != nil {
+			b.state = BreakerOpen
+		} else {
+			b.state = BreakerClosed
+		}
+	case BreakerClosed:
+		b.requests++
+		if err != nil {
+			b.failures++
+		}
+		if b.requests >= bs.cfg.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= bs.cfg.FailureRate {
+			b.state = BreakerOpen
+			b.since = now
+		}
+	}
+	to := b.state
+	bs.mu.Unlock()
+	if from != to {
+		bs.changed(key, from, to)
+	}
+}
+
+// release frees the probe of a half-open breaker taken by allow for a job which did not run
+func (bs *breakers) release(key string) {
+	bs.mu.Lock()
+	if b, ok := bs.m[key]; ok && b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+	bs.mu.Unlock()
+}
+
+func (bs *breakers) changed(key string, from, to BreakerState) {
+	if bs.cfg.OnStateChange != nil {
+		bs.cfg.OnStateChange(key, from, to)
+	}
+}
+
+func (bs *breakers) states() map[string]BreakerState {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	states := make(map[string]BreakerState, len(bs.m))
+	for key, b := range bs.m {
+		states[key] = b.state
+	}
+	return states
+}
+
+// admit checks the breaker of j's group before j runs and returns the group key.
+// A rejected job is completed with ErrCircuitOpen or parked until the next probe.
+func (d *Dispatcher) admit(j *job) (string, bool) {
+	bs := d.breakers
+	key := bs.cfg.Key(j.info())
+	if key == "" {
+		return "", true
+	}
+	ok, wait := bs.allow(key, d.clock.Now())
+	if ok {
+		return key, true
+	}
+	if bs.cfg.Park {
+		if d.quota != nil {
+			d.quota.release(d, j)
+		}
+		d.clock.AfterFunc(wait, func() {
+			d.requeue([]*job{j})
+		})
+		return key, false
+	}
+	d.finish(j, ErrCircuitOpen)
+	return key, false
+}
@@ -0,0 +1,88 @@
+package gorker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBreakers(t *testing.T) {
+	var transitions []string
+	d := New(1, WithCircuitBreaker(BreakerConfig{
+		Key: func(info JobInfo) string {
+			return "db"
+		},
+		FailureRate: 0.5,
+		MinRequests: 2,
+		Window:      time.Minute,
+		Cooldown:    time.Second,
+		OnStateChange: func(key string, from, to BreakerState) {
+			transitions = append(transitions, string(from)+">"+string(to))
+		},
+	}))
+	bs := d.breakers
+	fail := errors.New("fail")
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := bs.allow("db", now); !ok {
+			t.Fatal("closed breaker rejected a job")
+		}
+		bs.record("db", fail, now)
+	}
+	if ok, wait := bs.allow("db", now); ok || wait != time.Second {
+		t.Fatalf("open breaker allow() = %v, %v, want false, 1s", ok, wait)
+	}
+	if got := d.Stats().Breakers["db"]; got != BreakerOpen {
+		t.Errorf("Stats().Breakers[db] = %v, want %v", got, BreakerOpen)
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := bs.allow("db", now); !ok {
+		t.Fatal("breaker did not let a probe through after cooldown")
+	}
+	if ok, _ := bs.allow("db", now); ok {
+		t.Fatal("half-open breaker let a second probe through")
+	}
+	bs.record("db", nil, now)
+	if ok, _ := bs.allow("db", now); !ok {
+		t.Fatal("breaker did not close after a successful probe")
+	}
+
+	want := "closed>open,open>half-open,half-open>closed"
+	if got := strings.Join(transitions, ","); got != want {
+		t.Errorf("transitions = %s, want %s", got, want)
+	}
+}
+
+func TestWithCircuitBreaker_FailFast(t *testing.T) {
+	d := New(1, WithCircuitBreaker(BreakerConfig{
+		Key: func(info JobInfo) string {
+			for _, tag := range info.Tags {
+				if strings.HasPrefix(tag, "dep:") {
+					return tag
+				}
+			}
+			return ""
+		},
+		FailureRate: 1,
+		MinRequests: 1,
+		Cooldown:    time.Hour,
+	})).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("down")
+	if err := <-d.Add(func() error { return fail }, WithTags("dep:payments")); !errors.Is(err, fail) {
+		t.Fatalf("first job error = %v, want %v", err, fail)
+	}
+	if err := <-d.Add(func() error { return nil }, WithTags("dep:payments")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("job in open group error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if err := <-d.Add(func() error { return nil }, WithTags("dep:search")); err != nil {
+		t.Errorf("job in other group error = %v, want nil", err)
+	}
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Errorf("job without group error = %v, want nil", err)
+	}
+}
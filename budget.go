@@ -0,0 +1,130 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrTimeBudgetExceeded is the context cause of jobs aborted for running longer than their budget
+	ErrTimeBudgetExceeded error = &timeoutError{"gorker: job time budget exceeded"}
+	// ErrMemoryBudgetExceeded is the context cause of jobs aborted while the process used more
+	// memory than the budget
+	ErrMemoryBudgetExceeded = errors.New("gorker: job memory budget exceeded")
+)
+
+var defaultBudgetInterval = 100 * time.Millisecond
+
+// Budget bounds the resources of every job of a Dispatcher, zero fields are unbounded
+type Budget struct {
+	// WallTime is the longest a job may run
+	WallTime time.Duration
+	// Memory is the process memory in bytes above which the longest running job is aborted.
+	// Go cannot attribute memory to goroutines, the job running for the longest time is
+	// assumed to be the runaway one and only one job is aborted per check.
+	Memory uint64
+	// Interval is how often running jobs are checked, 100ms when 0
+	Interval time.Duration
+	// OnViolation, if not nil, is called for every aborted job with the cause
+	OnViolation func(info JobInfo, cause error)
+}
+
+type budgetGuard struct {
+	Budget
+	mu         sync.Mutex
+	running    map[JobID]budgetedJob
+	sampler    memorySampler
+	violations uint64
+}
+
+type budgetedJob struct {
+	info    JobInfo
+	started time.Time
+	cancel  context.CancelCauseFunc
+}
+
+// WithBudget aborts jobs exceeding b by canceling their context with ErrTimeBudgetExceeded or
+// ErrMemoryBudgetExceeded, aborted jobs are counted in Stats().BudgetViolations.
+// Like the memory guard this is best effort, a job ignoring its context keeps running.
+func WithBudget(b Budget) Option {
+	return func(d *Dispatcher) {
+		if b.Interval <= 0 {
+			b.Interval = defaultBudgetInterval
+		}
+		d.budget = &budgetGuard{
+			Budget:  b,
+			running: make(map[JobID]budgetedJob),
+			sampler: newMemorySampler(),
+		}
+	}
+}
+
+func (g *budgetGuard) track(j *job, cancel context.CancelCauseFunc) {
+	g.mu.Lock()
+	g.running[j.id] = budgetedJob{
+		info:    j.info(),
+		started: j.started,
+		cancel:  cancel,
+	}
+	g.mu.Unlock()
+}
+
+func (g *budgetGuard) untrack(id JobID) {
+	g.mu.Lock()
+	delete(g.running, id)
+	g.mu.Unlock()
+}
+
+func (g *budgetGuard) run(ctx context.Context, clock Clock) {
+	ticker := clock.NewTicker(g.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			g.check(now)
+		}
+	}
+}
+
+// check aborts the jobs over the time budget and the oldest job when the process is over the memory budget
+func (g *budgetGuard) check(now time.Time) {
+	type violation struct {
+		budgetedJob
+		cause error
+	}
+	var aborted []violation
+	g.mu.Lock()
+	if len(g.running) == 0 {
+		g.mu.Unlock()
+		return
+	}
+	var oldest JobID
+	for id, bj := range g.running {
+		if g.WallTime > 0 && now.Sub(bj.started) > g.WallTime {
+			aborted = append(aborted, violation{bj, ErrTimeBudgetExceeded})
+			delete(g.running, id)
+			continue
+		}
+		if oldest == 0 || bj.started.Before(g.running[oldest].started) {
+			oldest = id
+		}
+	}
+	if g.Memory > 0 && oldest != 0 && g.sampler.usage() > g.Memory {
+		aborted = append(aborted, violation{g.running[oldest], ErrMemoryBudgetExceeded})
+		delete(g.running, oldest)
+	}
+	g.mu.Unlock()
+
+	for _, v := range aborted {
+		atomic.AddUint64(&g.violations, 1)
+		v.cancel(v.cause)
+		if g.OnViolation != nil {
+			g.OnViolation(v.info, v.cause)
+		}
+	}
+}
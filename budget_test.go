@@ -0,0 +1,96 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget Budget
+		job    time.Duration
+		want   error
+	}{
+		{
+			name:   "within time budget",
+			budget: Budget{WallTime: time.Second, Interval: time.Millisecond},
+			job:    5 * time.Millisecond,
+		},
+		{
+			name:   "time budget exceeded",
+			budget: Budget{WallTime: 10 * time.Millisecond, Interval: time.Millisecond},
+			job:    time.Second,
+			want:   ErrTimeBudgetExceeded,
+		},
+		{
+			name:   "memory budget exceeded",
+			budget: Budget{Memory: 1, Interval: time.Millisecond},
+			job:    time.Second,
+			want:   ErrMemoryBudgetExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violated := make(chan error, 1)
+			tt.budget.OnViolation = func(info JobInfo, cause error) {
+				if info.ID == 0 {
+					t.Error("OnViolation called without job info")
+				}
+				violated <- cause
+			}
+			d := New(1, WithBudget(tt.budget)).QueueRunner().Start()
+			defer d.Stop(true)
+
+			err := <-d.AddJob(func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				case <-time.After(tt.job):
+					return nil
+				}
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("job error = %v, want %v", err, tt.want)
+			}
+			var violations uint64
+			if tt.want != nil {
+				violations = 1
+				if cause := <-violated; cause != tt.want {
+					t.Errorf("OnViolation cause = %v, want %v", cause, tt.want)
+				}
+			}
+			if got := d.Stats().BudgetViolations; got != violations {
+				t.Errorf("budget violations = %d, want %d", got, violations)
+			}
+		})
+	}
+}
+
+func TestBudgetGuard_check(t *testing.T) {
+	now := time.Now()
+	var canceled []JobID
+	g := &budgetGuard{
+		Budget:  Budget{WallTime: time.Second},
+		running: make(map[JobID]budgetedJob),
+	}
+	for id, age := range map[JobID]time.Duration{1: 2 * time.Second, 2: 500 * time.Millisecond} {
+		id := id
+		g.running[id] = budgetedJob{
+			info:    JobInfo{ID: id},
+			started: now.Add(-age),
+			cancel: func(error) {
+				canceled = append(canceled, id)
+			},
+		}
+	}
+	g.check(now)
+	if len(canceled) != 1 || canceled[0] != 1 {
+		t.Errorf("canceled = %v, want [1]", canceled)
+	}
+	if _, ok := g.running[2]; !ok {
+		t.Error("job within the budget was untracked")
+	}
+}
@@ -0,0 +1,170 @@
+package gorker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// BufferPolicy decides the capacity of the channel handing queued jobs to the workers
+type BufferPolicy int
+
+const (
+	// BufferProportional sizes the buffer to size slots per worker, it is the default
+	BufferProportional BufferPolicy = iota
+	// BufferFixed keeps the buffer at size slots regardless of the worker count
+	BufferFixed
+	// BufferAdaptive resizes the buffer between the worker count and size
+	// following the observed enqueue and dequeue rates
+	BufferAdaptive
+)
+
+var (
+	defaultBufferSize     = 100
+	defaultBufferInterval = time.Second
+	// bufferHorizon is how much dequeue throughput the adaptive buffer holds
+	bufferHorizon = 100 * time.Millisecond
+)
+
+type bufferPolicy struct {
+	policy   BufferPolicy
+	size     int
+	interval time.Duration
+	// dispatched counts jobs moved from the queue to the buffer
+	dispatched uint64
+	lastIn     uint64
+	lastOut    uint64
+}
+
+func newBufferPolicy() *bufferPolicy {
+	return &bufferPolicy{
+		policy:   BufferProportional,
+		size:     defaultBufferSize,
+		interval: defaultBufferInterval,
+	}
+}
+
+// WithBufferPolicy selects how the dispatch buffer is sized. size is the number of slots
+// per worker for BufferProportional, the total for BufferFixed and the upper bound for
+// BufferAdaptive. A size below 1 keeps the default of 100.
+func WithBufferPolicy(policy BufferPolicy, size int) Option {
+	return func(d *Dispatcher) {
+		d.buffer.policy = policy
+		if size > 0 {
+			d.buffer.size = size
+		}
+		d.resetBuffer()
+	}
+}
+
+// WithBufferInterval sets how often the adaptive buffer policy samples the rates
+func WithBufferInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		if interval > 0 {
+			d.buffer.interval = interval
+		}
+	}
+}
+
+// capacity returns the buffer size for workers under a static policy
+func (b *bufferPolicy) capacity(workers int) int {
+	size := b.size
+	switch b.policy {
+	case BufferProportional:
+		size = workers * b.size
+	case BufferAdaptive:
+		size = workers
+	}
+	return clampBuffer(size)
+}
+
+// target returns the adaptive buffer size for the rates observed over elapsed
+func (b *bufferPolicy) target(workers int, in, out uint64, elapsed time.Duration) int {
+	if elapsed <= 0 {
+		return workers
+	}
+	rate := out
+	if in > rate {
+		rate = in
+	}
+	size := int(float64(rate) * float64(bufferHorizon) / float64(elapsed))
+	if size < workers {
+		size = workers
+	}
+	if size > b.size*workers {
+		size = b.size * workers
+	}
+	return clampBuffer(size)
+}
+
+func clampBuffer(size int) int {
+	if size < 1 {
+		return 1
+	}
+	if size > int(bufferSizeLimit) {
+		return int(bufferSizeLimit)
+	}
+	return size
+}
+
+// resetBuffer recreates the channels of a Dispatcher which was not started yet
+func (d *Dispatcher) resetBuffer() {
+	size := d.buffer.capacity(d.workerCount)
+	d.qin = make(chan *job, size)
+	d.qout = make(chan *job, size)
+	d.queue = make([]*job, 0, size)
+}
+
+// resizeBuffer applies the buffer policy after the worker count changed
+func (d *Dispatcher) resizeBuffer(workers int) {
+	size := d.buffer.capacity(workers)
+	if d.buffer.policy == BufferAdaptive {
+		d.mu.RLock()
+		cur := cap(d.qout)
+		d.mu.RUnlock()
+		if cur >= size {
+			return
+		}
+	}
+	d.ScaleBuffer(size)
+}
+
+// tuneBuffer periodically resizes the buffer for the adaptive policy
+func (d *Dispatcher) tuneBuffer(stop context.Context) {
+	d.label("buffer_tuner")
+	ticker := d.clock.NewTicker(d.buffer.interval)
+	defer ticker.Stop()
+	last := d.clock.Now()
+	for {
+		select {
+		case <-stop.Done():
+			return
+		case now := <-ticker.C():
+			d.adaptBuffer(now.Sub(last))
+			last = now
+		}
+	}
+}
+
+func (d *Dispatcher) adaptBuffer(elapsed time.Duration) {
+	b := d.buffer
+	in := atomic.LoadUint64(&d.submitted)
+	out := atomic.LoadUint64(&b.dispatched)
+	din, dout := in-b.lastIn, out-b.lastOut
+	b.lastIn, b.lastOut = in, out
+
+	d.mu.RLock()
+	workers := d.workerCount
+	cur := cap(d.qout)
+	d.mu.RUnlock()
+	size := b.target(workers, din, dout, elapsed)
+	// only resize on a factor of two to avoid swapping channels on every tick
+	if size >= cur*2 || size <= cur/2 {
+		d.ScaleBuffer(size)
+	}
+	d.mu.Lock()
+	if len(d.queue) == 0 && cap(d.queue) > size*4 {
+		d.queue = make([]*job, 0, size)
+	}
+	d.mu.Unlock()
+}
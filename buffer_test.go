@@ -0,0 +1,160 @@
+package gorker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithBufferPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     Option
+		workers int
+		want    int
+		scaled  int
+	}{
+		{
+			name:    "default proportional",
+			workers: 2,
+			want:    200,
+			scaled:  400,
+		},
+		{
+			name:    "proportional",
+			opt:     WithBufferPolicy(BufferProportional, 10),
+			workers: 2,
+			want:    20,
+			scaled:  40,
+		},
+		{
+			name:    "fixed",
+			opt:     WithBufferPolicy(BufferFixed, 16),
+			workers: 2,
+			want:    16,
+			scaled:  16,
+		},
+		{
+			name:    "adaptive",
+			opt:     WithBufferPolicy(BufferAdaptive, 100),
+			workers: 2,
+			want:    2,
+			scaled:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.opt != nil {
+				opts = append(opts, tt.opt)
+			}
+			d := New(tt.workers, opts...)
+			if got := cap(d.qout); got != tt.want {
+				t.Errorf("buffer = %d, want %d", got, tt.want)
+			}
+			d.UpScale(tt.workers * 2)
+			if got := cap(d.qout); got != tt.scaled {
+				t.Errorf("scaled buffer = %d, want %d", got, tt.scaled)
+			}
+		})
+	}
+}
+
+func TestBufferPolicy_target(t *testing.T) {
+	b := newBufferPolicy()
+	tests := []struct {
+		name    string
+		workers int
+		in, out uint64
+		want    int
+	}{
+		{name: "idle", workers: 4, want: 4},
+		{name: "dequeue rate", workers: 4, out: 1000, want: 100},
+		{name: "enqueue rate", workers: 4, in: 2000, out: 10, want: 200},
+		{name: "bounded", workers: 4, in: 100000, want: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.target(tt.workers, tt.in, tt.out, time.Second); got != tt.want {
+				t.Errorf("target = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_ScaleBuffer(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	// workers are parked on the old buffer and must follow the swap
+	d.ScaleBuffer(5)
+	if got := cap(d.qout); got != 5 {
+		t.Fatalf("buffer = %d, want 5", got)
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case err := <-d.Add(func() error { return nil }):
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("job stalled after ScaleBuffer")
+		}
+	}
+}
+
+func TestBufferAdaptive_Grows(t *testing.T) {
+	d := New(2, WithBufferPolicy(BufferAdaptive, 1000), WithBufferInterval(10*time.Millisecond)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	buffer := func() int {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return cap(d.qout)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for buffer() <= 2 && time.Now().Before(deadline) {
+		for i := 0; i < 200; i++ {
+			d.Add(func() error { return nil })
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := buffer(); got <= 2 {
+		t.Errorf("adaptive buffer did not grow, got %d", got)
+	}
+	d.Wait()
+}
+
+func benchmarkBufferPolicy(b *testing.B, workers int, opts ...Option) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d := New(workers, opts...).QueueRunner().Start()
+		var wg sync.WaitGroup
+		for j := 0; j < 1000; j++ {
+			wg.Add(1)
+			d.Add(func() error {
+				wg.Done()
+				return nil
+			})
+		}
+		wg.Wait()
+		d.Stop(false)
+		d.StopQueueRunner()
+	}
+}
+
+func BenchmarkBufferPolicy_Small_Proportional(b *testing.B) {
+	benchmarkBufferPolicy(b, 2)
+}
+
+func BenchmarkBufferPolicy_Small_Adaptive(b *testing.B) {
+	benchmarkBufferPolicy(b, 2, WithBufferPolicy(BufferAdaptive, 0))
+}
+
+func BenchmarkBufferPolicy_Large_Fixed(b *testing.B) {
+	benchmarkBufferPolicy(b, 256, WithBufferPolicy(BufferFixed, 16))
+}
+
+func BenchmarkBufferPolicy_Large_Adaptive(b *testing.B) {
+	benchmarkBufferPolicy(b, 256, WithBufferPolicy(BufferAdaptive, 0))
+}
@@ -0,0 +1,126 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ScaleReasonBurst is the reason of resizes by WithConcurrency
+const ScaleReasonBurst = "burst"
+
+var (
+	defaultBurstLatency  = 100 * time.Millisecond
+	defaultBurstDuration = 30 * time.Second
+)
+
+type burst struct {
+	soft, hard int
+	latency    time.Duration
+	duration   time.Duration
+	// wait is how long the last job taken by a worker was queued in nanoseconds
+	wait int64
+
+	mu sync.Mutex
+	// since is when the running burst started, cooldown when the next one may start
+	since    time.Time
+	cooldown time.Time
+}
+
+// WithConcurrency runs soft workers and bursts to hard workers while jobs wait in the
+// queue for longer than the burst latency. The pool shrinks back to soft once the
+// latency recovered or after the burst duration, a burst ended by its duration is
+// followed by a cooldown as long as the burst. The worker count becomes soft and
+// manual scaling is overridden when a burst ends. See WithBurstThreshold for the defaults.
+func WithConcurrency(soft, hard int) Option {
+	return func(d *Dispatcher) {
+		if soft < 1 {
+			soft = 1
+		}
+		if hard < soft {
+			hard = soft
+		}
+		if d.burst == nil {
+			d.burst = &burst{
+				latency:  defaultBurstLatency,
+				duration: defaultBurstDuration,
+			}
+		}
+		d.burst.soft, d.burst.hard = soft, hard
+		d.workerCount = soft
+		d.workers = make([]*worker, soft)
+		for i := range d.workers {
+			d.workers[i] = newWorker(d)
+		}
+		d.resetBuffer()
+	}
+}
+
+// WithBurstThreshold sets the queue latency starting a burst and the longest a burst
+// lasts for WithConcurrency, the defaults are 100ms and 30s. It must follow WithConcurrency.
+func WithBurstThreshold(latency, duration time.Duration) Option {
+	return func(d *Dispatcher) {
+		if d.burst == nil {
+			return
+		}
+		if latency > 0 {
+			d.burst.latency = latency
+		}
+		if duration > 0 {
+			d.burst.duration = duration
+		}
+	}
+}
+
+// Bursting reports whether d runs above its soft concurrency limit, see WithConcurrency
+func (d *Dispatcher) Bursting() bool {
+	if d.burst == nil {
+		return false
+	}
+	d.burst.mu.Lock()
+	defer d.burst.mu.Unlock()
+	return !d.burst.since.IsZero()
+}
+
+// dequeued records the queue wait of a job taken by a worker at now
+func (b *burst) dequeued(j *job, now time.Time) {
+	atomic.StoreInt64(&b.wait, int64(now.Sub(j.enqueued)))
+}
+
+func (b *burst) run(ctx context.Context, d *Dispatcher) {
+	ticker := d.clock.NewTicker(d.observeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			b.check(d, now)
+		}
+	}
+}
+
+func (b *burst) check(d *Dispatcher, now time.Time) {
+	wait := time.Duration(atomic.LoadInt64(&b.wait))
+	depth := d.queueDepth()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.since.IsZero() {
+		if depth > 0 && wait > b.latency && !now.Before(b.cooldown) {
+			b.since = now
+			d.upScale(b.hard, ScaleReasonBurst)
+		}
+		return
+	}
+	expired := now.Sub(b.since) >= b.duration
+	// the latency has to drop well below the threshold so that bursts do not flap
+	if depth > 0 && wait > b.latency/2 && !expired {
+		return
+	}
+	if expired {
+		b.cooldown = now.Add(b.duration)
+	}
+	b.since = time.Time{}
+	d.downScale(b.soft, ScaleReasonBurst)
+}
@@ -0,0 +1,75 @@
+package gorker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithConcurrency(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		scales [][2]int
+	)
+	d := New(8,
+		WithConcurrency(1, 3),
+		WithBurstThreshold(10*time.Millisecond, time.Hour),
+		WithObserverInterval(5*time.Millisecond, 0),
+	).OnScale(func(from, to int, reason string) {
+		if reason == ScaleReasonBurst {
+			mu.Lock()
+			scales = append(scales, [2]int{from, to})
+			mu.Unlock()
+		}
+	}).QueueRunner().Start()
+	defer d.Stop(true)
+
+	if got := d.GetWorkerCount(); got != 1 {
+		t.Fatalf("workers = %d, want the soft limit 1", got)
+	}
+	for i := 0; i < 20; i++ {
+		d.Add(func() error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+	}
+	d.Wait()
+	deadline := time.Now().Add(time.Second)
+	for d.Bursting() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(scales) < 2 || scales[0] != [2]int{1, 3} || scales[len(scales)-1] != [2]int{3, 1} {
+		t.Errorf("burst scales = %v, want a burst to 3 and back to 1", scales)
+	}
+	if got := d.GetWorkerCount(); got != 1 {
+		t.Errorf("workers after the burst = %d, want 1", got)
+	}
+}
+
+func TestBurst_Duration(t *testing.T) {
+	d := New(1, WithConcurrency(2, 4), WithBurstThreshold(time.Millisecond, time.Minute))
+	b := d.burst
+	now := time.Now()
+	d.enqueue(&job{enqueued: now})
+	b.wait = int64(time.Second)
+
+	tests := []struct {
+		name    string
+		at      time.Duration
+		workers int
+	}{
+		{name: "burst", at: 0, workers: 4},
+		{name: "bursting", at: 30 * time.Second, workers: 4},
+		{name: "expired", at: time.Minute, workers: 2},
+		{name: "cooldown", at: 90 * time.Second, workers: 2},
+		{name: "after cooldown", at: 2 * time.Minute, workers: 4},
+	}
+	for _, tt := range tests {
+		b.check(d, now.Add(tt.at))
+		if got := len(d.workers); got != tt.workers {
+			t.Errorf("%s: workers = %d, want %d", tt.name, got, tt.workers)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedResult is the result of a job added by AddCached
+type CachedResult struct {
+	Value any
+	Err   error
+	// Shared is set when the result comes from an execution started by another call
+	Shared bool
+}
+
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	value   any
+	done    bool
+	waiters []chan CachedResult
+}
+
+func AddCached(key string, ttl time.Duration, fn func() (any, error), opts ...JobOption) <-chan CachedResult {
+	return instance.AddCached(key, ttl, fn, opts...)
+}
+
+// AddCached runs fn on d unless a job for key is running or succeeded within ttl. Calls
+// for a running key share its result, calls after it succeeded receive the memoized
+// value until ttl expired. Failures are passed to the calls waiting for them but are
+// not memoized, so the next call runs fn again.
+func (d *Dispatcher) AddCached(key string, ttl time.Duration, fn func() (any, error), opts ...JobOption) <-chan CachedResult {
+	res := make(chan CachedResult, 1)
+	c := d.cache
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		if e.done {
+			res <- CachedResult{Value: e.value, Shared: true}
+		} else {
+			e.waiters = append(e.waiters, res)
+		}
+		c.mu.Unlock()
+		return res
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*cacheEntry)
+	}
+	e := &cacheEntry{waiters: []chan CachedResult{res}}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	var value any
+	ech := d.AddJob(func(context.Context) (err error) {
+		value, err = fn()
+		return err
+	}, opts...)
+	go func() {
+		err := <-ech
+		c.mu.Lock()
+		waiters := e.waiters
+		e.waiters = nil
+		if err != nil || ttl <= 0 {
+			delete(c.entries, key)
+		} else {
+			e.value, e.done = value, true
+			d.clock.AfterFunc(ttl, func() {
+				c.mu.Lock()
+				if c.entries[key] == e {
+					delete(c.entries, key)
+				}
+				c.mu.Unlock()
+			})
+		}
+		c.mu.Unlock()
+		for i, w := range waiters {
+			w <- CachedResult{Value: value, Err: err, Shared: i > 0}
+		}
+	}()
+	return res
+}
@@ -0,0 +1,73 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_AddCached(t *testing.T) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var runs int32
+	gate := make(chan struct{})
+	fn := func() (any, error) {
+		<-gate
+		return atomic.AddInt32(&runs, 1), nil
+	}
+	results := []<-chan CachedResult{
+		d.AddCached("a", time.Hour, fn),
+		d.AddCached("a", time.Hour, fn),
+		d.AddCached("a", time.Hour, fn),
+	}
+	close(gate)
+	for i, res := range results {
+		r := <-res
+		if r.Err != nil || r.Value != int32(1) || r.Shared != (i > 0) {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+	if r := <-d.AddCached("a", time.Hour, fn); r.Value != int32(1) || !r.Shared {
+		t.Errorf("memoized result = %+v", r)
+	}
+	if r := <-d.AddCached("b", time.Hour, fn); r.Value != int32(2) || r.Shared {
+		t.Errorf("other key result = %+v", r)
+	}
+}
+
+func TestDispatcher_AddCached_Expiry(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("fail")
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		err   error
+		sleep time.Duration
+		runs  int32
+	}{
+		{name: "memoized", ttl: time.Hour, runs: 1},
+		{name: "expired", ttl: 10 * time.Millisecond, sleep: 50 * time.Millisecond, runs: 2},
+		{name: "failure is not memoized", ttl: time.Hour, err: fail, runs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs int32
+			fn := func() (any, error) {
+				atomic.AddInt32(&runs, 1)
+				return nil, tt.err
+			}
+			if r := <-d.AddCached(tt.name, tt.ttl, fn); !errors.Is(r.Err, tt.err) {
+				t.Fatalf("error = %v, want %v", r.Err, tt.err)
+			}
+			time.Sleep(tt.sleep)
+			<-d.AddCached(tt.name, tt.ttl, fn)
+			if got := atomic.LoadInt32(&runs); got != tt.runs {
+				t.Errorf("runs = %d, want %d", got, tt.runs)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package gorker
+
+// WithClassWorkers reserves workers of the pool for the priority class name, so that its
+// jobs start without waiting for long running jobs of other classes. Jobs of other classes
+// are held in the queue while they would occupy a worker reserved for name and not used by
+// it. With reservations no more jobs than workers are handed to the dispatch buffer and jobs
+// do not run inline, see WithInlineThreshold.
+func WithClassWorkers(name string, workers int) Option {
+	return func(d *Dispatcher) {
+		if workers > 0 {
+			d.priorityClasses().reserved[name] = workers
+		} else if d.classes != nil {
+			delete(d.classes.reserved, name)
+		}
+	}
+}
+
+func (pc *priorityClasses) reserving() bool {
+	return pc != nil && len(pc.reserved) > 0
+}
+
+// admits reports whether j can be dispatched to one of workers without taking a worker
+// reserved for another class, d.mu must be held
+func (pc *priorityClasses) admits(j *job, workers int) bool {
+	need := pc.inUse + 1
+	for class, min := range pc.reserved {
+		if _, ok := pc.index[class]; !ok || class == j.class {
+			continue
+		}
+		if busy := pc.busy[class]; busy < min {
+			need += min - busy
+		}
+	}
+	return need <= workers
+}
+
+// reservedJob returns the first queued job which can be dispatched, d.mu must be held
+func (pc *priorityClasses) reservedJob(queue []*job, workers int) *job {
+	for _, j := range queue {
+		if pc.admits(j, workers) {
+			return j
+		}
+	}
+	return nil
+}
+
+// hold takes a worker for j, which is about to be dispatched
+func (d *Dispatcher) hold(j *job) {
+	d.mu.Lock()
+	j.holding = true
+	d.classes.busy[j.class]++
+	d.classes.inUse++
+	d.mu.Unlock()
+}
+
+// releaseWorker gives back the worker held by a dispatched job of a class with reserved workers
+// once it completed or left the worker to be retried or suspended
+func (d *Dispatcher) releaseWorker(j *job) {
+	if !j.holding {
+		return
+	}
+	d.mu.Lock()
+	d.classes.free(j)
+	d.notify()
+	d.mu.Unlock()
+}
+
+// free gives back the worker held by j, d.mu must be held
+func (pc *priorityClasses) free(j *job) {
+	if !j.holding {
+		return
+	}
+	j.holding = false
+	pc.busy[j.class]--
+	pc.inUse--
+}
+
+// undispatch empties the dispatch buffer ch and gives back the workers held by its jobs,
+// d.mu must be held
+func (d *Dispatcher) undispatch(ch chan *job) []*job {
+	jobs := drain(ch)
+	if d.classes.reserving() {
+		for _, j := range jobs {
+			d.classes.free(j)
+		}
+	}
+	return jobs
+}
@@ -0,0 +1,84 @@
+package gorker
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithClassWorkers(t *testing.T) {
+	d := New(3, WithPriorityClasses("high", "low"), WithClassWorkers("high", 1)).QueueRunner().Start()
+	defer d.Kill()
+
+	var running int32
+	release := make(chan struct{})
+	lows := make([]chan error, 5)
+	for i := range lows {
+		lows[i] = d.Add(func() error {
+			atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			<-release
+			return nil
+		}, WithClass("low"))
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&running) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := atomic.LoadInt32(&running); got != 2 {
+		t.Fatalf("%d low jobs running, want 2 with a worker reserved for high", got)
+	}
+
+	select {
+	case err := <-d.Add(func() error { return nil }, WithClass("high")):
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("high job waited for the low jobs")
+	}
+
+	close(release)
+	for _, ech := range lows {
+		if err := <-ech; err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.mu.RLock()
+	inUse := d.classes.inUse
+	d.mu.RUnlock()
+	if inUse != 0 {
+		t.Errorf("%d workers held after all jobs completed", inUse)
+	}
+}
+
+func TestWithClassWorkers_Go(t *testing.T) {
+	d := New(2, WithPriorityClasses("high", "low"), WithClassWorkers("high", 1)).QueueRunner().Start()
+	defer d.Kill()
+
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		d.Go(func() { done <- struct{}{} })
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("%d of 2 Go jobs ran", i)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.mu.RLock()
+		inUse := d.classes.inUse
+		d.mu.RUnlock()
+		if inUse == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d workers held after the Go jobs ran", inUse)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
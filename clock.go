@@ -0,0 +1,70 @@
+package gorker
+
+import "time"
+
+// Clock is the source of time of a Dispatcher, see WithClock
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f in its own goroutine after d, the returned Timer has a nil channel
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a single shot timer created by a Clock
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a periodic timer created by a Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock makes the Dispatcher read time and create timers from c instead of
+// the time package, so that time dependent behavior can be driven by tests
+func WithClock(c Clock) Option {
+	return func(d *Dispatcher) {
+		if c != nil {
+			d.clock = c
+			d.events.now = c.Now
+		}
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
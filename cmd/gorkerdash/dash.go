@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+const barWidth = 40
+
+// dashboard renders the state of a Dispatcher to a terminal
+type dashboard struct {
+	w    io.Writer
+	d    *gorker.Dispatcher
+	last gorker.Stats
+	at   time.Time
+}
+
+func newDashboard(w io.Writer, d *gorker.Dispatcher) *dashboard {
+	return &dashboard{
+		w:  w,
+		d:  d,
+		at: time.Now(),
+	}
+}
+
+func (b *dashboard) render(now time.Time) {
+	st := b.d.Stats()
+	elapsed := now.Sub(b.at).Seconds()
+	var throughput float64
+	if elapsed > 0 {
+		done := st.Succeeded + st.Failed - b.last.Succeeded - b.last.Failed
+		throughput = float64(done) / elapsed
+	}
+	b.last, b.at = st, now
+
+	health := "ok"
+	if err := b.d.Healthy(); err != nil {
+		health = err.Error()
+	}
+	var sb strings.Builder
+	// clear the screen and move the cursor home
+	sb.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&sb, "gorker %s  %s  %s\n\n", b.d.Name(), b.d.State(), now.Format(time.TimeOnly))
+	fmt.Fprintf(&sb, "health      %s\n", health)
+	fmt.Fprintf(&sb, "workers     %d running %d bursting %v\n", st.Workers, st.Running, b.d.Bursting())
+	fmt.Fprintf(&sb, "queue       %d\n", st.QueueDepth)
+	fmt.Fprintf(&sb, "throughput  %.1f jobs/s\n", throughput)
+	fmt.Fprintf(&sb, "jobs        %d submitted %d succeeded %d failed\n", st.Submitted, st.Succeeded, st.Failed)
+	fmt.Fprintf(&sb, "utilization %s %3.0f%%\n\n", bar(st.Utilization), st.Utilization*100)
+	for _, ws := range b.d.WorkerStats() {
+		hot := ""
+		if ws.Hot {
+			hot = " hot"
+		}
+		fmt.Fprintf(&sb, "worker %-3d %8d jobs  busy %-12s%s\n", ws.Index, ws.Jobs, ws.Busy.Round(time.Millisecond), hot)
+	}
+	io.WriteString(b.w, sb.String())
+}
+
+// bar draws a ratio between 0 and 1 as a bar of barWidth cells
+func bar(ratio float64) string {
+	n := int(ratio*barWidth + 0.5)
+	n = max(0, min(barWidth, n))
+	return "[" + strings.Repeat("#", n) + strings.Repeat(".", barWidth-n) + "]"
+}
@@ -0,0 +1,175 @@
+// Command gorkerdash runs a demo worker pool under synthetic load, serves its admin
+// HTTP endpoints and renders a live dashboard of it in the terminal.
+//
+//	gorkerdash -workers 4 -burst 16 -rate 500 -addr :8080
+//
+// The admin endpoints are
+//
+//	/healthz       Dispatcher.HealthHandler
+//	/stats         Stats as JSON
+//	/workers       WorkerStats as JSON
+//	/history       the last finished jobs as JSON, filtered by the tag and outcome query parameters
+//	/debug/vars    expvar including the Stats under "gorker"
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/kpango/glg"
+	"github.com/kpango/gorker"
+)
+
+type config struct {
+	addr     string
+	workers  int
+	burst    int
+	rate     float64
+	job      time.Duration
+	failRate float64
+	refresh  time.Duration
+	duration time.Duration
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.addr, "addr", ":8080", "address of the admin HTTP endpoint, empty to disable it")
+	flag.IntVar(&cfg.workers, "workers", 4, "workers the pool runs normally")
+	flag.IntVar(&cfg.burst, "burst", 16, "workers the pool bursts to while jobs queue up")
+	flag.Float64Var(&cfg.rate, "rate", 500, "jobs submitted per second")
+	flag.DurationVar(&cfg.job, "job", 10*time.Millisecond, "mean duration of a job")
+	flag.Float64Var(&cfg.failRate, "fail", 0.05, "fraction of failing jobs")
+	flag.DurationVar(&cfg.refresh, "refresh", time.Second, "dashboard refresh interval")
+	flag.DurationVar(&cfg.duration, "duration", 0, "stop after this long, 0 runs until interrupted")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if cfg.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.duration)
+		defer cancel()
+	}
+	if err := run(ctx, cfg); err != nil {
+		glg.Fatal(err)
+	}
+}
+
+func newDispatcher(cfg config) *gorker.Dispatcher {
+	return gorker.New(cfg.workers,
+		gorker.WithName("gorkerdash"),
+		gorker.WithConcurrency(cfg.workers, cfg.burst),
+		gorker.WithHistory(gorker.NewRingHistory(1000, 10*time.Minute)),
+	).StartWorkerObserver().QueueRunner().Start()
+}
+
+func run(ctx context.Context, cfg config) error {
+	d := newDispatcher(cfg)
+	d.PublishExpvar("gorker")
+
+	errc := make(chan error, 1)
+	var srv *http.Server
+	if cfg.addr != "" {
+		srv = &http.Server{Addr: cfg.addr, Handler: adminHandler(d)}
+		go func() {
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errc <- err
+			}
+		}()
+	}
+	go load(ctx, d, cfg)
+
+	dash := newDashboard(os.Stdout, d)
+	ticker := time.NewTicker(cfg.refresh)
+	defer ticker.Stop()
+	var err error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case err = <-errc:
+			break loop
+		case now := <-ticker.C:
+			dash.render(now)
+		}
+	}
+	if srv != nil {
+		srv.Close()
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if serr := d.Shutdown(shutdown); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// load submits jobs at cfg.rate until ctx is done
+func load(ctx context.Context, d *gorker.Dispatcher, cfg config) {
+	if cfg.rate <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+	defer ticker.Stop()
+	errFailed := errors.New("synthetic failure")
+	for n := 0; ; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		wait := time.Duration(rand.ExpFloat64() * float64(cfg.job))
+		fail := rand.Float64() < cfg.failRate
+		d.Add(func() error {
+			time.Sleep(wait)
+			if fail {
+				return errFailed
+			}
+			return nil
+		}, gorker.WithTags("kind-"+strconv.Itoa(n%3)))
+	}
+}
+
+func adminHandler(d *gorker.Dispatcher) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", d.HealthHandler())
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, d.Stats())
+	})
+	mux.HandleFunc("/workers", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, d.WorkerStats())
+	})
+	mux.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f := gorker.HistoryFilter{
+			Tag:     q.Get("tag"),
+			Outcome: gorker.Outcome(q.Get("outcome")),
+			Limit:   100,
+		}
+		if limit, err := strconv.Atoi(q.Get("limit")); err == nil {
+			f.Limit = limit
+		}
+		writeJSON(w, d.History(f))
+	})
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusInternalServerError)
+	}
+}
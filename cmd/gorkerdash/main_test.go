@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+func TestDashboard(t *testing.T) {
+	cfg := config{workers: 2, burst: 4, rate: 1000, job: time.Millisecond, failRate: 0.5}
+	d := newDispatcher(cfg)
+	defer d.Stop(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	load(ctx, d, cfg)
+	d.Wait()
+
+	srv := httptest.NewServer(adminHandler(d))
+	defer srv.Close()
+	tests := []struct {
+		path string
+		code int
+		body any
+	}{
+		{path: "/healthz", code: http.StatusOK},
+		{path: "/stats", code: http.StatusOK, body: new(gorker.Stats)},
+		{path: "/workers", code: http.StatusOK, body: new([]gorker.WorkerStat)},
+		{path: "/history?outcome=failed&limit=5", code: http.StatusOK, body: new([]gorker.JobRecord)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res, err := http.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != tt.code {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.code)
+			}
+			if tt.body != nil {
+				if err := json.NewDecoder(res.Body).Decode(tt.body); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+	var recs []gorker.JobRecord
+	res, err := http.Get(srv.URL + "/history?outcome=failed&limit=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	json.NewDecoder(res.Body).Decode(&recs)
+	if len(recs) == 0 || len(recs) > 5 || recs[0].Outcome != gorker.OutcomeFailed {
+		t.Errorf("history = %+v, want up to 5 failed jobs", recs)
+	}
+
+	var out bytes.Buffer
+	newDashboard(&out, d).render(time.Now().Add(time.Second))
+	for _, want := range []string{"gorkerdash", "health      ok", "throughput", "worker 0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dashboard does not show %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type coalescer struct {
+	mu      sync.Mutex
+	pending map[string]*coalesced
+}
+
+type coalesced struct {
+	fn   JobFunc
+	opts []JobOption
+	echs []chan error
+}
+
+func AddCoalesced(key string, window time.Duration, fn func() error, opts ...JobOption) chan error {
+	return instance.AddCoalesced(key, window, fn, opts...)
+}
+
+// AddCoalesced collapses the submissions for key within window into a single job
+// submitted once the window, started by the first of them, closed. The job runs the
+// fn and options of the latest submission and every submission receives its result.
+// Wait and Barrier also wait for jobs whose window is still open.
+func (d *Dispatcher) AddCoalesced(key string, window time.Duration, fn func() error, opts ...JobOption) chan error {
+	ech := make(chan error, 1)
+	jf := func(context.Context) error {
+		return fn()
+	}
+	c := d.coalescer
+	c.mu.Lock()
+	if p, ok := c.pending[key]; ok {
+		p.fn, p.opts = jf, opts
+		p.echs = append(p.echs, ech)
+		c.mu.Unlock()
+		return ech
+	}
+	if c.pending == nil {
+		c.pending = make(map[string]*coalesced)
+	}
+	// the job ID is taken now so that Wait and Barrier cover the open window
+	id := d.track()
+	c.pending[key] = &coalesced{fn: jf, opts: opts, echs: []chan error{ech}}
+	c.mu.Unlock()
+
+	d.clock.AfterFunc(window, func() {
+		c.mu.Lock()
+		p := c.pending[key]
+		delete(c.pending, key)
+		c.mu.Unlock()
+		res := d.AddJob(p.fn, append(p.opts, withID(id))...)
+		go func() {
+			err := <-res
+			for _, ech := range p.echs {
+				ech <- err
+			}
+		}()
+	})
+	return ech
+}
@@ -0,0 +1,48 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_AddCoalesced(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var runs, last int32
+	submit := func(key string, n int32, err error) chan error {
+		return d.AddCoalesced(key, 20*time.Millisecond, func() error {
+			atomic.AddInt32(&runs, 1)
+			atomic.StoreInt32(&last, n)
+			return err
+		})
+	}
+	fail := errors.New("fail")
+	echs := []chan error{submit("index", 1, nil), submit("index", 2, nil), submit("index", 3, fail)}
+	other := submit("other", 10, nil)
+	if got := atomic.LoadInt32(&runs); got != 0 {
+		t.Fatalf("%d jobs ran before the window closed", got)
+	}
+	d.Wait()
+	for i, ech := range echs {
+		if err := <-ech; !errors.Is(err, fail) {
+			t.Errorf("submission %d error = %v, want %v", i, err, fail)
+		}
+	}
+	if err := <-other; err != nil {
+		t.Error(err)
+	}
+	if got := atomic.LoadInt32(&runs); got != 2 {
+		t.Errorf("runs = %d, want 2", got)
+	}
+
+	// a submission after the window opens a new one
+	if err := <-submit("index", 4, nil); err != nil {
+		t.Error(err)
+	}
+	if got := atomic.LoadInt32(&last); got != 4 {
+		t.Errorf("last run = %d, want 4", got)
+	}
+}
@@ -0,0 +1,152 @@
+package gorker
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownCodec is returned by tasks whose content type has no registered Codec
+var ErrUnknownCodec = errors.New("gorker: unknown codec")
+
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeGob  = "application/x-gob"
+)
+
+// Codec encodes the values of tasks added by AddValue. Codecs for formats such as
+// protobuf or msgpack are implemented on top of their library and registered with RegisterCodec.
+type Codec interface {
+	// ContentType identifies the encoding, it is stored with every task
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes values with encoding/json, it is the default codec
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec encodes values with encoding/gob
+	GobCodec Codec = gobCodec{}
+
+	codecs = struct {
+		sync.RWMutex
+		m map[string]Codec
+	}{m: map[string]Codec{
+		ContentTypeJSON: JSONCodec,
+		ContentTypeGob:  GobCodec,
+	}}
+)
+
+// RegisterCodec makes c available to decode tasks tagged with its content type,
+// it replaces a codec registered for the same content type
+func RegisterCodec(c Codec) {
+	codecs.Lock()
+	codecs.m[c.ContentType()] = c
+	codecs.Unlock()
+}
+
+// CodecFor returns the codec registered for contentType
+func CodecFor(contentType string) (Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	c, ok := codecs.m[contentType]
+	return c, ok
+}
+
+// WithCodec sets the codec AddValue encodes with and registers it, the default is JSONCodec
+func WithCodec(c Codec) Option {
+	return func(d *Dispatcher) {
+		if c != nil {
+			RegisterCodec(c)
+			d.codec = c
+		}
+	}
+}
+
+// WithContentType tags a task added by AddTask with the content type of its payload,
+// handlers registered by HandleValue decode it with the matching codec
+func WithContentType(contentType string) JobOption {
+	return func(j *job) {
+		if j.task != nil {
+			j.task.contentType = contentType
+		}
+	}
+}
+
+type contentTypeKey struct{}
+
+// ContentTypeFrom returns the content type of the task executed with ctx,
+// it is empty for tasks added without one
+func ContentTypeFrom(ctx context.Context) string {
+	ct, _ := ctx.Value(contentTypeKey{}).(string)
+	return ct
+}
+
+// AddValue adds a task executed by the handler registered as name with v encoded
+// by the codec of d, the task fails when v cannot be encoded
+func (d *Dispatcher) AddValue(name string, v any, opts ...JobOption) chan error {
+	c := d.codec
+	payload, err := c.Marshal(v)
+	if err != nil {
+		ech := make(chan error, 1)
+		ech <- fmt.Errorf("gorker: encoding %s task: %w", name, err)
+		return ech
+	}
+	return d.AddTask(name, payload, append(opts, WithContentType(c.ContentType()))...)
+}
+
+// HandleValue registers fn as the handler of tasks named name, the payload is decoded
+// into a T with the codec of the task content type, or the codec of d for untagged tasks
+func HandleValue[T any](d *Dispatcher, name string, fn func(ctx context.Context, v T) error) *Dispatcher {
+	return d.Handle(name, func(ctx context.Context, payload []byte) error {
+		c := d.codec
+		if ct := ContentTypeFrom(ctx); ct != "" {
+			var ok bool
+			if c, ok = CodecFor(ct); !ok {
+				return fmt.Errorf("%w: %s", ErrUnknownCodec, ct)
+			}
+		}
+		var v T
+		if err := c.Unmarshal(payload, &v); err != nil {
+			return fmt.Errorf("gorker: decoding %s task: %w", name, err)
+		}
+		return fn(ctx, v)
+	})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) ContentType() string {
+	return ContentTypeGob
+}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,101 @@
+package gorker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type codecTestValue struct {
+	Name  string
+	Count int
+}
+
+func TestHandleValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{name: "default json"},
+		{name: "gob", codec: GobCodec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.codec != nil {
+				opts = append(opts, WithCodec(tt.codec))
+			}
+			d := New(1, opts...).QueueRunner().Start()
+			defer d.Stop(true)
+
+			want := codecTestValue{Name: "a", Count: 3}
+			got := make(chan codecTestValue, 1)
+			HandleValue(d, "value", func(ctx context.Context, v codecTestValue) error {
+				got <- v
+				return nil
+			})
+			if err := <-d.AddValue("value", want); err != nil {
+				t.Fatal(err)
+			}
+			if v := <-got; v != want {
+				t.Errorf("handled %+v, want %+v", v, want)
+			}
+		})
+	}
+}
+
+func TestHandleValue_MixedCodecs(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var got []codecTestValue
+	HandleValue(d, "value", func(ctx context.Context, v codecTestValue) error {
+		got = append(got, v)
+		return nil
+	})
+	gobbed, err := GobCodec.Marshal(codecTestValue{Name: "gob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-d.AddTask("value", gobbed, WithContentType(ContentTypeGob)); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-d.AddTask("value", []byte(`{"Name":"json"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-d.AddTask("value", nil, WithContentType("application/unknown")); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("unknown content type error = %v, want %v", err, ErrUnknownCodec)
+	}
+	if len(got) != 2 || got[0].Name != "gob" || got[1].Name != "json" {
+		t.Errorf("handled %+v", got)
+	}
+}
+
+func TestAppendTask_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        []string
+		contentType string
+	}{
+		{name: "untagged"},
+		{name: "content type", contentType: ContentTypeGob},
+		{name: "content type and tags", tags: []string{"a", "b"}, contentType: ContentTypeJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &job{id: 1, tags: tt.tags, task: &task{name: "n", payload: []byte("p"), contentType: tt.contentType}}
+			buf := appendTask(nil, j)
+			rec, n, err := readTask(bytes.NewReader(buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(buf) {
+				t.Errorf("read %d bytes, want %d", n, len(buf))
+			}
+			if rec.contentType != tt.contentType || len(rec.tags) != len(tt.tags) {
+				t.Errorf("decoded content type %q and tags %v", rec.contentType, rec.tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package gorker
+
+import "sync"
+
+type collectConfig struct {
+	closeOnDone bool
+}
+
+// CollectOption configures Collect
+type CollectOption func(*collectConfig)
+
+// CloseOnDone closes the result and error channels of Collect after every job has finished
+func CloseOnDone() CollectOption {
+	return func(c *collectConfig) {
+		c.closeOnDone = true
+	}
+}
+
+// Collect runs jobs on d and streams each result or error as soon as the job finishes,
+// jobs rejected by d report their RejectionError. Both channels are buffered for every
+// job so unread results never block workers.
+func Collect[T any](d *Dispatcher, jobs []func() (T, error), opts ...CollectOption) (<-chan T, <-chan error) {
+	cfg := new(collectConfig)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	results := make(chan T, len(jobs))
+	errs := make(chan error, len(jobs))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(jobs))
+	for _, fn := range jobs {
+		fn := fn
+		d.Add(func() error {
+			res, err := fn()
+			if err != nil {
+				return err
+			}
+			results <- res
+			return nil
+		}, onDone(func(err error) {
+			if err != nil {
+				errs <- err
+			}
+			wg.Done()
+		}))
+	}
+
+	if cfg.closeOnDone {
+		go func() {
+			wg.Wait()
+			close(results)
+			close(errs)
+		}()
+	}
+	return results, errs
+}
@@ -0,0 +1,65 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	d := New(3).QueueRunner().Start()
+	defer d.Stop(true)
+
+	want := errors.New("fail")
+	jobs := make([]func() (int, error), 10)
+	for i := range jobs {
+		n := i
+		jobs[i] = func() (int, error) {
+			if n%5 == 0 {
+				return 0, want
+			}
+			return n, nil
+		}
+	}
+
+	results, errs := Collect(d, jobs, CloseOnDone())
+	sum := 0
+	for r := range results {
+		sum += r
+	}
+	failed := 0
+	for err := range errs {
+		if !errors.Is(err, want) {
+			t.Errorf("error = %v, want %v", err, want)
+		}
+		failed++
+	}
+	if sum != 40 {
+		t.Errorf("sum = %d, want 40", sum)
+	}
+	if failed != 2 {
+		t.Errorf("failed = %d, want 2", failed)
+	}
+}
+
+func TestCollect_Rejected(t *testing.T) {
+	d := New(1).Start().Kill()
+
+	jobs := []func() (int, error){
+		func() (int, error) { return 1, nil },
+		func() (int, error) { return 2, nil },
+	}
+	results, errs := Collect(d, jobs, CloseOnDone())
+	for range results {
+		t.Error("rejected job produced a result")
+	}
+	n := 0
+	for err := range errs {
+		if !errors.Is(err, ErrStopped) {
+			t.Errorf("error = %v, want %v", err, ErrStopped)
+		}
+		n++
+	}
+	if n != len(jobs) {
+		t.Errorf("errors = %d, want %d", n, len(jobs))
+	}
+}
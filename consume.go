@@ -0,0 +1,97 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConsumeStats are the counters of a Consume call
+type ConsumeStats struct {
+	Received  uint64
+	Succeeded uint64
+	Failed    uint64
+	// Skipped counts received items which were not handled because ctx was done
+	Skipped uint64
+	Elapsed time.Duration
+}
+
+type consumeConfig struct {
+	inFlight int
+}
+
+// ConsumeOption configures Consume
+type ConsumeOption func(*consumeConfig)
+
+// ConsumeInFlight bounds how many received items are queued or running at once,
+// the default is twice the worker count
+func ConsumeInFlight(n int) ConsumeOption {
+	return func(c *consumeConfig) {
+		if n > 0 {
+			c.inFlight = n
+		}
+	}
+}
+
+// Consume receives items from in and runs handler for each of them on d until in is
+// closed or ctx is done, then waits for the items in flight. Receiving stops while the
+// in flight bound is reached, so a slow pool applies backpressure to the producer.
+// Items rejected by d count as failed. It returns ctx.Err() when ctx ended the consumption.
+func Consume[T any](ctx context.Context, d *Dispatcher, in <-chan T, handler func(T) error, opts ...ConsumeOption) (ConsumeStats, error) {
+	d.mu.RLock()
+	cfg := &consumeConfig{inFlight: 2 * d.workerCount}
+	d.mu.RUnlock()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var (
+		st    ConsumeStats
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, cfg.inFlight)
+	)
+	start := time.Now()
+	err := func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case slots <- struct{}{}:
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case item, ok := <-in:
+				if !ok {
+					<-slots
+					return nil
+				}
+				st.Received++
+				wg.Add(1)
+				var skipped bool
+				d.Add(func() error {
+					if ctx.Err() != nil {
+						skipped = true
+						return ctx.Err()
+					}
+					return handler(item)
+				}, onDone(func(err error) {
+					switch {
+					case skipped:
+						atomic.AddUint64(&st.Skipped, 1)
+					case err != nil:
+						atomic.AddUint64(&st.Failed, 1)
+					default:
+						atomic.AddUint64(&st.Succeeded, 1)
+					}
+					<-slots
+					wg.Done()
+				}))
+			}
+		}
+	}()
+	wg.Wait()
+	st.Elapsed = time.Since(start)
+	return st, err
+}
@@ -0,0 +1,91 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConsume(t *testing.T) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 100; i++ {
+			in <- i
+		}
+	}()
+	var sum int64
+	st, err := Consume(context.Background(), d, in, func(i int) error {
+		if i%10 == 0 {
+			return errors.New("fail")
+		}
+		atomic.AddInt64(&sum, int64(i))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ConsumeStats{Received: 100, Succeeded: 90, Failed: 10}
+	st.Elapsed = 0
+	if st != want {
+		t.Errorf("stats = %+v, want %+v", st, want)
+	}
+	if sum != 4500 {
+		t.Errorf("sum = %d, want 4500", sum)
+	}
+}
+
+func TestConsume_Canceled(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		in <- i
+	}
+	st, err := Consume(ctx, d, in, func(i int) error {
+		if i == 2 {
+			cancel()
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	}, ConsumeInFlight(2))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Consume() = %v, want %v", err, context.Canceled)
+	}
+	if st.Received > 4 {
+		t.Errorf("received %d items with 2 in flight before cancel", st.Received)
+	}
+	if st.Succeeded+st.Skipped != st.Received {
+		t.Errorf("stats = %+v do not add up", st)
+	}
+}
+
+func TestConsume_Rejected(t *testing.T) {
+	d := New(1).Start().Kill()
+
+	in := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		in <- i
+	}
+	close(in)
+	done := make(chan ConsumeStats, 1)
+	go func() {
+		st, _ := Consume(context.Background(), d, in, func(int) error { return nil }, ConsumeInFlight(1))
+		done <- st
+	}()
+	select {
+	case st := <-done:
+		if st.Received != 3 || st.Failed != 3 {
+			t.Errorf("stats = %+v, want 3 received and failed", st)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Consume with rejected items did not return")
+	}
+}
@@ -0,0 +1,72 @@
+package gorker
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/kpango/glg"
+)
+
+const (
+	// EnvWorkers sets the worker count of the package level Dispatcher
+	EnvWorkers = "GORKER_WORKERS"
+	// EnvQueueSize bounds the queue of every Dispatcher, see WithQueueLimit
+	EnvQueueSize = "GORKER_QUEUE_SIZE"
+)
+
+var defaults struct {
+	mu sync.RWMutex
+	// env holds the options read from the environment, opts the ones set by SetDefaults
+	env  []Option
+	opts []Option
+}
+
+// SetDefaults replaces the options applied to every Dispatcher created afterwards by New.
+// They are applied after the environment defaults and before the options passed to New,
+// which override them. SetDefaults without options clears the defaults.
+func SetDefaults(opts ...Option) {
+	defaults.mu.Lock()
+	defaults.opts = append([]Option(nil), opts...)
+	defaults.mu.Unlock()
+}
+
+// withDefaults returns the default options followed by opts
+func withDefaults(opts []Option) []Option {
+	defaults.mu.RLock()
+	defer defaults.mu.RUnlock()
+	if len(defaults.env) == 0 && len(defaults.opts) == 0 {
+		return opts
+	}
+	all := make([]Option, 0, len(defaults.env)+len(defaults.opts)+len(opts))
+	all = append(all, defaults.env...)
+	all = append(all, defaults.opts...)
+	return append(all, opts...)
+}
+
+// loadEnv reads the environment defaults, invalid values are logged and ignored
+func loadEnv() {
+	if n, ok := envInt(EnvWorkers); ok {
+		defaultWorker = n
+	}
+	var env []Option
+	if n, ok := envInt(EnvQueueSize); ok {
+		env = append(env, WithQueueLimit(n, OverflowBlock))
+	}
+	defaults.mu.Lock()
+	defaults.env = env
+	defaults.mu.Unlock()
+}
+
+func envInt(key string) (int, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		glg.Warnf("gorker: ignoring %s=%q, want a positive integer", key, v)
+		return 0, false
+	}
+	return n, true
+}
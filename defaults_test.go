@@ -0,0 +1,64 @@
+package gorker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetDefaults(t *testing.T) {
+	defer SetDefaults()
+
+	SetDefaults(WithName("default"), WithObserverInterval(time.Second, 0))
+	d := New(1, WithName("explicit"))
+	if d.name != "explicit" {
+		t.Errorf("name = %q, want the explicit option to win", d.name)
+	}
+	if d.observeInterval != time.Second {
+		t.Errorf("observe interval = %v, want the default of 1s", d.observeInterval)
+	}
+	if d = d.Reset(); d.name != "explicit" || d.observeInterval != time.Second {
+		t.Errorf("Reset lost the configuration, name %q interval %v", d.name, d.observeInterval)
+	}
+
+	SetDefaults()
+	if d := New(1); d.observeInterval != defaultObserveInterval {
+		t.Errorf("observe interval = %v after clearing the defaults", d.observeInterval)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	workers := defaultWorker
+	defer func() {
+		defaultWorker = workers
+		loadEnv()
+	}()
+	tests := []struct {
+		name    string
+		workers string
+		queue   string
+		want    int
+		limit   int
+	}{
+		{name: "unset", want: workers},
+		{name: "set", workers: "8", queue: "50", want: 8, limit: 50},
+		{name: "invalid", workers: "many", queue: "-1", want: workers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultWorker = workers
+			t.Setenv(EnvWorkers, tt.workers)
+			t.Setenv(EnvQueueSize, tt.queue)
+			loadEnv()
+			if defaultWorker != tt.want {
+				t.Errorf("workers = %d, want %d", defaultWorker, tt.want)
+			}
+			limit := 0
+			if l := New(1).queueLimit.Load(); l != nil {
+				limit = l.max
+			}
+			if limit != tt.limit {
+				t.Errorf("queue limit = %d, want %d", limit, tt.limit)
+			}
+		})
+	}
+}
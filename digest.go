@@ -0,0 +1,169 @@
+package gorker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// digestSubBits is the number of bits after the leading one kept by a Digest bucket,
+	// bounding the relative error of a quantile to 1/2^digestSubBits
+	digestSubBits = 4
+	digestSub     = 1 << digestSubBits
+	digestBuckets = digestSub + (64-digestSubBits)*digestSub
+)
+
+// Digest is a log-linear histogram of durations in the manner of an HDR histogram. It
+// estimates quantiles within about 6% in constant space, and digests of several
+// Dispatchers or processes can be merged. The zero value is empty and ready to use,
+// Add and Quantile may be called concurrently.
+type Digest struct {
+	counts [digestBuckets]uint64
+}
+
+// Percentiles are quantiles of a Digest
+type Percentiles struct {
+	P50 time.Duration `json:"p50_ns"`
+	P90 time.Duration `json:"p90_ns"`
+	P99 time.Duration `json:"p99_ns"`
+}
+
+func digestIndex(v uint64) int {
+	if v < digestSub {
+		return int(v)
+	}
+	e := bits.Len64(v) - 1
+	sub := int(v>>(e-digestSubBits)) & (digestSub - 1)
+	return digestSub + (e-digestSubBits)*digestSub + sub
+}
+
+// digestValue returns the midpoint of the bucket i
+func digestValue(i int) uint64 {
+	if i < digestSub {
+		return uint64(i)
+	}
+	shift := (i - digestSub) / digestSub
+	low := uint64(digestSub+(i-digestSub)%digestSub) << shift
+	return low + (uint64(1)<<shift)/2
+}
+
+// Add records d, negative durations are recorded as 0
+func (g *Digest) Add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	atomic.AddUint64(&g.counts[digestIndex(uint64(d))], 1)
+}
+
+// Merge adds the samples of other to g
+func (g *Digest) Merge(other *Digest) {
+	for i := range other.counts {
+		if n := atomic.LoadUint64(&other.counts[i]); n > 0 {
+			atomic.AddUint64(&g.counts[i], n)
+		}
+	}
+}
+
+// Count returns the number of samples in g
+func (g *Digest) Count() uint64 {
+	var n uint64
+	for i := range g.counts {
+		n += atomic.LoadUint64(&g.counts[i])
+	}
+	return n
+}
+
+// Quantile returns the estimated q quantile of the samples, 0 when g is empty
+func (g *Digest) Quantile(q float64) time.Duration {
+	return g.quantiles(q)[0]
+}
+
+// Percentiles returns the p50, p90 and p99 of the samples
+func (g *Digest) Percentiles() Percentiles {
+	qs := g.quantiles(0.5, 0.9, 0.99)
+	return Percentiles{P50: qs[0], P90: qs[1], P99: qs[2]}
+}
+
+// quantiles returns the estimated quantiles qs, given in ascending order, from one pass over the buckets
+func (g *Digest) quantiles(qs ...float64) []time.Duration {
+	var counts [digestBuckets]uint64
+	var total uint64
+	for i := range g.counts {
+		counts[i] = atomic.LoadUint64(&g.counts[i])
+		total += counts[i]
+	}
+	res := make([]time.Duration, len(qs))
+	if total == 0 {
+		return res
+	}
+	var seen uint64
+	k := 0
+	for i, n := range counts {
+		seen += n
+		for k < len(qs) && float64(seen) >= qs[k]*float64(total) && seen > 0 {
+			res[k] = time.Duration(digestValue(i))
+			k++
+		}
+		if k == len(qs) {
+			break
+		}
+	}
+	return res
+}
+
+// Snapshot returns a copy of g
+func (g *Digest) Snapshot() *Digest {
+	s := new(Digest)
+	s.Merge(g)
+	return s
+}
+
+// MarshalJSON encodes the non empty buckets of g as pairs of bucket index and count
+func (g *Digest) MarshalJSON() ([]byte, error) {
+	buckets := make([][2]uint64, 0)
+	for i := range g.counts {
+		if n := atomic.LoadUint64(&g.counts[i]); n > 0 {
+			buckets = append(buckets, [2]uint64{uint64(i), n})
+		}
+	}
+	return json.Marshal(struct {
+		Buckets [][2]uint64 `json:"buckets"`
+	}{buckets})
+}
+
+// UnmarshalJSON decodes a digest encoded by MarshalJSON
+func (g *Digest) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Buckets [][2]uint64 `json:"buckets"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*g = Digest{}
+	for _, bucket := range v.Buckets {
+		if bucket[0] >= digestBuckets {
+			return fmt.Errorf("gorker: digest bucket %d out of range", bucket[0])
+		}
+		g.counts[bucket[0]] = bucket[1]
+	}
+	return nil
+}
+
+// latencies are the digests of the queue wait and the run time of the jobs of a Dispatcher
+type latencies struct {
+	wait Digest
+	run  Digest
+}
+
+func Latencies() (wait, run *Digest) {
+	return instance.Latencies()
+}
+
+// Latencies returns snapshots of the digests of how long jobs waited in the queue before
+// their first attempt and how long their last attempt ran, for aggregation across Dispatchers
+func (d *Dispatcher) Latencies() (wait, run *Digest) {
+	return d.latency.wait.Snapshot(), d.latency.run.Snapshot()
+}
@@ -0,0 +1,91 @@
+package gorker
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDigest_Quantile(t *testing.T) {
+	g := new(Digest)
+	for i := 1; i <= 10000; i++ {
+		g.Add(time.Duration(i) * time.Microsecond)
+	}
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{q: 0.5, want: 5 * time.Millisecond},
+		{q: 0.9, want: 9 * time.Millisecond},
+		{q: 0.99, want: 9900 * time.Microsecond},
+		{q: 1, want: 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := g.Quantile(tt.q)
+		if rel := math.Abs(float64(got-tt.want)) / float64(tt.want); rel > 1.0/digestSub {
+			t.Errorf("Quantile(%v) = %v, want %v within %.1f%%", tt.q, got, tt.want, 100.0/digestSub)
+		}
+	}
+	if got := g.Count(); got != 10000 {
+		t.Errorf("Count = %d, want 10000", got)
+	}
+	if got := new(Digest).Quantile(0.5); got != 0 {
+		t.Errorf("empty Quantile = %v, want 0", got)
+	}
+}
+
+func TestDigest_Merge(t *testing.T) {
+	a, b := new(Digest), new(Digest)
+	for i := 0; i < 100; i++ {
+		a.Add(time.Millisecond)
+		b.Add(time.Second)
+	}
+	b.Add(time.Second)
+	a.Merge(b)
+	if got := a.Count(); got != 201 {
+		t.Errorf("merged Count = %d, want 201", got)
+	}
+	if p := a.Percentiles(); p.P50 < 900*time.Millisecond || p.P90 < 900*time.Millisecond {
+		t.Errorf("merged percentiles = %+v, want about a second", p)
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Digest)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Percentiles() != a.Percentiles() || decoded.Count() != a.Count() {
+		t.Errorf("decoded digest = %+v, want %+v", decoded.Percentiles(), a.Percentiles())
+	}
+	if err := json.Unmarshal([]byte(`{"buckets":[[100000,1]]}`), decoded); err == nil {
+		t.Error("out of range bucket decoded")
+	}
+}
+
+func TestStats_Percentiles(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Kill()
+
+	for i := 0; i < 10; i++ {
+		d.Add(func() error {
+			time.Sleep(2 * time.Millisecond)
+			return nil
+		})
+	}
+	d.Wait()
+	st := d.Stats()
+	if st.Duration.P50 < 2*time.Millisecond || st.Duration.P99 < st.Duration.P50 {
+		t.Errorf("Duration = %+v", st.Duration)
+	}
+	if st.QueueWait.P99 < time.Millisecond {
+		t.Errorf("QueueWait = %+v, queued jobs waited for the worker", st.QueueWait)
+	}
+	wait, run := d.Latencies()
+	if wait.Count() != 10 || run.Count() != 10 {
+		t.Errorf("Latencies counts = %d, %d, want 10", wait.Count(), run.Count())
+	}
+}
@@ -0,0 +1,35 @@
+package gorker
+
+import "sync/atomic"
+
+// WithDirectDispatch hands submitted jobs straight to the buffer read by the workers while
+// the queue is empty and the buffer has room, skipping the hop through the submission
+// channel and the queue runner. Other submissions take the queued path so that the order
+// of the queue is kept. Priority classes, fairness and spilling need every job to pass the
+// queue and disable the direct path. Run the comparison of the bench package to measure
+// the difference for a workload.
+func WithDirectDispatch() Option {
+	return func(d *Dispatcher) {
+		d.direct = true
+	}
+}
+
+// dispatchDirect puts j in the dispatch buffer, it returns false when j has to be queued
+func (d *Dispatcher) dispatchDirect(j *job) bool {
+	if d.classes != nil || d.fairness != nil || d.spill != nil {
+		return false
+	}
+	// holding d.mu keeps the buffer from being swapped or undispatched during the send
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.queue) > 0 || len(d.qin) > 0 || !d.State().dispatching() {
+		return false
+	}
+	select {
+	case d.qout <- j:
+		atomic.AddUint64(&d.buffer.dispatched, 1)
+		return true
+	default:
+		return false
+	}
+}
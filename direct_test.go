@@ -0,0 +1,79 @@
+package gorker
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestWithDirectDispatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		direct bool
+	}{
+		{name: "direct", opts: []Option{WithDirectDispatch()}, direct: true},
+		{name: "queued", opts: nil},
+		{name: "priority classes", opts: []Option{WithDirectDispatch(), WithPriorityClasses("high", "low")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// without a queue runner only the direct path reaches the workers
+			d := New(1, tt.opts...).Start()
+			defer d.Stop(true)
+			ech := d.Add(func() error { return nil })
+			if got := len(d.qin) == 0; got != tt.direct {
+				t.Fatalf("direct dispatch = %v, want %v", got, tt.direct)
+			}
+			if tt.direct {
+				if err := <-ech; err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestWithDirectDispatch_Go(t *testing.T) {
+	d := New(1, WithDirectDispatch()).Start()
+	defer d.Stop(true)
+	done := make(chan struct{})
+	d.Go(func() { close(done) })
+	if len(d.qin) != 0 {
+		t.Fatal("Go job was queued with direct dispatch")
+	}
+	<-done
+}
+
+func TestWithDirectDispatch_Order(t *testing.T) {
+	d := New(1, WithDirectDispatch(), WithBufferPolicy(BufferFixed, 1)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var (
+		mu  sync.Mutex
+		got []int
+	)
+	release := make(chan struct{})
+	d.Add(func() error {
+		<-release
+		return nil
+	})
+	echs := make([]chan error, 0, 10)
+	for i := 0; i < 10; i++ {
+		i := i
+		echs = append(echs, d.Add(func() error {
+			mu.Lock()
+			got = append(got, i)
+			mu.Unlock()
+			return nil
+		}))
+	}
+	close(release)
+	for _, ech := range echs {
+		<-ech
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("jobs ran in order %v", got)
+		}
+	}
+}
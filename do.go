@@ -0,0 +1,28 @@
+package gorker
+
+import "context"
+
+func Do(ctx context.Context, job func() error, opts ...JobOption) error {
+	return instance.Do(ctx, job, opts...)
+}
+
+// Do submits job to d and blocks until it completed or ctx is done, it returns the error
+// of the job, or ctx.Err() when ctx was done first. A job which did not start before ctx
+// was done is skipped, a running one is not interrupted and its result is discarded.
+func (d *Dispatcher) Do(ctx context.Context, job func() error, opts ...JobOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ech := d.Add(func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return job()
+	}, opts...)
+	select {
+	case err := <-ech:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Do(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	errJob := errors.New("job failed")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want error
+	}{
+		{name: "succeeded", ctx: context.Background()},
+		{name: "failed", ctx: context.Background(), err: errJob, want: errJob},
+		{name: "canceled", ctx: canceled, want: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.Do(tt.ctx, func() error { return tt.err }); !errors.Is(err, tt.want) {
+				t.Errorf("Do() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Do_Timeout(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	release := make(chan struct{})
+	busy := d.Add(func() error {
+		<-release
+		return nil
+	})
+	var ran int32
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Do(ctx, func() error {
+		atomic.StoreInt32(&ran, 1)
+		return nil
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() = %v, want context.DeadlineExceeded", err)
+	}
+	close(release)
+	<-busy
+	d.Wait()
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Error("job queued past its context ran")
+	}
+}
@@ -0,0 +1,133 @@
+package gorker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// trail keeps the last events of a Dispatcher for DumpState
+type trail struct {
+	mu     sync.Mutex
+	events []Event
+	next   int
+	full   bool
+}
+
+// WithEventTrail keeps the last n events of the Dispatcher in memory so that DumpState
+// prints them. Recording takes a lock on every event, the trail is off by default.
+func WithEventTrail(n int) Option {
+	return func(d *Dispatcher) {
+		if n < 1 || d.trail != nil {
+			return
+		}
+		d.trail = &trail{
+			events: make([]Event, n),
+		}
+		d.OnEvent(d.trail.record)
+	}
+}
+
+func (t *trail) record(ev Event) {
+	t.mu.Lock()
+	t.events[t.next] = ev
+	t.next++
+	if t.next == len(t.events) {
+		t.next = 0
+		t.full = true
+	}
+	t.mu.Unlock()
+}
+
+// recent returns the recorded events, oldest first
+func (t *trail) recent() []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]Event(nil), t.events[:t.next]...)
+	}
+	return append(append([]Event(nil), t.events[t.next:]...), t.events[:t.next]...)
+}
+
+// goroutineID parses the ID of the calling goroutine from its stack header
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+func DumpState(w io.Writer) error {
+	return instance.DumpState(w)
+}
+
+// DumpState writes a human readable snapshot of d to w: its state, queue, the job run by
+// every worker with the goroutine running it, the jobs Wait is blocked on and the events
+// recorded by WithEventTrail. It is meant for SIGQUIT handlers and debugging stuck pools.
+func (d *Dispatcher) DumpState(w io.Writer) error {
+	now := d.clock.Now()
+	d.mu.RLock()
+	queued, buffered, pinned := len(d.queue)+len(d.qin), len(d.qout), d.pinnedDepth()
+	workers := make([]*worker, len(d.workers))
+	copy(workers, d.workers)
+	count := d.workerCount
+	d.mu.RUnlock()
+
+	t := d.tracker
+	t.mu.Lock()
+	pending, oldest := len(t.pending), t.low
+	t.mu.Unlock()
+
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "gorker dispatcher %q state=%s workers=%d\n", d.name, d.State(), count)
+	fmt.Fprintf(b, "queue: queued=%d buffered=%d pinned=%d inflight=%d\n",
+		queued, buffered, pinned, atomic.LoadInt64(&d.inflight))
+	fmt.Fprintf(b, "jobs: submitted=%d succeeded=%d failed=%d pending=%d",
+		atomic.LoadUint64(&d.submitted), atomic.LoadUint64(&d.succeeded), atomic.LoadUint64(&d.failed), pending)
+	if pending > 0 {
+		fmt.Fprintf(b, " oldest=%d", oldest)
+	}
+	b.WriteString("\n")
+	for i, wk := range workers {
+		s := &wk.stats
+		fmt.Fprintf(b, "worker %d: id=%d goroutine=%d", i, atomic.LoadUint64(&s.id), atomic.LoadUint64(&s.goroutine))
+		id := JobID(atomic.LoadUint64(&s.current))
+		if id == 0 {
+			b.WriteString(" idle\n")
+			continue
+		}
+		since := time.Unix(0, atomic.LoadInt64(&s.since))
+		fmt.Fprintf(b, " job=%d", id)
+		if tags := s.tags.Load(); tags != nil {
+			fmt.Fprintf(b, " tags=%v", *tags)
+		}
+		fmt.Fprintf(b, " started=%s running=%s\n", since.Format(time.RFC3339Nano), now.Sub(since))
+	}
+	if d.trail != nil {
+		b.WriteString("recent events:\n")
+		for _, ev := range d.trail.recent() {
+			fmt.Fprintf(b, "  %s %s", ev.Time.Format(time.RFC3339Nano), ev.Type)
+			if ev.Job != nil {
+				fmt.Fprintf(b, " job=%d", ev.Job.ID)
+			}
+			if ev.Worker != 0 {
+				fmt.Fprintf(b, " worker=%d", ev.Worker)
+			}
+			if ev.Err != nil {
+				fmt.Fprintf(b, " err=%q", ev.Err.Error())
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
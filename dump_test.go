@@ -0,0 +1,55 @@
+package gorker
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDispatcher_DumpState(t *testing.T) {
+	d := New(1, WithName("dump"), WithEventTrail(8)).QueueRunner().Start()
+	defer d.Kill()
+
+	started, block := make(chan struct{}), make(chan struct{})
+	ech := d.Add(func() error {
+		close(started)
+		<-block
+		return nil
+	}, WithTags("stuck"))
+	<-started
+
+	b := new(bytes.Buffer)
+	if err := d.DumpState(b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`gorker dispatcher "dump" state=running workers=1`,
+		"inflight=1",
+		"pending=1 oldest=1",
+		"job=1 tags=[stuck]",
+		"recent events:",
+		"started job=1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dump does not contain %q:\n%s", want, out)
+		}
+	}
+	if !regexp.MustCompile(`goroutine=[1-9]`).MatchString(out) {
+		t.Errorf("dump has no worker goroutine:\n%s", out)
+	}
+	close(block)
+	<-ech
+}
+
+func TestTrail_recent(t *testing.T) {
+	tr := &trail{events: make([]Event, 2)}
+	for _, typ := range []EventType{EventEnqueued, EventStarted, EventFinished} {
+		tr.record(Event{Type: typ})
+	}
+	got := tr.recent()
+	if len(got) != 2 || got[0].Type != EventStarted || got[1].Type != EventFinished {
+		t.Errorf("recent = %v, want the last two events", got)
+	}
+}
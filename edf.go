@@ -0,0 +1,46 @@
+package gorker
+
+import (
+	"context"
+	"time"
+)
+
+// WithEarliestDeadlineFirst queues the jobs with a deadline, see WithDeadline, in order of
+// their deadline ahead of the jobs without one. Priority classes still take precedence,
+// the deadlines order the jobs within a class.
+func WithEarliestDeadlineFirst() Option {
+	return func(d *Dispatcher) {
+		d.edf = true
+	}
+}
+
+// WithDeadline sets the time by which the job must be done. A job still queued at deadline
+// completes with ErrExpired instead of running, and the context of a running job is
+// canceled with ErrExpired at deadline.
+func WithDeadline(deadline time.Time) JobOption {
+	return func(j *job) {
+		j.due = deadline
+	}
+}
+
+func AddWithDeadline(fn JobFunc, deadline time.Time, opts ...JobOption) chan error {
+	return instance.AddWithDeadline(fn, deadline, opts...)
+}
+
+// AddWithDeadline adds fn which must be done by deadline, see WithDeadline and WithEarliestDeadlineFirst
+func (d *Dispatcher) AddWithDeadline(fn JobFunc, deadline time.Time, opts ...JobOption) chan error {
+	return d.AddJob(fn, append(opts, WithDeadline(deadline))...)
+}
+
+// dueBefore reports whether j has to run before q under earliest deadline first
+func (j *job) dueBefore(q *job) bool {
+	return !j.due.IsZero() && (q.due.IsZero() || j.due.Before(q.due))
+}
+
+// withDue bounds ctx by the deadline of j
+func (j *job) withDue(ctx context.Context) (context.Context, context.CancelFunc) {
+	if j.due.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadlineCause(ctx, j.due, ErrExpired)
+}
@@ -0,0 +1,69 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithEarliestDeadlineFirst(t *testing.T) {
+	d := New(1, WithEarliestDeadlineFirst()).QueueRunner().Start().Pause()
+	defer d.Kill()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	add := func(name string, opts ...JobOption) chan error {
+		return d.Add(func() error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}, opts...)
+	}
+	now := time.Now()
+	add("none")
+	add("late", WithDeadline(now.Add(3*time.Second)))
+	expired := add("expired", WithDeadline(now.Add(-time.Second)))
+	add("soon", WithDeadline(now.Add(time.Second)))
+	add("later", WithDeadline(now.Add(2*time.Second)))
+
+	deadline := time.Now().Add(time.Second)
+	for len(d.SampleQueue(5)) != 5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	d.Resume()
+	if err := <-expired; !errors.Is(err, ErrExpired) {
+		t.Errorf("expired job = %v, want ErrExpired", err)
+	}
+	d.Wait()
+
+	want := []string{"soon", "later", "late", "none"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if got := d.Stats().Expired; got != 1 {
+		t.Errorf("Stats().Expired = %d, want 1", got)
+	}
+}
+
+func TestDispatcher_AddWithDeadline(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Kill()
+
+	err := <-d.AddWithDeadline(func(ctx context.Context) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}, time.Now().Add(10*time.Millisecond))
+	if !errors.Is(err, ErrExpired) {
+		t.Errorf("AddWithDeadline = %v, want ErrExpired", err)
+	}
+}
@@ -0,0 +1,170 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of a lifecycle Event
+type EventType string
+
+const (
+	EventEnqueued      EventType = "enqueued"
+	EventStarted       EventType = "started"
+	EventFinished      EventType = "finished"
+	EventFailed        EventType = "failed"
+	EventRetried       EventType = "retried"
+	EventDropped       EventType = "dropped"
+	EventWorkerStarted EventType = "worker_started"
+	EventWorkerStopped EventType = "worker_stopped"
+	EventScaled        EventType = "scaled"
+	// EventConfigChanged is emitted by Reconfigure, Changed lists the settings it changed
+	EventConfigChanged EventType = "config_changed"
+	// EventMemoryExceeded is emitted for every job aborted by the memory guard
+	EventMemoryExceeded EventType = "memory_exceeded"
+)
+
+// Event is a structured lifecycle event of a Dispatcher
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	// Job is set for job events
+	Job *JobInfo `json:"job,omitempty"`
+	// Record is set for finished, failed and dropped jobs
+	Record *JobRecord `json:"record,omitempty"`
+	// Err is the error of failed and dropped jobs
+	Err error `json:"-"`
+	// Worker is set for worker events
+	Worker uint64 `json:"worker,omitempty"`
+	// From and To are the worker counts of scaled events
+	From int `json:"from,omitempty"`
+	To   int `json:"to,omitempty"`
+	// Reason is why a scaled event happened, one of the ScaleReason constants
+	Reason string `json:"reason,omitempty"`
+	// Rejection is why a dropped job was rejected, it is empty for other drops
+	Rejection RejectionReason `json:"rejection,omitempty"`
+	// Changed are the settings changed by a config_changed event
+	Changed []string `json:"changed,omitempty"`
+	// Usage is the process memory in bytes of memory_exceeded events
+	Usage uint64 `json:"usage,omitempty"`
+}
+
+type eventHooks struct {
+	mu    sync.RWMutex
+	hooks []func(Event)
+	now   func() time.Time
+}
+
+func OnEvent(fn func(Event)) *Dispatcher {
+	return instance.OnEvent(fn)
+}
+
+// OnEvent registers fn to receive every lifecycle event of d.
+// fn is called synchronously from the goroutine causing the event and must not block.
+func (d *Dispatcher) OnEvent(fn func(Event)) *Dispatcher {
+	d.events.mu.Lock()
+	d.events.hooks = append(d.events.hooks, fn)
+	d.events.mu.Unlock()
+	return d
+}
+
+func (e *eventHooks) enabled() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.hooks) > 0
+}
+
+func (e *eventHooks) emit(ev Event) {
+	e.mu.RLock()
+	hooks := e.hooks
+	e.mu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	if ev.Time.IsZero() {
+		if e.now != nil {
+			ev.Time = e.now()
+		} else {
+			ev.Time = time.Now()
+		}
+	}
+	for _, fn := range hooks {
+		fn(ev)
+	}
+}
+
+func (e *eventHooks) job(typ EventType, j *job) {
+	if !e.enabled() {
+		return
+	}
+	info := j.info()
+	e.emit(Event{
+		Type: typ,
+		Job:  &info,
+	})
+}
+
+func (e *eventHooks) completed(j *job, finished time.Time, err error) {
+	if !e.enabled() {
+		return
+	}
+	typ := EventFinished
+	switch {
+	case j.started.IsZero():
+		typ = EventDropped
+	case err != nil:
+		typ = EventFailed
+	}
+	rec := j.record(finished, err)
+	ev := Event{
+		Type:   typ,
+		Time:   finished,
+		Job:    &rec.Job,
+		Record: &rec,
+		Err:    err,
+	}
+	var re *RejectionError
+	if errors.As(err, &re) {
+		ev.Rejection = re.Reason
+	}
+	e.emit(ev)
+}
+
+func (e *eventHooks) retried(j *job, err error) {
+	if !e.enabled() {
+		return
+	}
+	info := j.info()
+	e.emit(Event{
+		Type: EventRetried,
+		Job:  &info,
+		Err:  err,
+	})
+}
+
+func (e *eventHooks) memoryExceeded(info JobInfo, usage uint64) {
+	e.emit(Event{
+		Type:  EventMemoryExceeded,
+		Job:   &info,
+		Err:   ErrMemoryLimitExceeded,
+		Usage: usage,
+	})
+}
+
+func (e *eventHooks) worker(typ EventType, id uint64) {
+	e.emit(Event{
+		Type:   typ,
+		Worker: id,
+	})
+}
+
+func (e *eventHooks) scaled(at time.Time, from, to int, reason string) {
+	e.emit(Event{
+		Type:   EventScaled,
+		Time:   at,
+		From:   from,
+		To:     to,
+		Reason: reason,
+	})
+}
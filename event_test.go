@@ -0,0 +1,62 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_OnEvent(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events = make(map[EventType][]Event)
+	)
+	d := New(1).OnEvent(func(ev Event) {
+		mu.Lock()
+		events[ev.Type] = append(events[ev.Type], ev)
+		mu.Unlock()
+	}).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("fail")
+	<-d.Add(func() error { return nil }, WithTags("ok"))
+	<-d.Add(func() error { return fail })
+	d.UpScale(2)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(events[EventWorkerStarted])
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	counts := map[EventType]int{
+		EventEnqueued:      2,
+		EventStarted:       2,
+		EventFinished:      1,
+		EventFailed:        1,
+		EventScaled:        1,
+		EventWorkerStarted: 2,
+	}
+	for typ, want := range counts {
+		if got := len(events[typ]); got != want {
+			t.Errorf("%s events = %d, want %d", typ, got, want)
+		}
+	}
+	if ev := events[EventFinished][0]; ev.Record == nil || ev.Record.Outcome != OutcomeSucceeded || ev.Job.Tags[0] != "ok" {
+		t.Errorf("finished event = %+v", ev)
+	}
+	if ev := events[EventFailed][0]; !errors.Is(ev.Err, fail) || ev.Record.Error != "fail" {
+		t.Errorf("failed event = %+v", ev)
+	}
+	if ev := events[EventScaled][0]; ev.From != 1 || ev.To != 2 {
+		t.Errorf("scaled event = %+v, want 1 -> 2", ev)
+	}
+}
@@ -0,0 +1,38 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrExpired is returned by jobs which were queued longer than the maximum queue age or
+// reached their deadline
+var ErrExpired = errors.New("gorker: job expired in queue")
+
+// WithMaxQueueAge expires jobs which waited in the queue longer than age instead of
+// running them, their error channel receives ErrExpired. Expired jobs are counted in
+// Stats().Expired. The age is checked when a worker takes the job.
+func WithMaxQueueAge(age time.Duration) Option {
+	return func(d *Dispatcher) {
+		if age > 0 {
+			d.maxQueueAge.Store(int64(age))
+		}
+	}
+}
+
+// expire completes j with ErrExpired when it is too old to start or past its deadline and
+// reports whether it did, retried jobs already started and are not expired
+func (d *Dispatcher) expire(j *job) bool {
+	if !j.started.IsZero() {
+		return false
+	}
+	now := d.clock.Now()
+	age := time.Duration(d.maxQueueAge.Load())
+	if (age <= 0 || now.Sub(j.enqueued) <= age) && (j.due.IsZero() || now.Before(j.due)) {
+		return false
+	}
+	atomic.AddUint64(&d.expired, 1)
+	d.reject(j, RejectedExpired, ErrExpired)
+	return true
+}
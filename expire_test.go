@@ -0,0 +1,49 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithMaxQueueAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     time.Duration
+		stall   time.Duration
+		want    error
+		expired uint64
+	}{
+		{name: "expired after stall", age: 10 * time.Millisecond, stall: 50 * time.Millisecond, want: ErrExpired, expired: 3},
+		{name: "fresh", age: time.Second, stall: 10 * time.Millisecond},
+		{name: "disabled", stall: 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1, WithMaxQueueAge(tt.age)).QueueRunner().Start().Pause()
+			defer d.Stop(true)
+
+			ran := 0
+			echs := make([]chan error, 3)
+			for i := range echs {
+				echs[i] = d.Add(func() error {
+					ran++
+					return nil
+				})
+			}
+			time.Sleep(tt.stall)
+			d.Resume()
+			for _, ech := range echs {
+				if err := <-ech; !errors.Is(err, tt.want) {
+					t.Errorf("error = %v, want %v", err, tt.want)
+				}
+			}
+			if got := d.Stats().Expired; got != tt.expired {
+				t.Errorf("expired = %d, want %d", got, tt.expired)
+			}
+			if want := 3 - int(tt.expired); ran != want {
+				t.Errorf("ran = %d, want %d", ran, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package gorker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownExtension is returned for extension specs naming an unregistered extension
+var ErrUnknownExtension = errors.New("gorker: unknown extension")
+
+// Extension builds the Option enabling a capability from its configuration string
+type Extension func(config string) (Option, error)
+
+var (
+	extMu      sync.RWMutex
+	extensions = make(map[string]Extension)
+)
+
+func init() {
+	RegisterExtension("ondemand", func(config string) (Option, error) {
+		if config == "" {
+			return WithOnDemandWorkers(), nil
+		}
+		idle, err := time.ParseDuration(config)
+		if err != nil {
+			return nil, err
+		}
+		return WithOnDemandIdle(idle), nil
+	})
+	RegisterExtension("ratelimit", func(config string) (Option, error) {
+		rate, burst, _ := strings.Cut(config, "/")
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, err
+		}
+		b := 1
+		if burst != "" {
+			if b, err = strconv.Atoi(burst); err != nil {
+				return nil, err
+			}
+		}
+		return WithRateLimit(NewLimiter(r, b)), nil
+	})
+}
+
+// RegisterExtension makes an extension available by name to Config and EnableExtensions.
+// Extensions usually register themselves from an init function.
+// It panics if name is registered twice or ext is nil.
+func RegisterExtension(name string, ext Extension) {
+	extMu.Lock()
+	defer extMu.Unlock()
+	if ext == nil {
+		panic("gorker: RegisterExtension extension is nil")
+	}
+	if _, dup := extensions[name]; dup {
+		panic("gorker: RegisterExtension called twice for extension " + name)
+	}
+	extensions[name] = ext
+}
+
+// Extensions returns the sorted names of the registered extensions
+func Extensions() []string {
+	extMu.RLock()
+	defer extMu.RUnlock()
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnableExtensions builds the Options of extension specs of the form "name" or "name:config"
+func EnableExtensions(specs ...string) ([]Option, error) {
+	extMu.RLock()
+	defer extMu.RUnlock()
+	opts := make([]Option, 0, len(specs))
+	for _, spec := range specs {
+		name, config, _ := strings.Cut(spec, ":")
+		ext, ok := extensions[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownExtension, name)
+		}
+		opt, err := ext(config)
+		if err != nil {
+			return nil, fmt.Errorf("gorker: extension %s: %w", name, err)
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+// Config describes a Dispatcher in plain values, e.g. loaded from a configuration file
+type Config struct {
+	Workers    int      `json:"workers"`
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// NewWithConfig creates a Dispatcher from cfg with the extensions it enables
+func NewWithConfig(cfg Config, opts ...Option) (*Dispatcher, error) {
+	exts, err := EnableExtensions(cfg.Extensions...)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg.Workers, append(exts, opts...)...), nil
+}
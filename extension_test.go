@@ -0,0 +1,69 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+)
+
+func init() {
+	RegisterExtension("test-handler", func(config string) (Option, error) {
+		return func(d *Dispatcher) {
+			d.Handle(config, nil)
+		}, nil
+	})
+}
+
+func TestNewWithConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+		check   func(*Dispatcher) bool
+	}{
+		{
+			name: "builtin extensions",
+			cfg:  Config{Workers: 2, Extensions: []string{"ondemand:10ms", "ratelimit:100/5"}},
+			check: func(d *Dispatcher) bool {
+				return d.onDemand != nil && d.limiter.Load() != nil && d.workerCount == 2
+			},
+		},
+		{
+			name: "registered extension",
+			cfg:  Config{Workers: 1, Extensions: []string{"test-handler:export"}},
+			check: func(d *Dispatcher) bool {
+				_, ok := d.handlers["export"]
+				return ok
+			},
+		},
+		{
+			name:    "unknown extension",
+			cfg:     Config{Extensions: []string{"missing"}},
+			wantErr: ErrUnknownExtension,
+		},
+		{
+			name:    "invalid config",
+			cfg:     Config{Extensions: []string{"ratelimit:fast"}},
+			wantErr: errors.New("any"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewWithConfig(tt.cfg)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatal("NewWithConfig() succeeded, want error")
+				}
+				if errors.Is(tt.wantErr, ErrUnknownExtension) && !errors.Is(err, ErrUnknownExtension) {
+					t.Errorf("NewWithConfig() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(d) {
+				t.Error("extensions were not applied")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package gorker
+
+// fairness round-robins the dispatches across submitter identities, guarded by Dispatcher.mu
+type fairness struct {
+	identity func(JobInfo) string
+	// queued counts the queued jobs of the identities in ring, turn is the ring position served next
+	queued map[string]int
+	ring   []string
+	turn   int
+}
+
+// WithFairness dispatches queued jobs round-robin across the identities returned by
+// identityFn instead of in submission order, so that a burst from one submitter does
+// not delay the jobs of the others. Jobs of one identity keep their order. With priority
+// classes the round-robin applies within the highest queued class.
+func WithFairness(identityFn func(JobInfo) string) Option {
+	return func(d *Dispatcher) {
+		if identityFn == nil {
+			d.fairness = nil
+			return
+		}
+		d.fairness = &fairness{
+			identity: identityFn,
+			queued:   make(map[string]int),
+		}
+	}
+}
+
+// pushed accounts for j entering the queue
+func (f *fairness) pushed(j *job) {
+	j.identity = f.identity(j.info())
+	if f.queued[j.identity] == 0 {
+		f.ring = append(f.ring, j.identity)
+	}
+	f.queued[j.identity]++
+}
+
+// removed accounts for j leaving the queue and advances the turn past its identity when dispatched
+func (f *fairness) removed(j *job, dispatched bool) {
+	n, ok := f.queued[j.identity]
+	if !ok {
+		return
+	}
+	i := 0
+	for f.ring[i] != j.identity {
+		i++
+	}
+	if dispatched {
+		f.turn = i + 1
+	}
+	if n > 1 {
+		f.queued[j.identity] = n - 1
+	} else {
+		delete(f.queued, j.identity)
+		f.ring = append(f.ring[:i], f.ring[i+1:]...)
+		if f.turn > i {
+			f.turn--
+		}
+	}
+	if f.turn >= len(f.ring) {
+		f.turn = 0
+	}
+}
+
+// next returns the first job of the identity whose turn it is among the jobs of the priority
+// at the head of queue. Jobs put back at the head after being dispatched are not counted
+// and are returned first.
+func (f *fairness) next(queue []*job) *job {
+	head := queue[0]
+	if _, ok := f.queued[head.identity]; !ok {
+		return head
+	}
+	for k := range f.ring {
+		id := f.ring[(f.turn+k)%len(f.ring)]
+		for _, j := range queue {
+			if j.prio != head.prio {
+				break
+			}
+			if j.identity == id {
+				return j
+			}
+		}
+	}
+	return head
+}
@@ -0,0 +1,91 @@
+package gorker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithFairness(t *testing.T) {
+	identity := func(info JobInfo) string {
+		return info.Tags[0]
+	}
+	d := New(1, WithFairness(identity), WithBufferPolicy(BufferFixed, 1)).QueueRunner()
+	defer d.Stop(true)
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	add := func(tag string) {
+		d.Add(func() error {
+			mu.Lock()
+			order = append(order, tag)
+			mu.Unlock()
+			return nil
+		}, WithTags(tag))
+	}
+	for i := 0; i < 100; i++ {
+		add("bulk")
+	}
+	add("single")
+	for {
+		d.mu.RLock()
+		n := len(d.queue)
+		d.mu.RUnlock()
+		if n == 101 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	d.Start().Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 101 {
+		t.Fatalf("ran %d jobs, want 101", len(order))
+	}
+	for i, tag := range order {
+		if tag == "single" {
+			if i > 2 {
+				t.Errorf("single job ran at position %d behind the bulk", i)
+			}
+			return
+		}
+	}
+	t.Error("single job did not run")
+}
+
+func TestFairness_next(t *testing.T) {
+	f := &fairness{
+		identity: func(info JobInfo) string { return info.Tags[0] },
+		queued:   make(map[string]int),
+	}
+	var queue []*job
+	for _, tag := range []string{"a", "a", "a", "b", "c", "b"} {
+		j := &job{tags: []string{tag}}
+		f.pushed(j)
+		queue = append(queue, j)
+	}
+	var got []string
+	for len(queue) > 0 {
+		j := f.next(queue)
+		f.removed(j, true)
+		for i, q := range queue {
+			if q == j {
+				queue = append(queue[:i], queue[i+1:]...)
+				break
+			}
+		}
+		got = append(got, j.identity)
+	}
+	want := []string{"a", "b", "c", "a", "b", "a"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+	if len(f.ring) != 0 || len(f.queued) != 0 {
+		t.Errorf("identities left after the queue drained: %v", f.queued)
+	}
+}
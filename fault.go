@@ -0,0 +1,59 @@
+//go:build gorkerfault
+
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrFaultDropped is returned by job attempts dropped by WithFaultInjection
+var ErrFaultDropped = errors.New("gorker: job dropped by fault injection")
+
+// FaultPanic is the value of panics raised by WithFaultInjection
+type FaultPanic struct {
+	ID JobID
+}
+
+func (p FaultPanic) String() string {
+	return fmt.Sprintf("gorker: job %d panicked by fault injection", p.ID)
+}
+
+// DelayRange is the range of the delays added by WithFaultInjection, Max is exclusive
+type DelayRange struct {
+	Min, Max time.Duration
+}
+
+// WithFaultInjection makes every job attempt fail with ErrFaultDropped with probability
+// dropRate, wait for a random delay within delay and panic with a FaultPanic with
+// probability panicRate, so that retry and failure handling can be exercised.
+// Rates are between 0 and 1. It only exists in builds with the gorkerfault build tag.
+func WithFaultInjection(dropRate float64, delay DelayRange, panicRate float64) Option {
+	return func(d *Dispatcher) {
+		d.faults = func(ctx context.Context, j *job) error {
+			if rand.Float64() < dropRate {
+				return ErrFaultDropped
+			}
+			wait := delay.Min
+			if delay.Max > delay.Min {
+				wait += rand.N(delay.Max - delay.Min)
+			}
+			if wait > 0 {
+				timer := d.clock.NewTimer(wait)
+				defer timer.Stop()
+				select {
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				case <-timer.C():
+				}
+			}
+			if rand.Float64() < panicRate {
+				panic(FaultPanic{ID: j.id})
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+//go:build gorkerfault
+
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithFaultInjection(t *testing.T) {
+	tests := []struct {
+		name      string
+		dropRate  float64
+		delay     DelayRange
+		panicRate float64
+		want      error
+		minTime   time.Duration
+		panics    bool
+	}{
+		{name: "no faults"},
+		{name: "dropped", dropRate: 1, want: ErrFaultDropped},
+		{name: "delayed", delay: DelayRange{Min: 10 * time.Millisecond, Max: 20 * time.Millisecond}, minTime: 10 * time.Millisecond},
+		{name: "panics", panicRate: 1, panics: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1, WithSynchronous(), WithFaultInjection(tt.dropRate, tt.delay, tt.panicRate))
+			defer func() {
+				r := recover()
+				if _, ok := r.(FaultPanic); ok != tt.panics {
+					t.Errorf("recovered %v, want panic %v", r, tt.panics)
+				}
+			}()
+			start := time.Now()
+			err := <-d.Add(func() error { return nil })
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minTime {
+				t.Errorf("job took %s, want at least %s", elapsed, tt.minTime)
+			}
+		})
+	}
+}
+
+func TestWithFaultInjection_Retry(t *testing.T) {
+	d := New(1, WithFaultInjection(0.5, DelayRange{}, 0)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	failed := 0
+	for i := 0; i < 50; i++ {
+		if err := <-d.Add(func() error { return nil }, WithRetry(20, time.Microsecond)); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		t.Errorf("%d jobs failed despite retries", failed)
+	}
+}
@@ -0,0 +1,45 @@
+package gorker
+
+import "sync/atomic"
+
+func Go(fn func()) {
+	instance.Go(fn)
+}
+
+// Go runs fn without reporting its completion. It skips the error channel, the
+// pending job tracking so Wait, Barrier and WaitFor do not wait for it, the Submitted,
+// Succeeded and Failed counters, and the rate limits, circuit breakers, quotas and
+// memory guard applied to other jobs.
+// Finished Go jobs are counted in Stats().Detached. Go does not check the State of d,
+// jobs added to a stopped Dispatcher run once it is started again.
+func (d *Dispatcher) Go(fn func()) {
+	j := jobPool.Get().(*job)
+	j.run = fn
+	j.id = JobID(atomic.AddUint64(&d.jobSeq, 1))
+	j.enqueued = d.clock.Now()
+	if d.classes != nil {
+		j.prio = d.classes.defaultPrio()
+	}
+	if d.synchronous {
+		d.runDetached(j)
+		return
+	}
+	if d.direct && d.dispatchDirect(j) {
+		return
+	}
+	d.mu.RLock()
+	qin := d.qin
+	d.mu.RUnlock()
+	qin <- j
+}
+
+// runDetached runs a job added by Go and recycles it
+func (d *Dispatcher) runDetached(j *job) {
+	atomic.AddInt64(&d.inflight, 1)
+	j.run()
+	atomic.AddInt64(&d.inflight, -1)
+	atomic.AddUint64(&d.detached, 1)
+	d.releaseWorker(j)
+	*j = job{ech: j.ech}
+	jobPool.Put(j)
+}
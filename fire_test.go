@@ -0,0 +1,60 @@
+package gorker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Go(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var ran int32
+	for i := 0; i < 100; i++ {
+		d.Go(func() {
+			atomic.AddInt32(&ran, 1)
+		})
+	}
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Detached != 100 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Detached = %d, want 100", d.Stats().Detached)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&ran); got != 100 {
+		t.Errorf("ran %d jobs, want 100", got)
+	}
+	if st := d.Stats(); st.Submitted != 0 || st.Succeeded != 0 {
+		t.Errorf("Go jobs counted as regular jobs: %+v", st)
+	}
+}
+
+func BenchmarkDispatcher_Go(b *testing.B) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+	var n int64
+	fn := func() { atomic.AddInt64(&n, 1) }
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Go(fn)
+	}
+	for atomic.LoadInt64(&n) != int64(b.N) {
+		time.Sleep(10 * time.Microsecond)
+	}
+}
+
+func BenchmarkDispatcher_Add_FireAndForget(b *testing.B) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+	fn := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.AddJob(fn)
+	}
+	d.Wait()
+}
@@ -0,0 +1,226 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrFlowCycle is returned by Flow.Run when the dependencies form a cycle
+	ErrFlowCycle = errors.New("gorker: flow dependency cycle")
+	// ErrFlowInvalid is returned by Flow.Run for duplicate nodes and unknown dependencies
+	ErrFlowInvalid = errors.New("gorker: invalid flow")
+)
+
+// FlowOption configures a node added to a Flow
+type FlowOption func(*flowNode)
+
+// DependsOn makes the node wait for the named nodes to succeed
+func DependsOn(names ...string) FlowOption {
+	return func(n *flowNode) {
+		n.deps = append(n.deps, names...)
+	}
+}
+
+type flowNode struct {
+	name       string
+	fn         JobFunc
+	deps       []string
+	dependents []*flowNode
+	waiting    int
+	done       bool
+}
+
+// Flow is a DAG of jobs executed on a Dispatcher with every node running as soon as
+// all of its dependencies succeeded. A failed node skips its dependents with a SkippedError.
+type Flow struct {
+	dis   *Dispatcher
+	mu    *sync.Mutex
+	wg    *sync.WaitGroup
+	nodes map[string]*flowNode
+	order []*flowNode
+	errs  map[string]error
+	err   error
+	ctx   context.Context
+}
+
+// NewFlow creates an empty Flow whose nodes run on d
+func (d *Dispatcher) NewFlow() *Flow {
+	return &Flow{
+		dis:   d,
+		mu:    new(sync.Mutex),
+		wg:    new(sync.WaitGroup),
+		nodes: make(map[string]*flowNode),
+		errs:  make(map[string]error),
+	}
+}
+
+// Add adds the node name running fn, Run reports a duplicate name
+func (f *Flow) Add(name string, fn JobFunc, opts ...FlowOption) *Flow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.nodes[name]; ok {
+		if f.err == nil {
+			f.err = fmt.Errorf("%w: duplicate node %q", ErrFlowInvalid, name)
+		}
+		return f
+	}
+	n := &flowNode{
+		name: name,
+		fn:   fn,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	f.nodes[name] = n
+	f.order = append(f.order, n)
+	return f
+}
+
+// Run validates the graph and submits the nodes without dependencies, it must be called once.
+// Nodes which did not start before ctx is canceled are skipped.
+func (f *Flow) Run(ctx context.Context) error {
+	roots, err := f.prepare(ctx)
+	if err != nil {
+		return err
+	}
+	for _, n := range roots {
+		f.submit(n)
+	}
+	return nil
+}
+
+func (f *Flow) prepare(ctx context.Context) ([]*flowNode, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, n := range f.order {
+		n.waiting = len(n.deps)
+	}
+	for _, n := range f.order {
+		for _, dep := range n.deps {
+			p, ok := f.nodes[dep]
+			if !ok {
+				return nil, fmt.Errorf("%w: node %q depends on unknown node %q", ErrFlowInvalid, n.name, dep)
+			}
+			p.dependents = append(p.dependents, n)
+		}
+	}
+	if name, ok := f.cycle(); ok {
+		return nil, fmt.Errorf("%w through node %q", ErrFlowCycle, name)
+	}
+
+	f.ctx = ctx
+	f.wg.Add(len(f.order))
+	roots := make([]*flowNode, 0, len(f.order))
+	for _, n := range f.order {
+		if n.waiting == 0 {
+			roots = append(roots, n)
+		}
+	}
+	return roots, nil
+}
+
+// cycle reports a node on a dependency cycle using Kahn's algorithm, f.mu must be held
+func (f *Flow) cycle() (string, bool) {
+	waiting := make(map[*flowNode]int, len(f.order))
+	ready := make([]*flowNode, 0, len(f.order))
+	for _, n := range f.order {
+		waiting[n] = n.waiting
+		if n.waiting == 0 {
+			ready = append(ready, n)
+		}
+	}
+	visited := 0
+	for len(ready) > 0 {
+		n := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		visited++
+		for _, c := range n.dependents {
+			waiting[c]--
+			if waiting[c] == 0 {
+				ready = append(ready, c)
+			}
+		}
+	}
+	if visited == len(f.order) {
+		return "", false
+	}
+	for _, n := range f.order {
+		if waiting[n] > 0 {
+			return n.name, true
+		}
+	}
+	return "", false
+}
+
+// submit runs n on the Dispatcher, f.mu must not be held as the job may run inline.
+// n finishes from the completion hook of its job, so that a rejected job fails n.
+func (f *Flow) submit(n *flowNode) {
+	ctx := f.ctx
+	f.dis.AddJob(func(jctx context.Context) error {
+		if ctx.Err() != nil {
+			return &SkippedError{Cause: context.Cause(ctx)}
+		}
+		jctx, cancel := context.WithCancelCause(jctx)
+		defer cancel(nil)
+		stop := context.AfterFunc(ctx, func() {
+			cancel(context.Cause(ctx))
+		})
+		defer stop()
+		return n.fn(jctx)
+	}, onDone(func(err error) {
+		f.finish(n, err)
+	}))
+}
+
+func (f *Flow) finish(n *flowNode, err error) {
+	f.mu.Lock()
+	f.settle(n, err)
+	var ready []*flowNode
+	if err == nil {
+		for _, c := range n.dependents {
+			c.waiting--
+			if c.waiting == 0 && !c.done {
+				ready = append(ready, c)
+			}
+		}
+	}
+	f.mu.Unlock()
+	for _, c := range ready {
+		f.submit(c)
+	}
+}
+
+// settle records the result of n and skips its dependents on failure, f.mu must be held
+func (f *Flow) settle(n *flowNode, err error) {
+	if n.done {
+		return
+	}
+	n.done = true
+	defer f.wg.Done()
+	if err == nil {
+		return
+	}
+	f.errs[n.name] = err
+	for _, c := range n.dependents {
+		f.settle(c, &SkippedError{Cause: fmt.Errorf("dependency %q failed: %w", n.name, err)})
+	}
+}
+
+// Wait blocks until every node finished or was skipped and returns the errors by node name,
+// the map is empty when the whole flow succeeded
+func (f *Flow) Wait() map[string]error {
+	f.wg.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	errs := make(map[string]error, len(f.errs))
+	for name, err := range f.errs {
+		errs[name] = err
+	}
+	return errs
+}
@@ -0,0 +1,123 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFlow(t *testing.T) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	node := func(name string, err error) JobFunc {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return err
+		}
+	}
+	fail := errors.New("fail")
+	f := d.NewFlow().
+		Add("a", node("a", nil)).
+		Add("b", node("b", nil), DependsOn("a")).
+		Add("c", node("c", nil), DependsOn("a")).
+		Add("d", node("d", nil), DependsOn("b", "c")).
+		Add("e", node("e", fail), DependsOn("a")).
+		Add("f", node("f", nil), DependsOn("e", "d"))
+	if err := f.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	errs := f.Wait()
+
+	pos := make(map[string]int, len(order))
+	for i, name := range order {
+		pos[name] = i
+	}
+	for _, edge := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"a", "e"}} {
+		if pos[edge[0]] > pos[edge[1]] {
+			t.Errorf("%s ran before its dependency %s: %v", edge[1], edge[0], order)
+		}
+	}
+	if _, ok := pos["f"]; ok {
+		t.Error("f ran although e failed")
+	}
+	if len(errs) != 2 || !errors.Is(errs["e"], fail) {
+		t.Fatalf("errors = %v, want e and f", errs)
+	}
+	if !errors.Is(errs["f"], ErrSkipped) || !errors.Is(errs["f"], fail) {
+		t.Errorf("f error = %v, want skipped by fail", errs["f"])
+	}
+}
+
+func TestFlow_Invalid(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	tests := []struct {
+		name string
+		flow func(f *Flow) *Flow
+		want error
+	}{
+		{
+			name: "cycle",
+			flow: func(f *Flow) *Flow {
+				return f.Add("a", noop, DependsOn("c")).Add("b", noop, DependsOn("a")).Add("c", noop, DependsOn("b"))
+			},
+			want: ErrFlowCycle,
+		},
+		{
+			name: "unknown dependency",
+			flow: func(f *Flow) *Flow {
+				return f.Add("a", noop, DependsOn("x"))
+			},
+			want: ErrFlowInvalid,
+		},
+		{
+			name: "duplicate",
+			flow: func(f *Flow) *Flow {
+				return f.Add("a", noop).Add("a", noop)
+			},
+			want: ErrFlowInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.flow(New(1).NewFlow())
+			if err := f.Run(context.Background()); !errors.Is(err, tt.want) {
+				t.Errorf("Run() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlow_Rejected(t *testing.T) {
+	d := New(1).Start().Kill()
+
+	f := d.NewFlow().
+		Add("a", func(context.Context) error { return nil }).
+		Add("b", func(context.Context) error { return nil }, DependsOn("a"))
+	if err := f.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan map[string]error, 1)
+	go func() {
+		done <- f.Wait()
+	}()
+	select {
+	case errs := <-done:
+		if !errors.Is(errs["a"], ErrStopped) {
+			t.Errorf("a error = %v, want %v", errs["a"], ErrStopped)
+		}
+		if !errors.Is(errs["b"], ErrSkipped) || !errors.Is(errs["b"], ErrStopped) {
+			t.Errorf("b error = %v, want skipped by %v", errs["b"], ErrStopped)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait of a flow with rejected jobs did not return")
+	}
+}
@@ -0,0 +1,66 @@
+package gorker
+
+import "sync"
+
+type forwardRule struct {
+	to    *Dispatcher
+	match func(JobInfo) bool
+}
+
+type forwards struct {
+	mu    sync.RWMutex
+	rules []forwardRule
+}
+
+func ForwardTo(other *Dispatcher, predicate func(JobInfo) bool) *Dispatcher {
+	return instance.ForwardTo(other, predicate)
+}
+
+// ForwardTo reroutes the jobs submitted to d which match predicate to other, rules are
+// checked in registration order. A forwarded job runs with the options and limits of
+// other and is reported by its events and stats, while Wait, Barrier and WaitFor of d
+// still wait for it. Jobs are forwarded once, so rules forwarding back to d do not loop.
+func (d *Dispatcher) ForwardTo(other *Dispatcher, predicate func(JobInfo) bool) *Dispatcher {
+	if other == nil || other == d || predicate == nil {
+		return d
+	}
+	d.forwards.mu.Lock()
+	d.forwards.rules = append(d.forwards.rules, forwardRule{to: other, match: predicate})
+	d.forwards.mu.Unlock()
+	return d
+}
+
+// target returns the Dispatcher j is forwarded to, nil when no rule matches
+func (f *forwards) target(j *job) *Dispatcher {
+	f.mu.RLock()
+	rules := f.rules
+	f.mu.RUnlock()
+	if len(rules) == 0 {
+		return nil
+	}
+	info := j.info()
+	for _, r := range rules {
+		if r.match(info) {
+			return r.to
+		}
+	}
+	return nil
+}
+
+// forward submits j to other, keeping its ID of d pending until it completed there
+func (d *Dispatcher) forward(other *Dispatcher, j *job) chan error {
+	id := j.id
+	if id == 0 {
+		id = d.track()
+	}
+	j.id = 0
+	j.forwarded = true
+	done := j.done
+	j.done = func(err error) {
+		if done != nil {
+			done(err)
+		}
+		d.tracker.untrack(id, err)
+	}
+	return other.submit(j, nil)
+}
@@ -0,0 +1,50 @@
+package gorker
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDispatcher_ForwardTo(t *testing.T) {
+	fast := New(1).QueueRunner().Start()
+	defer fast.Stop(true)
+	slow := New(1).QueueRunner().Start()
+	defer slow.Stop(true)
+
+	isSlow := func(info JobInfo) bool {
+		return slices.Contains(info.Tags, "slow")
+	}
+	fast.ForwardTo(slow, isSlow)
+	// forwarding back does not loop
+	slow.ForwardTo(fast, isSlow)
+
+	block := make(chan struct{})
+	id, ech := fast.AddWithID(func(context.Context) error {
+		<-block
+		return nil
+	}, WithTags("slow"))
+	if err := <-fast.Add(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	barrier := fast.Barrier()
+	select {
+	case <-barrier.Done():
+		t.Fatal("barrier of the forwarding pool did not wait for the forwarded job")
+	default:
+	}
+	close(block)
+	if err := <-ech; err != nil {
+		t.Fatal(err)
+	}
+	barrier.Wait()
+	if err := fast.WaitFor(context.Background(), id); err != nil {
+		t.Errorf("WaitFor = %v", err)
+	}
+	if got := fast.Stats().Succeeded; got != 1 {
+		t.Errorf("fast succeeded = %d, want 1", got)
+	}
+	if got := slow.Stats().Succeeded; got != 1 {
+		t.Errorf("slow succeeded = %d, want 1", got)
+	}
+}
@@ -0,0 +1,91 @@
+package gorker
+
+import "sync"
+
+// Future is the result of a job submitted by Submit, which can be chained with Then
+type Future struct {
+	d    *Dispatcher
+	mu   sync.Mutex
+	done chan struct{}
+	err  error
+	next []func(err error)
+}
+
+func newFuture(d *Dispatcher) *Future {
+	return &Future{
+		d:    d,
+		done: make(chan struct{}),
+	}
+}
+
+func Submit(fn func() error, opts ...JobOption) *Future {
+	return instance.Submit(fn, opts...)
+}
+
+// Submit adds fn like Add and returns a Future of its result
+func (d *Dispatcher) Submit(fn func() error, opts ...JobOption) *Future {
+	f := newFuture(d)
+	d.Add(fn, append(opts, f.member)...)
+	return f
+}
+
+// member completes f with the result of j
+func (f *Future) member(j *job) {
+	done := j.done
+	j.done = func(err error) {
+		if done != nil {
+			done(err)
+		}
+		f.complete(err)
+	}
+}
+
+func (f *Future) complete(err error) {
+	f.mu.Lock()
+	f.err = err
+	next := f.next
+	f.next = nil
+	close(f.done)
+	f.mu.Unlock()
+	for _, fn := range next {
+		fn(err)
+	}
+}
+
+// Done returns a channel closed once the job completed
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the job completed and returns its error
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// Then calls next with the result of the job once it completed and adds the job next
+// returns to the same Dispatcher, the returned Future completes with its result. When next
+// returns nil the returned Future completes with the error of f, so that a failure is
+// propagated down a chain until a continuation handles it. next runs on the goroutine
+// completing the job and should not block.
+func (f *Future) Then(next func(err error) func() error) *Future {
+	g := newFuture(f.d)
+	cont := func(err error) {
+		fn := next(err)
+		if fn == nil {
+			g.complete(err)
+			return
+		}
+		f.d.Add(fn, g.member)
+	}
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		cont(f.err)
+	default:
+		f.next = append(f.next, cont)
+		f.mu.Unlock()
+	}
+	return g
+}
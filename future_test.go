@@ -0,0 +1,86 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFuture_Then(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Kill()
+
+	errFirst := errors.New("first")
+	tests := []struct {
+		name  string
+		first error
+		want  error
+		ran   []string
+	}{
+		{name: "chain", ran: []string{"first", "second", "third"}},
+		{name: "propagated", first: errFirst, want: errFirst, ran: []string{"first"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu  sync.Mutex
+				ran []string
+			)
+			step := func(name string, err error) func() error {
+				return func() error {
+					mu.Lock()
+					ran = append(ran, name)
+					mu.Unlock()
+					return err
+				}
+			}
+			// the continuations skip their job after a failure, leaving the error to the end of the chain
+			then := func(name string) func(err error) func() error {
+				return func(err error) func() error {
+					if err != nil {
+						return nil
+					}
+					return step(name, nil)
+				}
+			}
+			f := d.Submit(step("first", tt.first)).Then(then("second")).Then(then("third"))
+			if err := f.Wait(); !errors.Is(err, tt.want) {
+				t.Fatalf("chain = %v, want %v", err, tt.want)
+			}
+			if len(ran) != len(tt.ran) {
+				t.Fatalf("ran %v, want %v", ran, tt.ran)
+			}
+			for i := range ran {
+				if ran[i] != tt.ran[i] {
+					t.Fatalf("ran %v, want %v", ran, tt.ran)
+				}
+			}
+		})
+	}
+}
+
+func TestFuture_ThenCompleted(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Kill()
+
+	f := d.Submit(func() error { return errors.New("fail") })
+	<-f.Done()
+	// a continuation added after completion still runs and can recover from the error
+	g := f.Then(func(err error) func() error {
+		if err == nil {
+			t.Error("continuation did not receive the error")
+		}
+		return func() error { return nil }
+	})
+	if err := g.Wait(); err != nil {
+		t.Errorf("recovered chain = %v, want nil", err)
+	}
+
+	d.Kill()
+	rejected := g.Then(func(error) func() error {
+		return func() error { return nil }
+	})
+	if err := rejected.Wait(); !errors.Is(err, ErrStopped) {
+		t.Errorf("continuation on a stopped Dispatcher = %v, want ErrStopped", err)
+	}
+}
@@ -2,41 +2,120 @@ package gorker
 
 import (
 	"context"
-	"math"
+	"log/slog"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kpango/glg"
 )
 
 type Dispatcher struct {
-	running     bool
-	scaling     bool
-	resizing    bool
-	queue       []func()
-	qin         chan func()
-	qout        chan func()
-	wg          *sync.WaitGroup
-	mu          *sync.RWMutex
-	workerCount int
-	workers     []*worker
-	ctx         context.Context
-	cancel      context.CancelFunc
+	state           int32
+	scaling         int32
+	queue           []*job
+	qin             chan *job
+	qout            chan *job
+	changed         chan struct{}
+	jobSeq          uint64
+	submitted       uint64
+	succeeded       uint64
+	failed          uint64
+	inflight        int64
+	detached        uint64
+	expired         uint64
+	reserved        int64
+	maxQueueAge     atomic.Int64
+	tracker         *tracker
+	mu              *sync.RWMutex
+	workerCount     int
+	workers         []*worker
+	parent          context.Context
+	ctx             context.Context
+	cancel          context.CancelFunc
+	opts            []Option
+	onDemand        *onDemand
+	memGuard        *memoryGuard
+	budget          *budgetGuard
+	handlers        map[string]Handler
+	workerSeq       uint64
+	workerInit      func(context.Context, *WorkerScope) error
+	limiter         atomic.Pointer[Limiter]
+	breakers        *breakers
+	quota           *quota
+	completions     *completions
+	queueLimit      atomic.Pointer[queueLimit]
+	classes         *priorityClasses
+	scaleHistory    *scaleHistory
+	events          *eventHooks
+	tags            *tagStats
+	spill           *spill
+	buffer          *bufferPolicy
+	tuner           *subsystem
+	queueRunner     *subsystem
+	observer        *subsystem
+	clock           Clock
+	synchronous     bool
+	name            string
+	noLabels        bool
+	affinity        func(JobInfo) (uint64, bool)
+	shutdown        *shutdown
+	observeInterval time.Duration
+	observeJitter   time.Duration
+	codec           Codec
+	// faults is set by WithFaultInjection in builds with the gorkerfault tag
+	faults      func(context.Context, *job) error
+	lanes       [2]*Subpool
+	coalescer   *coalescer
+	cache       *resultCache
+	health      *healthCheck
+	propagation propagation
+	history     []HistorySink
+	burst       *burst
+	adaptive    *adaptive
+	inline      *inlineRunner
+	trail       *trail
+	fairness    *fairness
+	schedule    *scaleSchedule
+	forwards    *forwards
+	quarantine  *quarantine
+	slo         *slo
+	logger      *slog.Logger
+	running     *runningJobs
+	edf         bool
+	latency     *latencies
+	suspended   *suspended
+	shedder     *shedder
+	errs        *jobErrors
+	reporter    *reporter
+	direct      bool
+	// reconfiguring serializes Reconfigure
+	reconfiguring sync.Mutex
 }
 
 type worker struct {
 	dis     *Dispatcher
 	kill    chan struct{}
+	done    chan struct{}
 	running bool
+	// inbox holds the jobs pinned to the worker, pinned is its length
+	inbox  []*job
+	pinned int32
+	stats  workerStats
 }
 
 var (
-	defaultWorker   = 3
-	bufferSizeLimit = 1000000.0
-	instance        *Dispatcher
-	once            sync.Once
+	defaultWorker = 3
+	// defaultObserveInterval is how often the worker observer reconciles the worker count
+	defaultObserveInterval = 100 * time.Millisecond
+	bufferSizeLimit        = 1000000.0
+	instance               *Dispatcher
+	once                   sync.Once
 )
 
 func init() {
+	loadEnv()
 	instance = New(defaultWorker)
 }
 
@@ -58,59 +137,148 @@ func Get(maxWorker int) *Dispatcher {
 	return instance
 }
 
-func New(maxWorker int) *Dispatcher {
+func New(maxWorker int, opts ...Option) *Dispatcher {
 	if maxWorker < 1 {
 		maxWorker = 1
 	}
 	dis := newDispatcher(maxWorker)
 	for i := range dis.workers {
-		dis.workers[i] = &worker{
-			dis:     dis,
-			kill:    make(chan struct{}, 1),
-			running: false,
-		}
+		dis.workers[i] = newWorker(dis)
+	}
+	dis.opts = opts
+	for _, opt := range withDefaults(opts) {
+		opt(dis)
 	}
 	return dis
 }
 
 func newDispatcher(maxWorker int) *Dispatcher {
-	qs := 100000
-	return &Dispatcher{
-		running:     false,
-		workerCount: maxWorker,
-		queue:       make([]func(), 0, qs),
-		qin:         make(chan func(), int(math.Min(float64(maxWorker*100), bufferSizeLimit))),
-		qout:        make(chan func(), int(math.Min(float64(maxWorker*100), bufferSizeLimit))),
-		wg:          new(sync.WaitGroup),
-		mu:          new(sync.RWMutex),
-		workers:     make([]*worker, maxWorker),
-		ctx:         context.Background(),
+	d := &Dispatcher{
+		workerCount:     maxWorker,
+		changed:         make(chan struct{}),
+		tracker:         newTracker(),
+		mu:              new(sync.RWMutex),
+		workers:         make([]*worker, maxWorker),
+		ctx:             context.Background(),
+		queueRunner:     new(subsystem),
+		tags:            newTagStats(),
+		events:          new(eventHooks),
+		observer:        new(subsystem),
+		buffer:          newBufferPolicy(),
+		tuner:           new(subsystem),
+		clock:           realClock{},
+		name:            defaultName,
+		completions:     newCompletions(),
+		scaleHistory:    new(scaleHistory),
+		shutdown:        newShutdown(),
+		observeInterval: defaultObserveInterval,
+		observeJitter:   defaultObserveInterval / 10,
+		codec:           JSONCodec,
+		coalescer:       new(coalescer),
+		cache:           new(resultCache),
+		health:          newHealthCheck(),
+		forwards:        new(forwards),
+		running:         newRunningJobs(),
+		latency:         new(latencies),
+		suspended:       newSuspended(),
+		errs:            new(jobErrors),
 	}
+	d.resetBuffer()
+	return d
 }
 
+// QueueRunner starts the goroutine moving submitted jobs to the workers.
+// Calling it again only increments a reference count, see StopQueueRunner.
 func (d *Dispatcher) QueueRunner() *Dispatcher {
-	go func() {
-		var job func()
-		for {
-			select {
-			case <-d.ctx.Done():
-				return
-			case job = <-d.qin:
-				d.mu.Lock()
-				d.queue = append(d.queue, job)
-				d.mu.Unlock()
+	d.queueRunner.start(d.runQueue)
+	return d
+}
+
+// StopQueueRunner releases a reference taken by QueueRunner and stops the goroutine with the last one
+func (d *Dispatcher) StopQueueRunner() *Dispatcher {
+	d.queueRunner.stop()
+	return d
+}
+
+func (d *Dispatcher) runQueue(stop context.Context) {
+	d.label("queue_runner")
+	for {
+		d.mu.RLock()
+		qin, changed := d.qin, d.changed
+		var (
+			out  chan *job
+			next *job
+		)
+		if len(d.queue) > 0 && d.State().dispatching() {
+			if next = d.next(); next != nil {
+				out = d.qout
 			}
-			if len(d.queue) > 0 {
-				select {
-				case d.qout <- d.queue[0]:
-					d.mu.Lock()
-					d.queue = d.queue[1:]
-					d.mu.Unlock()
-				}
+		}
+		d.mu.RUnlock()
+		// a job of a class with reserved workers holds its worker before a worker can receive it
+		held := next != nil && d.classes.reserving()
+		if held {
+			d.hold(next)
+		}
+
+		select {
+		case <-stop.Done():
+			if held {
+				d.releaseWorker(next)
 			}
+			return
+		case <-changed:
+		case j := <-qin:
+			d.enqueue(j)
+		case out <- next:
+			d.dequeue(next)
+			held = false
 		}
-	}()
-	return d
+		if held {
+			d.releaseWorker(next)
+		}
+	}
+}
+
+func (d *Dispatcher) enqueue(j *job) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.spill != nil && d.spill.accept(j, len(d.queue)) {
+		err := d.spill.write(j)
+		if err == nil {
+			return
+		}
+		glg.Warnf("gorker: failed to spill job %d: %v", j.id, err)
+	}
+	d.queue = d.push(d.queue, j)
+}
+
+func (d *Dispatcher) dequeue(j *job) {
+	atomic.AddUint64(&d.buffer.dispatched, 1)
+	d.mu.Lock()
+	if d.classes != nil {
+		d.classes.dispatched(j)
+	}
+	if d.fairness != nil {
+		d.fairness.removed(j, true)
+	}
+	for i, q := range d.queue {
+		if q == j {
+			d.queue = append(d.queue[:i], d.queue[i+1:]...)
+			break
+		}
+	}
+	if !d.State().dispatching() {
+		// the job raced with Pause or Stop
+		d.queue = append(d.undispatch(d.qout), d.queue...)
+	}
+	var lost []lostTask
+	if d.spill != nil {
+		d.queue = d.spill.pageIn(d, d.queue)
+		lost = d.spill.takeLost()
+	}
+	d.mu.Unlock()
+	d.failLost(lost)
 }
 
 func GetWorkerCount() int {
@@ -120,71 +288,80 @@ func GetWorkerCount() int {
 // GetWorkerCount returns current worker count this function will be blocking while worker scaling
 func (d *Dispatcher) GetWorkerCount() int {
 	for {
-		if !d.scaling && len(d.workers) == d.workerCount {
-			return len(d.workers)
+		d.mu.RLock()
+		n, want := len(d.workers), d.workerCount
+		d.mu.RUnlock()
+		if !d.isScaling() && n == want {
+			return n
 		}
 	}
 }
 
+// ScaleBuffer resizes the channel handing queued jobs to the workers to size slots.
+// Buffered jobs move back to the head of the queue and idle workers switch to the new channel.
+// The submission channel keeps its size, the queue runner empties it continuously.
 func (d *Dispatcher) ScaleBuffer(size int) *Dispatcher {
-	size = int(math.Min(float64(size*100), bufferSizeLimit))
+	size = clampBuffer(size)
 	d.mu.Lock()
-	oldin := d.qin
+	defer d.mu.Unlock()
+	if cap(d.qout) == size {
+		return d
+	}
 	oldout := d.qout
-	d.qin = make(chan func(), size)
-	d.qout = make(chan func(), size)
-	d.mu.Unlock()
-	d.wg.Add(1)
-	go func() {
-		defer d.wg.Done()
-		tmpQueue := make([]func(), 0, len(oldin))
-		for job := range oldin {
-			tmpQueue = append(tmpQueue, job)
-		}
-		d.mu.Lock()
-		d.queue = append(d.queue, tmpQueue...)
-		d.mu.Unlock()
-	}()
-	d.wg.Add(1)
-	go func() {
-		defer d.wg.Done()
-		tmpQueue := make([]func(), 0, len(oldout))
-		for job := range oldout {
-			tmpQueue = append(tmpQueue, job)
-		}
-		d.mu.Lock()
-		d.queue = append(tmpQueue, d.queue...)
-		d.mu.Unlock()
-	}()
+	d.qout = make(chan *job, size)
+	d.queue = append(d.undispatch(oldout), d.queue...)
+	d.notify()
 	return d
 }
 
+// notify wakes the goroutines waiting on the dispatch channel, d.mu must be held
+func (d *Dispatcher) notify() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// drain empties ch without blocking
+func drain(ch chan *job) []*job {
+	jobs := make([]*job, 0, len(ch))
+	for {
+		select {
+		case j := <-ch:
+			jobs = append(jobs, j)
+		default:
+			return jobs
+		}
+	}
+}
+
 func UpScale(workerCount int) *Dispatcher {
 	return instance.UpScale(workerCount)
 }
 
 func (d *Dispatcher) UpScale(workerCount int) *Dispatcher {
-	d.ScaleBuffer(workerCount * 100)
+	return d.upScale(workerCount, ScaleReasonUpScale)
+}
+
+func (d *Dispatcher) upScale(workerCount int, reason string) *Dispatcher {
+	d.resizeBuffer(workerCount)
 	d.mu.Lock()
-	d.scaling = true
+	atomic.StoreInt32(&d.scaling, 1)
+	from := len(d.workers)
 	diff := workerCount - len(d.workers)
 	for {
 		if diff < 1 {
 			break
 		}
-		d.workers = append(d.workers, &worker{
-			dis:     d,
-			kill:    make(chan struct{}, 1),
-			running: false,
-		})
+		d.workers = append(d.workers, newWorker(d))
 		diff--
 	}
 	d.workerCount = workerCount
+	to := len(d.workers)
 	d.mu.Unlock()
-	if d.running {
-		d.Start()
+	if d.State().active() && d.onDemand == nil {
+		d.startWorkers()
 	}
-	d.scaling = false
+	atomic.StoreInt32(&d.scaling, 0)
+	d.scaled(from, to, reason)
 	return d
 }
 
@@ -192,30 +369,56 @@ func DownScale(workerCount int) *Dispatcher {
 	return instance.DownScale(workerCount)
 }
 
+// DownScale removes workers down to workerCount without waiting for them.
+// A removed worker finishes its current job and takes no further one.
 func (d *Dispatcher) DownScale(workerCount int) *Dispatcher {
-	d.ScaleBuffer(workerCount * 100)
-	d.mu.Lock()
-	d.scaling = true
-	diff := len(d.workers) - workerCount
-	idx := 0
-	for {
-		if diff < 1 {
-			break
-		}
-		if d.running && d.workers[idx].running {
-			d.workers[idx].stop()
+	d.downScale(workerCount, ScaleReasonDownScale)
+	return d
+}
+
+func DownScaleGraceful(ctx context.Context, workerCount int) error {
+	return instance.DownScaleGraceful(ctx, workerCount)
+}
+
+// DownScaleGraceful is DownScale waiting until every removed worker finished its
+// current job, it returns ctx.Err() when ctx is done first
+func (d *Dispatcher) DownScaleGraceful(ctx context.Context, workerCount int) error {
+	for _, done := range d.downScale(workerCount, ScaleReasonDownScale) {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		d.workers = append(d.workers[:idx], d.workers[idx+1:]...)
-		diff--
-		idx++
-		if idx >= len(d.workers) {
-			idx = 0
+	}
+	return nil
+}
+
+// downScale removes the workers beyond workerCount and returns the done channels of the stopped ones
+func (d *Dispatcher) downScale(workerCount int, reason string) []chan struct{} {
+	if workerCount < 0 {
+		workerCount = 0
+	}
+	d.resizeBuffer(workerCount)
+	d.mu.Lock()
+	atomic.StoreInt32(&d.scaling, 1)
+	from := len(d.workers)
+	var stopped []chan struct{}
+	if workerCount < len(d.workers) {
+		for _, w := range d.workers[workerCount:] {
+			if w.running {
+				stopped = append(stopped, w.done)
+				w.stop()
+			}
+			d.unpin(w)
 		}
+		d.workers = d.workers[:workerCount:workerCount]
 	}
 	d.workerCount = workerCount
-	d.scaling = false
+	atomic.StoreInt32(&d.scaling, 0)
+	to := len(d.workers)
 	d.mu.Unlock()
-	return d
+	d.scaled(from, to, reason)
+	return stopped
 }
 
 func AutoScale() *Dispatcher {
@@ -226,10 +429,10 @@ func (d *Dispatcher) AutoScale() *Dispatcher {
 	d.mu.Lock()
 	if len(d.workers) > d.workerCount {
 		d.mu.Unlock()
-		d.DownScale(d.workerCount)
+		d.downScale(d.workerCount, ScaleReasonAutoScale)
 	} else if len(d.workers) < d.workerCount {
 		d.mu.Unlock()
-		d.UpScale(d.workerCount)
+		d.upScale(d.workerCount, ScaleReasonAutoScale)
 	} else {
 		d.mu.Unlock()
 	}
@@ -240,30 +443,112 @@ func StartWorkerObserver() *Dispatcher {
 	return instance.StartWorkerObserver()
 }
 
+// StartWorkerObserver starts the goroutine scaling workers to the configured worker count,
+// see WithObserverInterval. Calling it again only increments a reference count, see StopWorkerObserver.
 func (d *Dispatcher) StartWorkerObserver() *Dispatcher {
-	go func() {
-		for {
-			select {
-			case <-d.ctx.Done():
-				return
-			default:
-				if d.workerCount != len(d.workers) && !d.scaling {
-					d.AutoScale()
-				}
-			}
+	d.observer.start(d.observe)
+	return d
+}
+
+func StopWorkerObserver() *Dispatcher {
+	return instance.StopWorkerObserver()
+}
+
+// StopWorkerObserver releases a reference taken by StartWorkerObserver and stops the goroutine with the last one
+func (d *Dispatcher) StopWorkerObserver() *Dispatcher {
+	d.observer.stop()
+	return d
+}
+
+// observe reconciles the worker count every observer interval plus a random jitter
+func (d *Dispatcher) observe(stop context.Context) {
+	d.label("observer")
+	timer := d.clock.NewTimer(d.observeDelay())
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop.Done():
+			return
+		case <-timer.C():
+			d.ObserveOnce()
+			timer.Reset(d.observeDelay())
 		}
-	}()
+	}
+}
+
+func ObserveOnce() *Dispatcher {
+	return instance.ObserveOnce()
+}
+
+// ObserveOnce applies the scale schedule and scales the workers to the configured worker
+// count unless a scaling is in progress, it is what the worker observer runs on every tick
+func (d *Dispatcher) ObserveOnce() *Dispatcher {
+	if d.schedule != nil {
+		d.schedule.apply(d, d.clock.Now())
+	}
+	d.mu.RLock()
+	scale := d.workerCount != len(d.workers)
+	d.mu.RUnlock()
+	if scale && !d.isScaling() {
+		d.AutoScale()
+	}
+	return d
+}
+
+func (d *Dispatcher) observeDelay() time.Duration {
+	if d.observeJitter <= 0 {
+		return d.observeInterval
+	}
+	return d.observeInterval + time.Duration(rand.Int64N(int64(d.observeJitter)))
+}
+
+func Pause() *Dispatcher {
+	return instance.Pause()
+}
+
+// Pause stops handing queued jobs to the workers until Resume. Jobs already
+// running finish, buffered jobs return to the queue and submissions are still accepted.
+// It is a no-op unless d is running.
+func (d *Dispatcher) Pause() *Dispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.transition(StatePaused, StateRunning) {
+		return d
+	}
+	d.notify()
+	d.queue = append(d.undispatch(d.qout), d.queue...)
+	return d
+}
+
+func Resume() *Dispatcher {
+	return instance.Resume()
+}
+
+// Resume continues dispatching after Pause
+func (d *Dispatcher) Resume() *Dispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.transition(StateRunning, StatePaused) {
+		d.notify()
+	}
 	return d
 }
 
+// Paused reports whether d is paused by Pause
+func (d *Dispatcher) Paused() bool {
+	return d.State() == StatePaused
+}
+
 func Reset() *Dispatcher {
 	instance = instance.Reset()
 	return instance
 }
 
+// Reset stops d and returns a new Dispatcher with the same configuration, queued jobs are discarded
 func (d *Dispatcher) Reset() *Dispatcher {
 	d.Stop(true)
-	d = New(d.workerCount)
+	d.release()
+	d = New(d.workerCount, d.opts...)
 	return d
 }
 
@@ -274,9 +559,10 @@ func SafeReset() *Dispatcher {
 
 func (d *Dispatcher) SafeReset() *Dispatcher {
 	for {
-		if !d.scaling {
+		if !d.isScaling() {
 			d.Stop(true)
-			d = New(d.workerCount)
+			d.release()
+			d = New(d.workerCount, d.opts...)
 			return d
 		}
 	}
@@ -286,17 +572,64 @@ func StartWithContext(c context.Context) *Dispatcher {
 	return instance.StartWithContext(c)
 }
 
+// StartWithContext starts the workers, it is a no-op unless d was created or stopped.
+// A stopped Dispatcher can be started again and keeps its queued jobs.
 func (d *Dispatcher) StartWithContext(c context.Context) *Dispatcher {
+	if !d.transition(StateRunning, StateCreated, StateStopped) {
+		return d
+	}
+	d.start(c)
+	return d
+}
+
+// start creates the worker goroutines and resumes the subsystems
+func (d *Dispatcher) start(c context.Context) {
 	ctx, cancel := context.WithCancel(c)
+	d.mu.Lock()
+	d.parent = c
 	d.ctx = ctx
 	d.cancel = cancel
-	for i, w := range d.workers {
+	d.notify()
+	d.mu.Unlock()
+	if d.memGuard != nil {
+		d.spawn("memory_guard", func() { d.memGuard.run(ctx, d.clock, d.events) })
+	}
+	if d.budget != nil {
+		d.spawn("budget", func() { d.budget.run(ctx, d.clock) })
+	}
+	if d.burst != nil {
+		d.spawn("burst", func() { d.burst.run(ctx, d) })
+	}
+	if d.adaptive != nil {
+		d.spawn("adaptive", func() { d.adaptive.run(ctx, d) })
+	}
+	if d.slo != nil {
+		d.spawn("slo", func() { d.slo.run(ctx, d) })
+	}
+	if d.reporter != nil {
+		d.spawn("stats_reporter", func() { d.reporter.run(ctx, d) })
+	}
+	if d.onDemand != nil {
+		d.startOnDemand(d.ctx)
+	} else {
+		d.startWorkers()
+	}
+	d.queueRunner.resume()
+	d.observer.resume()
+	if d.buffer.policy == BufferAdaptive && !d.tuner.running() {
+		d.tuner.start(d.tuneBuffer)
+	}
+	d.tuner.resume()
+}
+
+func (d *Dispatcher) startWorkers() {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, w := range d.workers {
 		if !w.running {
-			d.workers[i].start(d.ctx)
+			w.start(d.ctx)
 		}
 	}
-	d.running = true
-	return d
 }
 
 func Start() *Dispatcher {
@@ -307,26 +640,145 @@ func (d *Dispatcher) Start() *Dispatcher {
 	return d.StartWithContext(context.Background())
 }
 
-func Add(job func() error) chan error {
-	return instance.Add(job)
+func Add(fn func() error, opts ...JobOption) chan error {
+	return instance.Add(fn, opts...)
 }
 
-func (d *Dispatcher) Add(job func() error) chan error {
-	ech := make(chan error, 1)
-	d.wg.Add(1)
-	d.qin <- func() {
-		ech <- job()
+func (d *Dispatcher) Add(fn func() error, opts ...JobOption) chan error {
+	return d.AddJob(func(context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+// AddJob adds a job which receives a context canceled when the Dispatcher stops
+// or a guard aborts the job
+func (d *Dispatcher) AddJob(fn JobFunc, opts ...JobOption) chan error {
+	return d.submit(&job{
+		fn: fn,
+	}, opts)
+}
+
+func (d *Dispatcher) submit(j *job, opts []JobOption) chan error {
+	for _, opt := range opts {
+		opt(j)
+	}
+	if !j.forwarded {
+		if other := d.forwards.target(j); other != nil {
+			return d.forward(other, j)
+		}
+	}
+	if j.id == 0 {
+		j.id = d.track()
 	}
-	return ech
+	d.propagation.capture(j)
+	if j.enqueued.IsZero() {
+		j.enqueued = d.clock.Now()
+	}
+	if j.ech == nil {
+		j.ech = make(chan error, 1)
+	}
+	atomic.AddUint64(&d.submitted, 1)
+	d.tags.submitted(j.tags)
+	if state := d.State(); !state.accepting() {
+		if state == StateDraining {
+			d.reject(j, RejectedDraining, ErrDraining)
+		} else {
+			d.reject(j, RejectedStopped, ErrStopped)
+		}
+		return j.ech
+	}
+	if d.classes != nil && !d.classes.assign(j) {
+		d.reject(j, RejectedQueueFull, ErrQueueFull)
+		return j.ech
+	}
+	if d.shedder != nil && d.shedder.drop(j) {
+		d.reject(j, RejectedShed, ErrShed)
+		return j.ech
+	}
+	if d.quota != nil && !d.quota.admit(j) {
+		d.reject(j, RejectedQuota, ErrQuotaExceeded)
+		return j.ech
+	}
+	var reject, callerRuns bool
+	if !j.reserved {
+		reject, callerRuns = d.overflow()
+	}
+	if reject {
+		d.reject(j, RejectedQueueFull, ErrQueueFull)
+		return j.ech
+	}
+	d.events.job(EventEnqueued, j)
+	if d.synchronous || callerRuns {
+		d.runJob(j, nil)
+		return j.ech
+	}
+	if d.inline != nil && d.runInline(j) {
+		return j.ech
+	}
+	if pinned, err := d.pin(j); err != nil {
+		d.complete(j, err)
+		return j.ech
+	} else if pinned {
+		return j.ech
+	}
+	if d.direct && d.dispatchDirect(j) {
+		return j.ech
+	}
+	d.mu.RLock()
+	qin := d.qin
+	d.mu.RUnlock()
+	qin <- j
+	return j.ech
+}
+
+// complete finishes a job which either ran or was removed from the queue
+func (d *Dispatcher) complete(j *job, err error) {
+	if err != nil {
+		atomic.AddUint64(&d.failed, 1)
+	} else {
+		atomic.AddUint64(&d.succeeded, 1)
+	}
+	if d.quota != nil && j.started.IsZero() {
+		d.quota.dropped(j)
+	}
+	if j.holding {
+		d.releaseWorker(j)
+	}
+	d.tags.completed(j.tags, !j.started.IsZero(), err)
+	if d.classes != nil {
+		d.classes.stats.completed(j.classTags(), !j.started.IsZero(), err)
+	}
+	finished := d.clock.Now()
+	if !j.started.IsZero() {
+		d.latency.run.Add(finished.Sub(j.started))
+	}
+	d.events.completed(j, finished, err)
+	if len(d.history) > 0 {
+		d.appendHistory(j, finished, err)
+	}
+	if err != nil {
+		d.errs.add(j.id, err)
+	}
+	d.completions.notify()
+	if j.done != nil {
+		j.done(err)
+	}
+	// j may be recycled once its result was received
+	id := j.id
+	if j.ech != nil {
+		j.ech <- err
+	}
+	d.tracker.untrack(id, err)
 }
 
 func Wait() {
 	instance.Wait()
 }
 
+// Wait blocks until the jobs submitted before the call completed, see Barrier
 func (d *Dispatcher) Wait() {
-	if d.running {
-		d.wg.Wait()
+	if d.State().active() {
+		d.Barrier().Wait()
 	}
 }
 
@@ -334,47 +786,232 @@ func Stop(immediately bool) *Dispatcher {
 	return instance.Stop(immediately)
 }
 
+// Stop stops d in place, queued jobs are kept and run after d is started again.
+// Unless immediately is set Stop drains d first, it waits for every submitted job
+// while new jobs are rejected. A stopped Dispatcher rejects jobs with ErrStopped.
+//
+// Deprecated: Stop(false) is Flush without a deadline. Use StopIntake, Flush and Kill,
+// which document what happens to queued and in-flight jobs.
 func (d *Dispatcher) Stop(immediately bool) *Dispatcher {
-	if !d.running {
+	if !immediately {
+		d.Flush(context.Background())
 		return d
 	}
+	if d.transition(StateStopped, StateRunning, StatePaused, StateDraining) {
+		d.halt()
+	}
+	return d
+}
 
-	if !immediately {
-		glg.Warn("waiting")
-		d.Wait()
+func Restart() *Dispatcher {
+	return instance.Restart()
+}
+
+// Restart lets in-flight jobs finish, recreates the worker goroutines and resumes
+// dispatching. Queued jobs are preserved.
+func (d *Dispatcher) Restart() *Dispatcher {
+	if st := d.State(); st != StateRunning && st != StatePaused {
+		return d.Start()
 	}
+	d.mu.RLock()
+	parent := d.parent
+	d.mu.RUnlock()
 
-	d.cancel()
+	d.queueRunner.suspend()
+	d.observer.suspend()
+	d.tuner.suspend()
+	d.mu.Lock()
+	workers := make([]*worker, len(d.workers))
+	copy(workers, d.workers)
+	d.mu.Unlock()
+	for _, w := range workers {
+		w.stopAndWait()
+	}
+	d.mu.Lock()
+	d.queue = append(d.undispatch(d.qout), d.queue...)
+	d.mu.Unlock()
 
-	d.running = false
-	d = New(len(d.workers))
+	d.cancel()
+	d.start(parent)
 	return d
 }
 
+// release frees resources which outlive Stop
+func (d *Dispatcher) release() {
+	if d.spill != nil {
+		d.mu.Lock()
+		d.spill.close()
+		d.mu.Unlock()
+	}
+}
+
+func newWorker(d *Dispatcher) *worker {
+	return &worker{
+		dis:     d,
+		kill:    make(chan struct{}, 1),
+		running: false,
+	}
+}
+
 func (w *worker) start(ctx context.Context) {
 	w.running = true
-	go func() {
+	w.kill = make(chan struct{}, 1)
+	w.done = make(chan struct{})
+	go func(kill, done chan struct{}) {
+		defer close(done)
+		labels := w.dis.label("worker")
+		scope := w.dis.newWorkerScope(ctx)
+		scope.labels = labels
+		w.stats.started(scope.ID, goroutineID(), w.dis.clock.Now())
+		w.dis.events.worker(EventWorkerStarted, scope.ID)
+		defer w.dis.events.worker(EventWorkerStopped, scope.ID)
 		for {
+			// a stopped worker must not take another job even when one is ready
 			select {
-			case <-w.kill:
+			case <-kill:
+				return
+			default:
+			}
+			if j := w.takePinned(); j != nil {
+				w.run(j, scope)
+				continue
+			}
+			w.dis.mu.RLock()
+			qout, changed := w.dis.qout, w.dis.changed
+			w.dis.mu.RUnlock()
+			select {
+			case <-kill:
 				return
 			case <-ctx.Done():
 				return
-			case job := <-w.dis.qout:
-				w.run(job)
+			case <-changed:
+			case j := <-qout:
+				w.run(j, scope)
+			}
+		}
+	}(w.kill, w.done)
+}
+
+func (d *Dispatcher) runJob(j *job, scope *WorkerScope) {
+	if j == nil {
+		return
+	}
+	if j.run != nil {
+		d.runDetached(j)
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&j.claimed, 0, 1) {
+		// the job was taken from the queue while it was dispatched
+		return
+	}
+	if j.fn == nil {
+		d.complete(j, nil)
+		return
+	}
+	if d.expire(j) {
+		return
+	}
+	if d.quota != nil && !d.quota.acquire(j) {
+		return
+	}
+	ctx, cancel := context.WithCancelCause(context.WithValue(d.ctx, workerScopeKey{}, scope))
+	defer cancel(nil)
+	ctx = context.WithValue(ctx, jobKey{}, jobScope{d: d, j: j})
+	ctx = d.propagation.inject(ctx, j)
+	ctx, cancelDue := j.withDue(ctx)
+	defer cancelDue()
+	if d.logger != nil {
+		ctx = d.withLogger(ctx, j)
+	}
+	if err := d.throttle(ctx, scope); err != nil {
+		d.finish(j, err)
+		return
+	}
+	// the quarantine screens j first so that a job it skips does not take the probe of a
+	// half-open breaker
+	var poisonKey string
+	if d.quarantine != nil {
+		var ok bool
+		if poisonKey, ok = d.screen(j); !ok {
+			return
+		}
+	}
+	var group string
+	if d.breakers != nil {
+		var ok bool
+		if group, ok = d.admit(j); !ok {
+			if poisonKey != "" {
+				d.quarantine.release(poisonKey)
 			}
+			return
 		}
-	}()
+	}
+	if d.memGuard != nil {
+		d.memGuard.track(j, cancel)
+		defer d.memGuard.untrack(j.id)
+	}
+	j.started = d.clock.Now()
+	if j.attempt == 0 {
+		wait := j.started.Sub(j.enqueued)
+		d.latency.wait.Add(wait)
+		if d.shedder != nil {
+			d.shedder.observe(wait, j.started)
+		}
+	}
+	d.running.track(j, cancel)
+	defer d.running.untrack(j.id)
+	if d.budget != nil {
+		d.budget.track(j, cancel)
+		defer d.budget.untrack(j.id)
+	}
+	atomic.AddInt64(&d.inflight, 1)
+	d.tags.started(j.tags)
+	if d.classes != nil {
+		d.classes.stats.started(j.classTags())
+	}
+	d.events.job(EventStarted, j)
+	var err error
+	if d.faults != nil {
+		err = d.faults(ctx, j)
+	}
+	if err == nil {
+		err = d.profiled(ctx, j)
+	}
+	atomic.AddInt64(&d.inflight, -1)
+	if j.token != "" && d.park(j, err, group, poisonKey) {
+		return
+	}
+	if group != "" {
+		d.breakers.record(group, err, d.clock.Now())
+	}
+	if poisonKey != "" {
+		d.quarantine.record(poisonKey, err, d.clock.Now())
+	}
+	if retried, err := d.retry(j, err); !retried {
+		d.finish(j, err)
+	}
 }
 
-func (w *worker) run(job func()) {
-	defer w.dis.wg.Done()
-	if job != nil {
-		job()
+// finish completes a job which was admitted to run, j must not be used afterwards
+// as jobs added by AddReusing are recycled once their result was received
+func (d *Dispatcher) finish(j *job, err error) {
+	if d.quota != nil {
+		d.quota.release(d, j)
 	}
+	d.complete(j, err)
 }
 
 func (w *worker) stop() {
 	w.kill <- struct{}{}
 	w.running = false
 }
+
+// stopAndWait stops w after its current job and waits for its goroutine to exit
+func (w *worker) stopAndWait() {
+	if !w.running {
+		return
+	}
+	done := w.done
+	w.stop()
+	<-done
+}
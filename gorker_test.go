@@ -1,8 +1,11 @@
 package gorker
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGetInstance(t *testing.T) {
@@ -74,7 +77,7 @@ func TestGet(t *testing.T) {
 				t.Errorf("Get() = %v, want %v", got, tt.want)
 			}
 			got.Start()
-			if !got.running {
+			if got.State() != StateRunning {
 				t.Error("worker is not running")
 			}
 			if got.workerCount != tt.maxWorker {
@@ -84,7 +87,7 @@ func TestGet(t *testing.T) {
 				t.Errorf("worker length = %v, want %v", len(got.workers), tt.maxWorker)
 			}
 			got.Stop(false)
-			if got.running {
+			if got.State() == StateRunning {
 				t.Error("worker is running")
 			}
 		})
@@ -142,15 +145,15 @@ func TestNew(t *testing.T) {
 			if len(got.workers) != tt.maxWorker {
 				t.Errorf("worker length = %v, want %v", len(got.workers), tt.maxWorker)
 			}
-			if got.running {
+			if got.State() == StateRunning {
 				t.Error("worker is running")
 			}
 			got.Start()
-			if !got.running {
+			if got.State() != StateRunning {
 				t.Error("worker is not running")
 			}
 			got.Stop(true)
-			if got.running {
+			if got.State() == StateRunning {
 				t.Error("worker is running")
 			}
 		})
@@ -178,3 +181,235 @@ func TestDispatcher_GetWorkerCount(t *testing.T) {
 		t.Error("invalid worker count")
 	}
 }
+
+func TestDispatcher_QueueRunner(t *testing.T) {
+	d := New(1).Start()
+	defer d.Stop(true)
+
+	d.QueueRunner().QueueRunner().QueueRunner()
+	if got := d.queueRunner.refs; got != 3 {
+		t.Errorf("queue runner references = %d, want 3", got)
+	}
+
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	d.StopQueueRunner().StopQueueRunner()
+	if !d.queueRunner.running() {
+		t.Error("queue runner stopped before last reference was released")
+	}
+	d.StopQueueRunner()
+	if d.queueRunner.running() {
+		t.Error("queue runner is running after last reference was released")
+	}
+	// stopping more often than starting is a no-op
+	d.StopQueueRunner()
+}
+
+func TestDispatcher_StartWorkerObserver(t *testing.T) {
+	d := New(1).Start()
+	defer d.Stop(true)
+
+	d.StartWorkerObserver().StartWorkerObserver()
+	if !d.observer.running() {
+		t.Fatal("observer is not running")
+	}
+	d.StopWorkerObserver()
+	if !d.observer.running() {
+		t.Error("observer stopped before last reference was released")
+	}
+	d.StopWorkerObserver()
+	if d.observer.running() {
+		t.Error("observer is running after last reference was released")
+	}
+}
+
+func TestDispatcher_Stop_InPlace(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Stop(false); got != d {
+		t.Error("Stop() returned a different Dispatcher")
+	}
+	if got := d.State(); got != StateStopped {
+		t.Fatalf("state after Stop = %v, want %v", got, StateStopped)
+	}
+	if err := <-d.Add(func() error { return nil }); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Add() on a stopped dispatcher = %v, want %v", err, ErrStopped)
+	}
+
+	d.Start().Pause()
+	ech := d.Add(func() error { return nil })
+	d.Stop(true)
+	select {
+	case <-ech:
+		t.Fatal("job ran on a stopped dispatcher")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	d.Start()
+	defer d.Stop(true)
+	select {
+	case err := <-ech:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued job did not run after restart")
+	}
+}
+
+func TestDispatcher_Restart(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	block := make(chan struct{})
+	running := d.Add(func() error {
+		<-block
+		return nil
+	})
+	queued := make([]chan error, 0, 10)
+	for i := 0; i < 10; i++ {
+		queued = append(queued, d.Add(func() error { return nil }))
+	}
+
+	restarted := make(chan *Dispatcher)
+	go func() {
+		restarted <- d.Restart()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(block)
+	if got := <-restarted; got != d {
+		t.Error("Restart() returned a different Dispatcher")
+	}
+	if err := <-running; err != nil {
+		t.Errorf("in-flight job error = %v", err)
+	}
+	for i, ech := range queued {
+		select {
+		case err := <-ech:
+			if err != nil {
+				t.Errorf("queued job %d error = %v", i, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("queued job %d did not run after Restart", i)
+		}
+	}
+}
+
+func TestDispatcher_Pause(t *testing.T) {
+	d := New(2).QueueRunner().Start().Pause()
+	defer d.Stop(true)
+
+	ech := d.Add(func() error { return nil })
+	select {
+	case <-ech:
+		t.Fatal("job ran while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !d.Paused() {
+		t.Error("Paused() = false")
+	}
+	d.Resume()
+	select {
+	case err := <-ech:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job did not run after Resume")
+	}
+}
+
+func TestDispatcher_DownScaleGraceful(t *testing.T) {
+	d := New(3).QueueRunner().Start()
+	defer d.Stop(true)
+
+	release := make(chan struct{})
+	echs := make([]chan error, 0, 3)
+	for i := 0; i < 3; i++ {
+		echs = append(echs, d.Add(func() error {
+			<-release
+			return nil
+		}))
+	}
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Running != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- d.DownScaleGraceful(context.Background(), 1)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("DownScaleGraceful() = %v before the in-flight jobs finished", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for i, ech := range echs {
+		if err := <-ech; err != nil {
+			t.Errorf("in-flight job %d error = %v", i, err)
+		}
+	}
+	if got := d.GetWorkerCount(); got != 1 {
+		t.Errorf("worker count = %d, want 1", got)
+	}
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDispatcher_ObserveOnce(t *testing.T) {
+	tests := []struct {
+		name   string
+		target int
+	}{
+		{name: "up", target: 4},
+		{name: "down", target: 1},
+		{name: "unchanged", target: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(2).Start()
+			defer d.Stop(true)
+			d.mu.Lock()
+			d.workerCount = tt.target
+			d.mu.Unlock()
+			if got := d.ObserveOnce(); got != d {
+				t.Error("ObserveOnce() returned a different Dispatcher")
+			}
+			if got := len(d.workers); got != tt.target {
+				t.Errorf("workers = %d, want %d", got, tt.target)
+			}
+		})
+	}
+}
+
+func TestWithObserverInterval(t *testing.T) {
+	d := New(2, WithObserverInterval(time.Millisecond, time.Millisecond)).Start().StartWorkerObserver()
+	defer d.Stop(true)
+	defer d.StopWorkerObserver()
+
+	d.mu.Lock()
+	d.workerCount = 3
+	d.mu.Unlock()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.RLock()
+		n := len(d.workers)
+		d.mu.RUnlock()
+		if n == 3 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("observer did not reconcile the worker count")
+}
@@ -0,0 +1,167 @@
+package gorkertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+// FakeClock is a gorker.Clock which only moves when Advance is called
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+var _ gorker.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a FakeClock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) gorker.Timer {
+	return c.add(d, 0, nil)
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) gorker.Ticker {
+	if d <= 0 {
+		panic("gorkertest: non-positive interval for NewTicker")
+	}
+	return fakeTicker{c.add(d, d, nil)}
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) gorker.Timer {
+	return c.add(d, 0, f)
+}
+
+// Advance moves the clock forward by d and fires every timer which became due,
+// AfterFunc callbacks run on the calling goroutine in deadline order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for {
+		t := c.next(end)
+		if t == nil {
+			break
+		}
+		c.now = t.when
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			c.remove(t)
+		}
+		if t.fn != nil {
+			c.mu.Unlock()
+			t.fn()
+			c.mu.Lock()
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+	}
+	c.now = end
+	c.mu.Unlock()
+}
+
+// BlockUntil waits until n timers or tickers are pending on c, it lets a test
+// advance the clock only after the code under test armed its timers
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// next returns the earliest timer due at or before end, c.mu must be held
+func (c *FakeClock) next(end time.Time) *fakeTimer {
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+	if t := c.timers[0]; !t.when.After(end) {
+		return t
+	}
+	return nil
+}
+
+func (c *FakeClock) add(d, period time.Duration, fn func()) *fakeTimer {
+	t := &fakeTimer{
+		clock:  c,
+		period: period,
+		fn:     fn,
+	}
+	if fn == nil {
+		t.ch = make(chan time.Time, 1)
+	}
+	c.mu.Lock()
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	return t
+}
+
+// remove unschedules t and reports whether it was pending, c.mu must be held
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, p := range c.timers {
+		if p == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	when   time.Time
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := c.remove(t)
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return active
+}
+
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
@@ -0,0 +1,34 @@
+// Package gorkertest provides helpers for testing code which uses gorker
+// without sleeping or depending on goroutine scheduling.
+package gorkertest
+
+import (
+	"testing"
+
+	"github.com/kpango/gorker"
+)
+
+// RunInline returns a started Dispatcher which runs every job on the goroutine
+// submitting it, so that a job has finished when Add returns
+func RunInline(opts ...gorker.Option) *gorker.Dispatcher {
+	opts = append(opts, gorker.WithSynchronous())
+	return gorker.New(1, opts...).Start()
+}
+
+// AssertDrained fails tb unless d has no queued and no running jobs
+func AssertDrained(tb testing.TB, d *gorker.Dispatcher) {
+	tb.Helper()
+	st := d.Stats()
+	if st.QueueDepth != 0 || st.Spilled != 0 || st.Running != 0 {
+		tb.Errorf("gorkertest: queue not drained: %d queued, %d spilled, %d running", st.QueueDepth, st.Spilled, st.Running)
+	}
+}
+
+// AssertExecuted fails tb unless exactly n jobs of d finished, successfully or not
+func AssertExecuted(tb testing.TB, d *gorker.Dispatcher, n uint64) {
+	tb.Helper()
+	st := d.Stats()
+	if got := st.Succeeded + st.Failed; got != n {
+		tb.Errorf("gorkertest: %d jobs executed, want %d", got, n)
+	}
+}
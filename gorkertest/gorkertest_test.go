@@ -0,0 +1,146 @@
+package gorkertest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+func TestRunInline(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	var records []gorker.JobRecord
+	d := RunInline(gorker.WithClock(clock))
+	defer d.Stop(true)
+	d.OnEvent(func(ev gorker.Event) {
+		if ev.Record != nil {
+			records = append(records, *ev.Record)
+		}
+	})
+
+	ran := 0
+	d.Add(func() error {
+		ran++
+		clock.Advance(time.Second)
+		return nil
+	})
+	d.Add(func() error {
+		ran++
+		return errors.New("fail")
+	})
+	if ran != 2 {
+		t.Fatalf("ran %d jobs before Add returned, want 2", ran)
+	}
+	AssertExecuted(t, d, 2)
+	AssertDrained(t, d)
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if got := records[0]; !got.StartedAt.Equal(start) || got.Duration != time.Second {
+		t.Errorf("record = %+v, want start %v and 1s duration", got, start)
+	}
+	if got := records[1].Outcome; got != gorker.OutcomeFailed {
+		t.Errorf("outcome = %v, want %v", got, gorker.OutcomeFailed)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	timer := clock.NewTimer(2 * time.Second)
+	ticker := clock.NewTicker(time.Second)
+	defer ticker.Stop()
+	fired := 0
+	clock.AfterFunc(1500*time.Millisecond, func() {
+		fired++
+	})
+
+	clock.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	case got := <-ticker.C():
+		if want := time.Unix(1, 0); !got.Equal(want) {
+			t.Errorf("tick = %v, want %v", got, want)
+		}
+	default:
+		t.Fatal("ticker did not fire")
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("timer did not fire")
+	}
+	if fired != 1 {
+		t.Errorf("AfterFunc fired %d times, want 1", fired)
+	}
+	if timer.Stop() {
+		t.Error("Stop of a fired timer reported active")
+	}
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-clock.NewTimer(time.Minute).C()
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+}
+
+func TestFakeClock_TotalTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	d := gorker.New(1, gorker.WithClock(clock)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	attempts := make(chan struct{}, 3)
+	ech := d.Add(func() error {
+		attempts <- struct{}{}
+		return errors.New("fail")
+	}, gorker.WithRetry(5, time.Second), gorker.WithTotalTimeout(2500*time.Millisecond))
+
+	<-attempts
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-attempts
+	// the next backoff of 2s ends after the total timeout in the time of the clock
+	select {
+	case err := <-ech:
+		if !errors.Is(err, gorker.ErrTotalTimeout) {
+			t.Errorf("job error = %v, want %v", err, gorker.ErrTotalTimeout)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job was retried past its total timeout")
+	}
+}
+
+type recorder struct {
+	errs []any
+}
+
+func (r *recorder) Error(args ...any) {
+	r.errs = append(r.errs, args...)
+}
+
+func TestVerifyNone(t *testing.T) {
+	d := gorker.New(1).QueueRunner().Start()
+	<-d.Add(func() error { return nil })
+
+	prev := leakTimeout
+	leakTimeout = 10 * time.Millisecond
+	r := new(recorder)
+	VerifyNone(r, d)
+	leakTimeout = prev
+	if len(r.errs) == 0 {
+		t.Error("VerifyNone passed for a running Dispatcher")
+	}
+	d.Kill()
+	VerifyNone(t, d)
+}
@@ -0,0 +1,40 @@
+package gorkertest
+
+import (
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+// TestingT is the subset of testing.TB used by VerifyNone, like goleak.TestingT
+type TestingT interface {
+	Error(args ...any)
+}
+
+// leakTimeout is how long VerifyNone waits for the goroutines to exit
+var leakTimeout = time.Second
+
+// VerifyNone reports an error on t when goroutines of d are still alive after waiting
+// up to a second for them to exit, see gorker.LeakCheck. Like goleak.VerifyNone it is
+// meant to be deferred, after d was stopped.
+func VerifyNone(t TestingT, d *gorker.Dispatcher) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	deadline := time.Now().Add(leakTimeout)
+	delay := time.Millisecond
+	for {
+		err := d.LeakCheck()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Error(err)
+			return
+		}
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+}
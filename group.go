@@ -0,0 +1,131 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// JobGroup submits jobs sharing a context to a Dispatcher. Canceling the group
+// removes its queued jobs and cancels the context of its running ones.
+type JobGroup struct {
+	d       *Dispatcher
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	stop    func() bool
+	mu      sync.Mutex
+	pending int
+	idle    chan struct{}
+	errs    []error
+}
+
+func NewJobGroup(ctx context.Context) *JobGroup {
+	return instance.NewJobGroup(ctx)
+}
+
+// NewJobGroup returns a JobGroup of d whose context is derived from ctx, so that the
+// group is also canceled with ctx, for instance when the request it serves is aborted
+func (d *Dispatcher) NewJobGroup(ctx context.Context) *JobGroup {
+	g := &JobGroup{d: d}
+	g.ctx, g.cancel = context.WithCancelCause(ctx)
+	g.stop = context.AfterFunc(g.ctx, g.cancelQueued)
+	return g
+}
+
+// Context returns the context shared by the jobs of g
+func (g *JobGroup) Context() context.Context {
+	return g.ctx
+}
+
+// Cancel cancels g, queued jobs fail with context.Canceled and running ones see their
+// context canceled
+func (g *JobGroup) Cancel() {
+	g.cancel(nil)
+}
+
+// Add submits fn as a member of g
+func (g *JobGroup) Add(fn func() error, opts ...JobOption) chan error {
+	return g.AddJob(func(context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+// AddJob submits fn as a member of g, fn receives a context canceled with g.
+// Members submitted after g was canceled fail without running.
+func (g *JobGroup) AddJob(fn JobFunc, opts ...JobOption) chan error {
+	g.mu.Lock()
+	g.pending++
+	g.mu.Unlock()
+	return g.d.AddJob(func(ctx context.Context) error {
+		if g.ctx.Err() != nil {
+			return context.Cause(g.ctx)
+		}
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		stop := context.AfterFunc(g.ctx, func() {
+			cancel(context.Cause(g.ctx))
+		})
+		defer stop()
+		return fn(ctx)
+	}, append(opts, g.member)...)
+}
+
+// member is the JobOption marking a job of g
+func (g *JobGroup) member(j *job) {
+	j.group = g
+	done := j.done
+	j.done = func(err error) {
+		if done != nil {
+			done(err)
+		}
+		g.completed(err)
+	}
+}
+
+func (g *JobGroup) completed(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil {
+		g.errs = append(g.errs, err)
+	}
+	g.pending--
+	if g.pending == 0 && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+}
+
+// cancelQueued fails the queued jobs of g with the cause of its cancellation
+func (g *JobGroup) cancelQueued() {
+	d := g.d
+	d.mu.Lock()
+	jobs := d.takeQueued(func(j *job) bool {
+		return j.group == g
+	})
+	d.mu.Unlock()
+	err := context.Cause(g.ctx)
+	for _, j := range jobs {
+		d.complete(j, err)
+	}
+}
+
+// Wait blocks until every job submitted to g completed and returns their errors
+// joined by errors.Join, nil when all of them succeeded. Wait releases the
+// resources of g once they completed, g must not be used afterwards.
+func (g *JobGroup) Wait() error {
+	g.mu.Lock()
+	if g.pending > 0 {
+		if g.idle == nil {
+			g.idle = make(chan struct{})
+		}
+		idle := g.idle
+		g.mu.Unlock()
+		<-idle
+		g.mu.Lock()
+	}
+	errs := g.errs
+	g.mu.Unlock()
+	g.stop()
+	g.cancel(nil)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobGroup_Wait(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail1, fail2 := errors.New("fail1"), errors.New("fail2")
+	tests := []struct {
+		name string
+		errs []error
+	}{
+		{name: "succeeded", errs: []error{nil, nil, nil}},
+		{name: "failed", errs: []error{nil, fail1, fail2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := d.NewJobGroup(context.Background())
+			for _, err := range tt.errs {
+				g.Add(func() error { return err })
+			}
+			err := g.Wait()
+			for _, want := range tt.errs {
+				if want != nil && !errors.Is(err, want) {
+					t.Errorf("Wait = %v, want it to contain %v", err, want)
+				}
+			}
+			if err != nil && errors.Join(tt.errs...) == nil {
+				t.Errorf("Wait = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestJobGroup_Cancel(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g := d.NewJobGroup(ctx)
+	started := make(chan struct{})
+	running := g.AddJob(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	var ran int32
+	var queued []chan error
+	for i := 0; i < 5; i++ {
+		queued = append(queued, g.Add(func() error {
+			atomic.AddInt32(&ran, 1)
+			return nil
+		}))
+	}
+	other := d.Add(func() error { return nil })
+	<-started
+	cancel()
+
+	done := make(chan error)
+	go func() { done <- g.Wait() }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after the group was canceled")
+	}
+	if err := <-running; !errors.Is(err, context.Canceled) {
+		t.Errorf("running member = %v, want %v", err, context.Canceled)
+	}
+	for _, ech := range queued {
+		if err := <-ech; !errors.Is(err, context.Canceled) {
+			t.Errorf("queued member = %v, want %v", err, context.Canceled)
+		}
+	}
+	if n := atomic.LoadInt32(&ran); n != 0 {
+		t.Errorf("%d canceled members ran", n)
+	}
+	if err := <-other; err != nil {
+		t.Errorf("job outside the group = %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+// Package grpcctl exposes a gorker Dispatcher as the gRPC service defined in control.proto.
+//
+// control.pb.go and control_grpc.pb.go are generated from control.proto with
+// protoc-gen-go and protoc-gen-go-grpc, run go generate after changing it.
+package grpcctl
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative control.proto
@@ -0,0 +1,72 @@
+package grpcctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/kpango/gorker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Handoff streams the queued tasks of d to the control service of the replacement
+// instance behind c during a rolling deploy and returns the number of tasks it accepted.
+// Tasks count as handed off once written to the stream, a call failing after that loses them.
+func Handoff(ctx context.Context, d *gorker.Dispatcher, c ControlClient) (int, error) {
+	stream, err := c.AcceptHandoff(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := d.Handoff(chunkWriter{stream: stream}); err != nil {
+		if err == io.EOF {
+			// the stream was aborted, its status is returned by CloseAndRecv
+			_, err = stream.CloseAndRecv()
+		}
+		return 0, err
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, err
+	}
+	return int(res.GetAccepted()), nil
+}
+
+// AcceptHandoff enqueues the tasks streamed by Handoff on the terminating instance
+func (s *Server) AcceptHandoff(stream Control_AcceptHandoffServer) error {
+	n, err := s.dis.AcceptHandoff(&chunkReader{stream: stream})
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "accepted %d tasks: %v", n, err)
+	}
+	return stream.SendAndClose(&HandoffResponse{Accepted: int32(n)})
+}
+
+type chunkWriter struct {
+	stream Control_AcceptHandoffClient
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	// the stream may keep the message after Send returns, p is reused by the caller
+	if err := w.stream.Send(&HandoffChunk{Data: bytes.Clone(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+type chunkReader struct {
+	stream Control_AcceptHandoffServer
+	buf    []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = chunk.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
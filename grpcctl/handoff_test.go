@@ -0,0 +1,70 @@
+package grpcctl
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kpango/gorker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandoff(t *testing.T) {
+	src := gorker.New(1).QueueRunner().Start().Pause()
+	defer src.Stop(true)
+	echs := make([]chan error, 0, 10)
+	for i := 0; i < 10; i++ {
+		echs = append(echs, src.AddTask("record", []byte(strconv.Itoa(i))))
+	}
+	deadline := time.Now().Add(time.Second)
+	for src.Stats().QueueDepth < 10 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	dst := gorker.New(1).Handle("record", func(ctx context.Context, payload []byte) error {
+		mu.Lock()
+		got = append(got, string(payload))
+		mu.Unlock()
+		return nil
+	}).QueueRunner().Start()
+	defer dst.Stop(true)
+
+	n, err := Handoff(context.Background(), src, dial(t, dst))
+	if err != nil || n != 10 {
+		t.Fatalf("Handoff() = %d, %v, want 10, nil", n, err)
+	}
+	for _, ech := range echs {
+		if err := <-ech; !errors.Is(err, gorker.ErrHandedOff) {
+			t.Errorf("handed off task error = %v, want %v", err, gorker.ErrHandedOff)
+		}
+	}
+	dst.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 10 || got[9] != "9" {
+		t.Errorf("accepted tasks = %v", got)
+	}
+}
+
+func TestServer_AcceptHandoff_Invalid(t *testing.T) {
+	d := gorker.New(1).QueueRunner().Start()
+	defer d.Stop(true)
+	stream, err := dial(t, d).AcceptHandoff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&HandoffChunk{Data: []byte("NOTGORKER")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("AcceptHandoff() error = %v, want %v", err, codes.InvalidArgument)
+	}
+}
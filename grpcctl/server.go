@@ -0,0 +1,109 @@
+package grpcctl
+
+import (
+	"context"
+
+	"github.com/kpango/gorker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultListLimit = 100
+
+// Server implements ControlServer on a Dispatcher
+type Server struct {
+	UnimplementedControlServer
+	dis *gorker.Dispatcher
+}
+
+var _ ControlServer = (*Server)(nil)
+
+// NewServer returns a ControlServer managing d
+func NewServer(d *gorker.Dispatcher) *Server {
+	return &Server{dis: d}
+}
+
+func (s *Server) GetStats(context.Context, *GetStatsRequest) (*StatsResponse, error) {
+	return s.stats(), nil
+}
+
+// Scale sets the worker count of the Dispatcher
+func (s *Server) Scale(_ context.Context, req *ScaleRequest) (*StatsResponse, error) {
+	n := int(req.Workers)
+	if n < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "workers must be positive, got %d", n)
+	}
+	if n > s.dis.GetWorkerCount() {
+		s.dis.UpScale(n)
+	} else {
+		s.dis.DownScale(n)
+	}
+	return s.stats(), nil
+}
+
+func (s *Server) Pause(_ context.Context, req *PauseRequest) (*StatsResponse, error) {
+	if req.Resume {
+		s.dis.Resume()
+	} else {
+		s.dis.Pause()
+	}
+	return s.stats(), nil
+}
+
+// Drain stops the intake of the Dispatcher and stops it once every submitted job
+// completed, see Dispatcher.Flush. A paused Dispatcher cannot drain.
+func (s *Server) Drain(ctx context.Context, _ *DrainRequest) (*StatsResponse, error) {
+	if s.dis.Paused() {
+		return nil, status.Error(codes.FailedPrecondition, "dispatcher is paused")
+	}
+	if err := s.dis.Flush(ctx); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return s.stats(), nil
+}
+
+// ListJobs returns up to limit jobs from the head of the queue, 100 when limit is not set
+func (s *Server) ListJobs(_ context.Context, req *ListJobsRequest) (*ListJobsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	infos := s.dis.SampleQueue(limit)
+	res := &ListJobsResponse{
+		Jobs: make([]*JobInfo, 0, len(infos)),
+	}
+	for _, info := range infos {
+		res.Jobs = append(res.Jobs, &JobInfo{
+			Version:    int32(info.Version),
+			Id:         uint64(info.ID),
+			Tags:       info.Tags,
+			EnqueuedAt: timestamppb.New(info.EnqueuedAt),
+			Class:      info.Class,
+			Priority:   int32(info.Priority),
+		})
+	}
+	return res, nil
+}
+
+// Health reports whether the Dispatcher is healthy, an unhealthy Dispatcher is not an error of the call
+func (s *Server) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	if err := s.dis.Healthy(); err != nil {
+		return &HealthResponse{Reason: err.Error()}, nil
+	}
+	return &HealthResponse{Serving: true}, nil
+}
+
+func (s *Server) stats() *StatsResponse {
+	st := s.dis.Stats()
+	return &StatsResponse{
+		Workers:    int32(st.Workers),
+		QueueDepth: int64(st.QueueDepth),
+		Spilled:    int64(st.Spilled),
+		Running:    st.Running,
+		Submitted:  st.Submitted,
+		Succeeded:  st.Succeeded,
+		Failed:     st.Failed,
+		Paused:     s.dis.Paused(),
+	}
+}
@@ -0,0 +1,120 @@
+package grpcctl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kpango/gorker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves d on an in-memory listener and returns a client connected to it
+func dial(t *testing.T, d *gorker.Dispatcher) ControlClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterControlServer(srv, NewServer(d))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewControlClient(conn)
+}
+
+func TestControl(t *testing.T) {
+	d := gorker.New(2).QueueRunner().Start()
+	defer d.Stop(true)
+	c := dial(t, d)
+	ctx := context.Background()
+
+	st, err := c.Scale(ctx, &ScaleRequest{Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Workers != 4 {
+		t.Errorf("workers = %d, want 4", st.Workers)
+	}
+	if _, err := c.Scale(ctx, &ScaleRequest{}); err == nil {
+		t.Error("Scale(0) succeeded")
+	}
+
+	if st, err = c.Pause(ctx, &PauseRequest{}); err != nil || !st.Paused {
+		t.Fatalf("Pause = %v, %v", st, err)
+	}
+	d.Add(func() error { return nil }, gorker.WithTags("a"))
+	d.Add(func() error { return nil })
+	deadline := time.Now().Add(time.Second)
+	var jobs *ListJobsResponse
+	for time.Now().Before(deadline) {
+		if jobs, err = c.ListJobs(ctx, &ListJobsRequest{Limit: 10}); err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs.Jobs) == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(jobs.Jobs) != 2 || jobs.Jobs[0].Tags[0] != "a" || jobs.Jobs[0].EnqueuedAt.AsTime().IsZero() {
+		t.Fatalf("ListJobs = %v, want 2 queued jobs", jobs.Jobs)
+	}
+	if _, err := c.Drain(ctx, &DrainRequest{}); err == nil {
+		t.Error("Drain of a paused dispatcher succeeded")
+	}
+
+	if _, err := c.Pause(ctx, &PauseRequest{Resume: true}); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := c.Health(ctx, &HealthRequest{}); err != nil || !h.Serving {
+		t.Errorf("Health = %v, %v", h, err)
+	}
+	dctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if st, err = c.Drain(dctx, &DrainRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if st.QueueDepth != 0 || st.Succeeded != 2 {
+		t.Errorf("stats after Drain = %v", st)
+	}
+	if st, err = c.GetStats(ctx, &GetStatsRequest{}); err != nil || st.Submitted != 2 {
+		t.Errorf("GetStats = %v, %v", st, err)
+	}
+	if h, err := c.Health(ctx, &HealthRequest{}); err != nil || h.Serving || h.Reason == "" {
+		t.Errorf("Health of a drained dispatcher = %v, %v", h, err)
+	}
+}
+
+func TestServer_Drain_Deadline(t *testing.T) {
+	d := gorker.New(1).QueueRunner().Start()
+	defer d.Kill()
+	c := dial(t, d)
+
+	release := make(chan struct{})
+	defer close(release)
+	d.Add(func() error {
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Drain(ctx, &DrainRequest{}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Drain() error = %v, want %v", err, codes.DeadlineExceeded)
+	}
+	if err := <-d.Add(func() error { return nil }); !errors.Is(err, gorker.ErrStopped) {
+		t.Errorf("job added during Drain error = %v, want %v", err, gorker.ErrStopped)
+	}
+}
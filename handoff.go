@@ -0,0 +1,131 @@
+package gorker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// ErrHandedOff is delivered to tasks which were handed off to another instance
+var ErrHandedOff = errors.New("gorker: task handed off")
+
+// ErrInvalidHandoff is returned by AcceptHandoff for streams not written by Handoff
+var ErrInvalidHandoff = errors.New("gorker: invalid handoff stream")
+
+var handoffMagic = []byte("GORKERH\x01")
+
+// Handoff removes every queued task added by AddTask, including spilled ones,
+// and streams them to w so that a replacement instance can AcceptHandoff them
+// during a rolling deploy. Plain jobs stay queued. Every task is written to w
+// with its own Write call, tasks from the one a Write failed on are requeued and
+// the returned count only covers written tasks.
+func (d *Dispatcher) Handoff(w io.Writer) (int, error) {
+	tasks, lost := d.takeTasks()
+	d.failLost(lost)
+
+	if _, err := w.Write(handoffMagic); err != nil {
+		d.requeue(tasks)
+		return 0, err
+	}
+	var buf []byte
+	for i, j := range tasks {
+		buf = appendTask(buf[:0], j)
+		if _, err := w.Write(buf); err != nil {
+			d.requeue(tasks[i:])
+			d.handedOff(tasks[:i])
+			return i, err
+		}
+	}
+	d.handedOff(tasks)
+	return len(tasks), nil
+}
+
+// AcceptHandoff enqueues the tasks streamed by Handoff on another instance.
+// Accepted tasks get new job IDs but keep their original enqueue time and tags.
+func (d *Dispatcher) AcceptHandoff(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(handoffMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, handoffMagic) {
+		return 0, ErrInvalidHandoff
+	}
+	n := 0
+	for {
+		rec, _, err := readTask(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, fmt.Errorf("%w: %v", ErrInvalidHandoff, err)
+		}
+		d.submit(d.newTask(rec), nil)
+		n++
+	}
+}
+
+// takeTasks removes the queued and spilled tasks, it also returns the spilled tasks which
+// could not be read back
+func (d *Dispatcher) takeTasks() ([]*job, []lostTask) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	tasks := d.takeQueued(func(j *job) bool {
+		return j.task != nil
+	})
+	if d.spill == nil {
+		return tasks, nil
+	}
+	for d.spill.pending > 0 {
+		j, err := d.spill.read(d)
+		if err != nil {
+			d.spill.drop(err)
+			break
+		}
+		tasks = append(tasks, j)
+	}
+	return tasks, d.spill.takeLost()
+}
+
+// requeue puts jobs back at the head of the queue and wakes the queue runner
+func (d *Dispatcher) requeue(jobs []*job) {
+	for _, j := range jobs {
+		atomic.StoreInt32(&j.claimed, 0)
+	}
+	d.mu.Lock()
+	if d.classes.reserving() {
+		for _, j := range jobs {
+			d.classes.free(j)
+		}
+	}
+	d.queue = append(jobs, d.queue...)
+	d.notify()
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) handedOff(jobs []*job) {
+	for _, j := range jobs {
+		d.complete(j, ErrHandedOff)
+	}
+}
+
+// takeQueued removes the queued jobs matched by match and claims them, so that a worker
+// which received one of them from the dispatch buffer concurrently does not run it.
+// d.mu must be held.
+func (d *Dispatcher) takeQueued(match func(*job) bool) []*job {
+	var taken []*job
+	queue := d.queue[:0]
+	for _, j := range d.queue {
+		if match(j) && atomic.CompareAndSwapInt32(&j.claimed, 0, 1) {
+			if d.fairness != nil {
+				d.fairness.removed(j, false)
+			}
+			taken = append(taken, j)
+		} else {
+			queue = append(queue, j)
+		}
+	}
+	clear(d.queue[len(queue):])
+	d.queue = queue
+	return taken
+}
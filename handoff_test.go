@@ -0,0 +1,121 @@
+package gorker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestDispatcher_Handoff(t *testing.T) {
+	src := New(1)
+	echs := make([]chan error, 0, 10)
+	for i := 0; i < 10; i++ {
+		echs = append(echs, src.AddTask("record", []byte(strconv.Itoa(i)), WithTags("type:record")))
+	}
+	// move the submitted tasks from the channel into the queue without running them
+	for i := 0; i < 10; i++ {
+		src.enqueue(<-src.qin)
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := src.Handoff(buf)
+	if err != nil || n != 10 {
+		t.Fatalf("Handoff() = %d, %v, want 10, nil", n, err)
+	}
+	for _, ech := range echs {
+		if err := <-ech; !errors.Is(err, ErrHandedOff) {
+			t.Errorf("handed off task error = %v, want %v", err, ErrHandedOff)
+		}
+	}
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	dst := New(1).Handle("record", func(ctx context.Context, payload []byte) error {
+		mu.Lock()
+		got = append(got, string(payload))
+		mu.Unlock()
+		return nil
+	}).QueueRunner().Start()
+	defer dst.Stop(true)
+
+	n, err = dst.AcceptHandoff(buf)
+	if err != nil || n != 10 {
+		t.Fatalf("AcceptHandoff() = %d, %v, want 10, nil", n, err)
+	}
+	dst.Wait()
+	if len(got) != 10 || got[9] != "9" {
+		t.Errorf("accepted tasks = %v", got)
+	}
+	if s := dst.StatsByTag("type:")["type:record"]; s.Succeeded != 10 {
+		t.Errorf("accepted tasks with tag = %d, want 10", s.Succeeded)
+	}
+}
+
+func TestDispatcher_AcceptHandoff_Invalid(t *testing.T) {
+	d := New(1)
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{name: "empty", in: nil},
+		{name: "bad magic", in: []byte("NOTGORKER")},
+		{name: "truncated", in: append(append([]byte{}, handoffMagic...), 0, 0, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := d.AcceptHandoff(bytes.NewReader(tt.in)); !errors.Is(err, ErrInvalidHandoff) {
+				t.Errorf("AcceptHandoff() error = %v, want %v", err, ErrInvalidHandoff)
+			}
+		})
+	}
+}
+
+// failingWriter fails every Write after the first n
+type failingWriter struct {
+	bytes.Buffer
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("write failed")
+	}
+	w.n--
+	return w.Buffer.Write(p)
+}
+
+func TestDispatcher_Handoff_WriteError(t *testing.T) {
+	src := New(1)
+	echs := make([]chan error, 0, 5)
+	for i := 0; i < 5; i++ {
+		echs = append(echs, src.AddTask("record", []byte(strconv.Itoa(i))))
+	}
+	for i := 0; i < 5; i++ {
+		src.enqueue(<-src.qin)
+	}
+
+	// the magic and two tasks are written
+	w := &failingWriter{n: 3}
+	n, err := src.Handoff(w)
+	if err == nil || n != 2 {
+		t.Fatalf("Handoff() = %d, %v, want 2 and the write error", n, err)
+	}
+	for _, ech := range echs[:2] {
+		if err := <-ech; !errors.Is(err, ErrHandedOff) {
+			t.Errorf("written task error = %v, want %v", err, ErrHandedOff)
+		}
+	}
+	if got := src.Stats().QueueDepth; got != 3 {
+		t.Errorf("queue depth after failed Handoff = %d, want 3", got)
+	}
+
+	dst := New(1).Handle("record", func(context.Context, []byte) error { return nil })
+	if n, err := dst.AcceptHandoff(&w.Buffer); err != nil || n != 2 {
+		t.Errorf("AcceptHandoff() of the written tasks = %d, %v, want 2, nil", n, err)
+	}
+}
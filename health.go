@@ -0,0 +1,116 @@
+package gorker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrUnhealthy is wrapped by the errors returned by Healthy
+var ErrUnhealthy = errors.New("gorker: unhealthy")
+
+var (
+	defaultStallTimeout        = 30 * time.Second
+	defaultMaxQueueUtilization = 0.9
+)
+
+type healthCheck struct {
+	stallTimeout   time.Duration
+	maxUtilization float64
+	// dequeued counts the jobs taken by workers
+	dequeued uint64
+
+	mu sync.Mutex
+	// waiting is when a check first saw queued jobs after the dequeue count mark
+	waiting time.Time
+	mark    uint64
+}
+
+func newHealthCheck() *healthCheck {
+	return &healthCheck{
+		stallTimeout:   defaultStallTimeout,
+		maxUtilization: defaultMaxQueueUtilization,
+	}
+}
+
+// WithHealthThresholds sets when Healthy reports d unhealthy: once queued jobs waited
+// stallTimeout without a worker taking any job, or once the queue is filled beyond
+// maxQueueUtilization. Values below or equal to 0 keep the defaults of 30s and 0.9.
+func WithHealthThresholds(stallTimeout time.Duration, maxQueueUtilization float64) Option {
+	return func(d *Dispatcher) {
+		if stallTimeout > 0 {
+			d.health.stallTimeout = stallTimeout
+		}
+		if maxQueueUtilization > 0 {
+			d.health.maxUtilization = maxQueueUtilization
+		}
+	}
+}
+
+func Healthy() error {
+	return instance.Healthy()
+}
+
+// Healthy returns nil when d is healthy and an error wrapping ErrUnhealthy otherwise.
+// d is unhealthy when it is not started, draining or stopped, when its workers stalled,
+// or when its queue utilization exceeds the threshold set by WithHealthThresholds.
+// The queue utilization is relative to the queue limit, or to the dispatch buffer
+// without one. Workers stall when jobs are queued and none is taken for the stall
+// timeout, which is measured between calls, so Healthy has to be polled to detect it.
+func (d *Dispatcher) Healthy() error {
+	state := d.State()
+	switch state {
+	case StateCreated, StateDraining, StateStopped:
+		return fmt.Errorf("%w: dispatcher is %s", ErrUnhealthy, state)
+	}
+	h := d.health
+	depth := d.queueDepth()
+	d.mu.RLock()
+	capacity := cap(d.qout)
+	d.mu.RUnlock()
+	if l := d.queueLimit.Load(); l != nil {
+		capacity = l.max
+	}
+	if u := float64(depth) / float64(capacity); u > h.maxUtilization {
+		return fmt.Errorf("%w: queue utilization %.2f exceeds %.2f", ErrUnhealthy, u, h.maxUtilization)
+	}
+
+	now := d.clock.Now()
+	dequeued := atomic.LoadUint64(&h.dequeued)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// a paused Dispatcher does not dequeue on purpose
+	if depth == 0 || state == StatePaused || dequeued != h.mark || h.waiting.IsZero() {
+		h.mark = dequeued
+		h.waiting = time.Time{}
+		if depth > 0 && state != StatePaused {
+			h.waiting = now
+		}
+		return nil
+	}
+	if stalled := now.Sub(h.waiting); stalled >= h.stallTimeout {
+		return fmt.Errorf("%w: %d queued jobs and no job dequeued for %s", ErrUnhealthy, depth, stalled)
+	}
+	return nil
+}
+
+func HealthHandler() http.Handler {
+	return instance.HealthHandler()
+}
+
+// HealthHandler returns an http.Handler responding 200 when d is Healthy and 503
+// with the reason otherwise, to be mounted as liveness or readiness probe
+func (d *Dispatcher) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := d.Healthy(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
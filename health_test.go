@@ -0,0 +1,101 @@
+package gorker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Healthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		prepare func(d *Dispatcher, block chan struct{})
+		healthy bool
+	}{
+		{
+			name:    "created",
+			prepare: func(*Dispatcher, chan struct{}) {},
+		},
+		{
+			name:    "running",
+			prepare: func(d *Dispatcher, _ chan struct{}) { d.Start() },
+			healthy: true,
+		},
+		{
+			name: "stopped",
+			prepare: func(d *Dispatcher, _ chan struct{}) {
+				d.Start().Stop(true)
+			},
+		},
+		{
+			name: "paused with queued jobs",
+			opts: []Option{WithHealthThresholds(time.Millisecond, 0)},
+			prepare: func(d *Dispatcher, _ chan struct{}) {
+				d.Start().Pause()
+				d.Add(func() error { return nil })
+			},
+			healthy: true,
+		},
+		{
+			name: "queue utilization",
+			opts: []Option{WithQueueLimit(4, OverflowReject), WithHealthThresholds(0, 0.5)},
+			prepare: func(d *Dispatcher, _ chan struct{}) {
+				d.Start().Pause()
+				for i := 0; i < 3; i++ {
+					d.Add(func() error { return nil })
+				}
+			},
+		},
+		{
+			name: "stalled",
+			opts: []Option{WithHealthThresholds(10*time.Millisecond, 0)},
+			prepare: func(d *Dispatcher, block chan struct{}) {
+				d.Start()
+				for i := 0; i < 2; i++ {
+					d.Add(func() error {
+						<-block
+						return nil
+					})
+				}
+				for deadline := time.Now().Add(time.Second); d.Stats().Running == 0 && time.Now().Before(deadline); {
+					time.Sleep(time.Millisecond)
+				}
+				if err := d.Healthy(); err != nil {
+					t.Fatalf("first check = %v", err)
+				}
+				time.Sleep(20 * time.Millisecond)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1, tt.opts...).QueueRunner()
+			defer d.Stop(true)
+			block := make(chan struct{})
+			defer close(block)
+			tt.prepare(d, block)
+			err := d.Healthy()
+			if tt.healthy != (err == nil) || (err != nil && !errors.Is(err, ErrUnhealthy)) {
+				t.Errorf("Healthy = %v, want healthy %v", err, tt.healthy)
+			}
+		})
+	}
+}
+
+func TestDispatcher_HealthHandler(t *testing.T) {
+	d := New(1).QueueRunner()
+	defer d.Stop(true)
+	h := d.HealthHandler()
+
+	for _, want := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if rec.Code != want {
+			t.Errorf("status = %d, want %d: %s", rec.Code, want, rec.Body)
+		}
+		d.Start()
+	}
+}
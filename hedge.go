@@ -0,0 +1,75 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrHedgeLost is the cancellation cause of hedged attempts which lost against a faster one
+var ErrHedgeLost = errors.New("gorker: hedged attempt lost")
+
+func AddHedged(fn JobFunc, hedgeAfter time.Duration, maxHedges int, opts ...JobOption) chan error {
+	return instance.AddHedged(fn, hedgeAfter, maxHedges, opts...)
+}
+
+// AddHedged runs fn and starts another attempt every hedgeAfter while no attempt has
+// succeeded, up to maxHedges extra attempts. The first success cancels the other attempts
+// with ErrHedgeLost. When every started attempt failed the last error is returned and
+// no further attempt is started. fn must be idempotent, each attempt is a separate job.
+func (d *Dispatcher) AddHedged(fn JobFunc, hedgeAfter time.Duration, maxHedges int, opts ...JobOption) chan error {
+	if maxHedges < 0 {
+		maxHedges = 0
+	}
+	ech := make(chan error, 1)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	results := make(chan error, maxHedges+1)
+	// attempts report from their completion hook, so that rejected attempts count as failed
+	opts = append(opts[:len(opts):len(opts)], onDone(func(err error) {
+		results <- err
+	}))
+	attempt := func() {
+		d.AddJob(func(jctx context.Context) error {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			jctx, jcancel := context.WithCancelCause(jctx)
+			defer jcancel(nil)
+			stop := context.AfterFunc(ctx, func() {
+				jcancel(context.Cause(ctx))
+			})
+			defer stop()
+			return fn(jctx)
+		}, opts...)
+	}
+
+	attempt()
+	go func() {
+		defer cancel(nil)
+		timer := d.clock.NewTimer(hedgeAfter)
+		defer timer.Stop()
+		launched, failed := 1, 0
+		for {
+			select {
+			case <-timer.C():
+				if launched <= maxHedges {
+					attempt()
+					launched++
+					timer.Reset(hedgeAfter)
+				}
+			case err := <-results:
+				if err == nil {
+					cancel(ErrHedgeLost)
+					ech <- nil
+					return
+				}
+				failed++
+				if failed == launched {
+					ech <- err
+					return
+				}
+			}
+		}
+	}()
+	return ech
+}
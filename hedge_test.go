@@ -0,0 +1,83 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_AddHedged(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxHedges int
+		fn        func(attempt int32, ctx context.Context) error
+		want      error
+		attempts  int32
+	}{
+		{
+			name:      "first attempt wins",
+			maxHedges: 2,
+			fn: func(int32, context.Context) error {
+				return nil
+			},
+			attempts: 1,
+		},
+		{
+			name:      "hedge wins over slow attempt",
+			maxHedges: 2,
+			fn: func(attempt int32, ctx context.Context) error {
+				if attempt == 1 {
+					<-ctx.Done()
+					if !errors.Is(context.Cause(ctx), ErrHedgeLost) {
+						return errors.New("slow attempt not canceled as lost")
+					}
+					return ctx.Err()
+				}
+				return nil
+			},
+			attempts: 2,
+		},
+		{
+			name:      "all attempts fail",
+			maxHedges: 1,
+			fn: func(int32, context.Context) error {
+				time.Sleep(30 * time.Millisecond)
+				return errors.New("fail")
+			},
+			want:     errors.New("fail"),
+			attempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(4).QueueRunner().Start()
+			defer d.Stop(true)
+			var attempts int32
+			err := <-d.AddHedged(func(ctx context.Context) error {
+				return tt.fn(atomic.AddInt32(&attempts, 1), ctx)
+			}, 10*time.Millisecond, tt.maxHedges)
+			if (err == nil) != (tt.want == nil) || (err != nil && err.Error() != tt.want.Error()) {
+				t.Errorf("AddHedged() = %v, want %v", err, tt.want)
+			}
+			d.Wait()
+			if got := atomic.LoadInt32(&attempts); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestDispatcher_AddHedged_Rejected(t *testing.T) {
+	d := New(1).Start().Kill()
+
+	select {
+	case err := <-d.AddHedged(func(context.Context) error { return nil }, time.Millisecond, 2):
+		if !errors.Is(err, ErrStopped) {
+			t.Errorf("AddHedged() = %v, want %v", err, ErrStopped)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("AddHedged on a stopped Dispatcher did not complete")
+	}
+}
@@ -0,0 +1,301 @@
+package gorker
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kpango/glg"
+)
+
+// HistorySink receives the record of every completed job of a Dispatcher, see WithHistory.
+// Append is called synchronously when a job completed and must not block.
+type HistorySink interface {
+	Append(rec JobRecord) error
+}
+
+// HistoryQuerier is implemented by sinks which History can query
+type HistoryQuerier interface {
+	Query(f HistoryFilter) []JobRecord
+}
+
+// HistoryFunc is a HistorySink calling a function for every record
+type HistoryFunc func(rec JobRecord) error
+
+func (fn HistoryFunc) Append(rec JobRecord) error {
+	return fn(rec)
+}
+
+// HistoryFilter selects records from a history, zero fields match every record
+type HistoryFilter struct {
+	// Tag matches records of jobs having this tag
+	Tag     string
+	Outcome Outcome
+	// Since and Until bound the time the jobs finished
+	Since time.Time
+	Until time.Time
+	// Limit keeps only the newest Limit matching records
+	Limit int
+}
+
+func (f HistoryFilter) match(rec JobRecord) bool {
+	if f.Outcome != "" && rec.Outcome != f.Outcome {
+		return false
+	}
+	if !f.Since.IsZero() && rec.FinishedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && rec.FinishedAt.After(f.Until) {
+		return false
+	}
+	if f.Tag == "" {
+		return true
+	}
+	for _, tag := range rec.Job.Tags {
+		if tag == f.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the records of recs matched by f, recs are ordered from the oldest
+func (f HistoryFilter) filter(recs []JobRecord) []JobRecord {
+	var res []JobRecord
+	for _, rec := range recs {
+		if f.match(rec) {
+			res = append(res, rec)
+		}
+	}
+	if f.Limit > 0 && len(res) > f.Limit {
+		res = res[len(res)-f.Limit:]
+	}
+	return res
+}
+
+// retention bounds a history to max records which finished within age, zero values disable a bound
+type retention struct {
+	max int
+	age time.Duration
+}
+
+// trim drops the records of recs beyond the retention at now
+func (r retention) trim(recs []JobRecord, now time.Time) []JobRecord {
+	if r.max > 0 && len(recs) > r.max {
+		recs = recs[len(recs)-r.max:]
+	}
+	if r.age > 0 {
+		i := 0
+		for i < len(recs) && now.Sub(recs[i].FinishedAt) > r.age {
+			i++
+		}
+		recs = recs[i:]
+	}
+	return recs
+}
+
+// RingHistory keeps the newest records in memory
+type RingHistory struct {
+	mu   sync.Mutex
+	ret  retention
+	recs []JobRecord
+	now  func() time.Time
+}
+
+// NewRingHistory returns a RingHistory keeping up to maxRecords records which finished
+// within maxAge, a maxAge of 0 keeps records regardless of their age. maxRecords is raised
+// to 1 when lower.
+func NewRingHistory(maxRecords int, maxAge time.Duration) *RingHistory {
+	if maxRecords < 1 {
+		maxRecords = 1
+	}
+	return &RingHistory{
+		ret:  retention{max: maxRecords, age: maxAge},
+		recs: make([]JobRecord, 0, maxRecords),
+		now:  time.Now,
+	}
+}
+
+func (h *RingHistory) Append(rec JobRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.recs) == h.ret.max {
+		// shift in place so that the ring never reallocates
+		copy(h.recs, h.recs[1:])
+		h.recs = h.recs[:len(h.recs)-1]
+	}
+	h.recs = append(h.recs, rec)
+	return nil
+}
+
+func (h *RingHistory) Query(f HistoryFilter) []JobRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return f.filter(h.ret.trim(h.recs, h.now()))
+}
+
+// FileHistory appends records as JSON lines to a file. The file is compacted to the
+// retention once it holds twice as many records.
+type FileHistory struct {
+	mu    sync.Mutex
+	path  string
+	ret   retention
+	file  *os.File
+	lines int
+	now   func() time.Time
+}
+
+// NewFileHistory opens or creates the history file at path keeping up to maxRecords
+// records which finished within maxAge, zero values disable a bound
+func NewFileHistory(path string, maxRecords int, maxAge time.Duration) (*FileHistory, error) {
+	h := &FileHistory{
+		path: path,
+		ret:  retention{max: maxRecords, age: maxAge},
+		now:  time.Now,
+	}
+	recs, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	h.lines = len(recs)
+	if h.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *FileHistory) Append(rec JobRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return os.ErrClosed
+	}
+	if _, err := h.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	h.lines++
+	if h.ret.max > 0 && h.lines >= 2*h.ret.max {
+		return h.compact()
+	}
+	return nil
+}
+
+func (h *FileHistory) Query(f HistoryFilter) []JobRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recs, err := h.read()
+	if err != nil {
+		glg.Errorf("gorker: failed to read history %s: %v", h.path, err)
+	}
+	return f.filter(h.ret.trim(recs, h.now()))
+}
+
+// Close closes the history file, records appended afterwards fail with os.ErrClosed
+func (h *FileHistory) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
+
+func (h *FileHistory) read() ([]JobRecord, error) {
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var recs []JobRecord
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		var rec JobRecord
+		// a line torn by a crash is skipped
+		if json.Unmarshal(s.Bytes(), &rec) == nil {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, s.Err()
+}
+
+// compact rewrites the file with the records within the retention
+func (h *FileHistory) compact() error {
+	recs, err := h.read()
+	if err != nil {
+		return err
+	}
+	recs = h.ret.trim(recs, h.now())
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, rec := range recs {
+		if err = enc.Encode(rec); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), h.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	h.file.Close()
+	h.lines = len(recs)
+	h.file, err = os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	return err
+}
+
+// WithHistory appends the record of every completed job, including dropped ones, to sinks.
+// History queries the first of them implementing HistoryQuerier.
+func WithHistory(sinks ...HistorySink) Option {
+	return func(d *Dispatcher) {
+		d.history = append(d.history, sinks...)
+	}
+}
+
+func History(f HistoryFilter) []JobRecord {
+	return instance.History(f)
+}
+
+// History returns the records matched by f from the oldest, it returns nil when no
+// sink of d can be queried
+func (d *Dispatcher) History(f HistoryFilter) []JobRecord {
+	for _, sink := range d.history {
+		if q, ok := sink.(HistoryQuerier); ok {
+			return q.Query(f)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) appendHistory(j *job, finished time.Time, err error) {
+	rec := j.record(finished, err)
+	for _, sink := range d.history {
+		if err := sink.Append(rec); err != nil {
+			glg.Warnf("gorker: failed to append job %d to history: %v", j.id, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gorker
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryFilter(t *testing.T) {
+	now := time.Now()
+	recs := []JobRecord{
+		{Job: JobInfo{ID: 1, Tags: []string{"a"}}, FinishedAt: now.Add(-3 * time.Minute), Outcome: OutcomeSucceeded},
+		{Job: JobInfo{ID: 2, Tags: []string{"b"}}, FinishedAt: now.Add(-2 * time.Minute), Outcome: OutcomeFailed},
+		{Job: JobInfo{ID: 3, Tags: []string{"a", "b"}}, FinishedAt: now.Add(-time.Minute), Outcome: OutcomeSucceeded},
+	}
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []JobID
+	}{
+		{name: "all", want: []JobID{1, 2, 3}},
+		{name: "tag", filter: HistoryFilter{Tag: "a"}, want: []JobID{1, 3}},
+		{name: "outcome", filter: HistoryFilter{Outcome: OutcomeFailed}, want: []JobID{2}},
+		{name: "since", filter: HistoryFilter{Since: now.Add(-90 * time.Second)}, want: []JobID{3}},
+		{name: "until", filter: HistoryFilter{Until: now.Add(-90 * time.Second)}, want: []JobID{1, 2}},
+		{name: "limit keeps the newest", filter: HistoryFilter{Tag: "b", Limit: 1}, want: []JobID{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.filter(recs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %v", len(got), tt.want)
+			}
+			for i, rec := range got {
+				if rec.Job.ID != tt.want[i] {
+					t.Errorf("record %d = %d, want %d", i, rec.Job.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWithHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	file, err := NewFileHistory(path, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	ring := NewRingHistory(3, time.Hour)
+	var called int
+	d := New(1, WithHistory(ring, file, HistoryFunc(func(JobRecord) error {
+		called++
+		return nil
+	}))).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("fail")
+	for i := 0; i < 5; i++ {
+		var err error
+		if i%2 == 1 {
+			err = fail
+		}
+		<-d.Add(func() error { return err }, WithTags("history"))
+	}
+	d.Wait()
+	if called != 5 {
+		t.Errorf("callback called %d times, want 5", called)
+	}
+	got := d.History(HistoryFilter{})
+	if len(got) != 3 || got[2].Job.ID != 5 {
+		t.Errorf("ring history = %+v, want the last 3 jobs", got)
+	}
+	if got := d.History(HistoryFilter{Outcome: OutcomeFailed}); len(got) != 1 || got[0].Error != "fail" {
+		t.Errorf("failed ring history = %+v", got)
+	}
+	if got := file.Query(HistoryFilter{}); len(got) != 2 || got[0].Job.ID != 4 {
+		t.Errorf("file history = %+v", got)
+	}
+
+	reopened, err := NewFileHistory(path, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if got := reopened.Query(HistoryFilter{Tag: "history", Limit: 1}); len(got) != 1 || got[0].Job.ID != 5 {
+		t.Errorf("reopened file history = %+v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package gorker
+
+import "sync/atomic"
+
+type inlineRunner struct {
+	max     int64
+	running int64
+}
+
+// WithInlineThreshold runs submitted jobs on the submitting goroutine while the Dispatcher
+// is running, its queue is empty and fewer jobs than workers are in flight, skipping the
+// queue hops. At most n jobs run inline at the same time, which also bounds the nesting
+// of jobs submitting jobs inline. A threshold below 1 disables inline execution.
+func WithInlineThreshold(n int) Option {
+	return func(d *Dispatcher) {
+		if n < 1 {
+			d.inline = nil
+			return
+		}
+		d.inline = &inlineRunner{
+			max: int64(n),
+		}
+	}
+}
+
+// acquire reserves an inline slot
+func (r *inlineRunner) acquire() bool {
+	for {
+		n := atomic.LoadInt64(&r.running)
+		if n >= r.max {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&r.running, n, n+1) {
+			return true
+		}
+	}
+}
+
+func (r *inlineRunner) release() {
+	atomic.AddInt64(&r.running, -1)
+}
+
+// runInline runs j on the caller and reports whether it did, the checks race with
+// concurrent submitters so the worker count may be exceeded briefly
+func (d *Dispatcher) runInline(j *job) bool {
+	if j.pinned || j.reserved || d.classes.reserving() || d.State() != StateRunning || !d.inline.acquire() {
+		return false
+	}
+	defer d.inline.release()
+	d.mu.RLock()
+	workers := d.workerCount
+	d.mu.RUnlock()
+	if atomic.LoadInt64(&d.inflight) >= int64(workers) || d.queueDepth() > 0 {
+		return false
+	}
+	d.runJob(j, nil)
+	return true
+}
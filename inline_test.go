@@ -0,0 +1,80 @@
+package gorker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithInlineThreshold(t *testing.T) {
+	d := New(4, WithInlineThreshold(2)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ran := func(ech chan error) bool {
+		select {
+		case err := <-ech:
+			if err != nil {
+				t.Fatal(err)
+			}
+			return true
+		default:
+			return false
+		}
+	}
+	if !ran(d.Add(func() error { return nil })) {
+		t.Fatal("job on an idle pool did not run inline")
+	}
+
+	// nesting is bounded by the threshold
+	var nested [2]bool
+	ran(d.Add(func() error {
+		nested[0] = ran(d.Add(func() error {
+			ech := d.Add(func() error { return nil })
+			nested[1] = ran(ech)
+			<-ech
+			return nil
+		}))
+		return nil
+	}))
+	if !nested[0] || nested[1] {
+		t.Errorf("nested inline = %v, want [true false]", nested)
+	}
+
+	// a busy pool queues jobs
+	block := make(chan struct{})
+	started := make(chan struct{}, 4)
+	for i := 0; i < 4; i++ {
+		go d.Add(func() error {
+			started <- struct{}{}
+			<-block
+			return nil
+		})
+	}
+	for i := 0; i < 4; i++ {
+		<-started
+	}
+	ech := d.Add(func() error { return nil })
+	if ran(ech) {
+		t.Error("job ran inline on a busy pool")
+	}
+	close(block)
+	select {
+	case <-ech:
+	case <-time.After(time.Second):
+		t.Fatal("queued job did not run")
+	}
+}
+
+func TestWithInlineThreshold_Stopped(t *testing.T) {
+	d := New(1, WithInlineThreshold(1))
+	ech := d.Add(func() error { return nil })
+	select {
+	case <-ech:
+		t.Fatal("job ran inline before Start")
+	default:
+	}
+	d.QueueRunner().Start()
+	defer d.Stop(true)
+	if err := <-ech; err != nil {
+		t.Fatal(err)
+	}
+}
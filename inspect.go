@@ -0,0 +1,67 @@
+package gorker
+
+import "iter"
+
+// SampleQueue returns the JobInfo of at most n jobs at the head of the queue.
+// Only the sampled entries are copied while the queue lock is held,
+// so sampling a large queue does not stall dispatching.
+func (d *Dispatcher) SampleQueue(n int) []JobInfo {
+	if n < 1 {
+		return nil
+	}
+	d.mu.RLock()
+	if n > len(d.queue) {
+		n = len(d.queue)
+	}
+	sample := make([]*job, n)
+	copy(sample, d.queue[:n])
+	d.mu.RUnlock()
+
+	infos := make([]JobInfo, 0, n)
+	for _, j := range sample {
+		if j != nil {
+			infos = append(infos, j.info())
+		}
+	}
+	return infos
+}
+
+func ForEachQueued(fn func(info JobInfo) bool) {
+	instance.ForEachQueued(fn)
+}
+
+// ForEachQueued calls fn for every job waiting in the queue and the worker inboxes, in
+// dispatch order for the queue, until fn returns false. The jobs are described while the
+// queue lock is held and fn runs after it was released, so fn sees a consistent snapshot
+// and may take as long as it needs. Jobs already handed to the dispatch buffer or spilled
+// to disk are not visited.
+func (d *Dispatcher) ForEachQueued(fn func(info JobInfo) bool) {
+	d.mu.RLock()
+	infos := make([]JobInfo, 0, len(d.queue)+d.pinnedDepth())
+	for _, j := range d.queue {
+		infos = append(infos, j.info())
+	}
+	for _, w := range d.workers {
+		for _, j := range w.inbox {
+			infos = append(infos, j.info())
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, info := range infos {
+		if !fn(info) {
+			return
+		}
+	}
+}
+
+func Queued() iter.Seq[JobInfo] {
+	return instance.Queued()
+}
+
+// Queued iterates over the jobs waiting in the queue and the worker inboxes like ForEachQueued
+func (d *Dispatcher) Queued() iter.Seq[JobInfo] {
+	return func(yield func(JobInfo) bool) {
+		d.ForEachQueued(yield)
+	}
+}
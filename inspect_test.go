@@ -0,0 +1,102 @@
+package gorker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDispatcher_SampleQueue(t *testing.T) {
+	d := New(1)
+	now := time.Now()
+	for i := 1; i <= 10; i++ {
+		d.queue = append(d.queue, &job{id: JobID(i), enqueued: now})
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "negative", n: -1, want: 0},
+		{name: "leading", n: 3, want: 3},
+		{name: "larger than queue", n: 100, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.SampleQueue(tt.n)
+			if len(got) != tt.want {
+				t.Fatalf("len(SampleQueue(%d)) = %d, want %d", tt.n, len(got), tt.want)
+			}
+			for i, info := range got {
+				if info.ID != JobID(i+1) {
+					t.Errorf("got[%d].ID = %d, want %d", i, info.ID, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_ForEachQueued(t *testing.T) {
+	d := New(2, WithPriorityClasses("high", "low")).QueueRunner().Start().Pause()
+	defer d.Stop(true)
+
+	d.Add(func() error { return nil }, WithClass("low"), WithTags("a"))
+	d.Add(func() error { return nil }, WithClass("high"), WithTags("b"))
+	d.AddToWorker(1, func() error { return nil }, WithTags("pinned"))
+	deadline := time.Now().Add(time.Second)
+	for len(d.SampleQueue(2)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{name: "all", limit: 10, want: []string{"b", "a", "pinned"}},
+		{name: "stopped early", limit: 1, want: []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []JobInfo
+			d.ForEachQueued(func(info JobInfo) bool {
+				got = append(got, info)
+				return len(got) < tt.limit
+			})
+			if len(got) != len(tt.want) {
+				t.Fatalf("visited %d jobs, want %d", len(got), len(tt.want))
+			}
+			for i, info := range got {
+				if info.Tags[0] != tt.want[i] {
+					t.Errorf("job %d tags = %v, want %s", i, info.Tags, tt.want[i])
+				}
+			}
+			if got[0].Class != "high" || got[0].Priority != 0 {
+				t.Errorf("first job class = %q priority = %d", got[0].Class, got[0].Priority)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Queued(t *testing.T) {
+	d := New(1).QueueRunner().Start().Pause()
+	defer d.Stop(true)
+
+	for _, tag := range []string{"a", "b", "c"} {
+		d.Add(func() error { return nil }, WithTags(tag))
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(d.SampleQueue(3)) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	var got []string
+	for info := range d.Queued() {
+		got = append(got, info.Tags[0])
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("queued = %v, want [a b]", got)
+	}
+}
@@ -0,0 +1,155 @@
+package gorker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// JobFunc is a job which observes cancellation through its context
+type JobFunc func(ctx context.Context) error
+
+// JobID identifies a job submitted to a Dispatcher
+type JobID uint64
+
+// Outcome describes how a job execution ended
+type Outcome string
+
+const (
+	// JobInfoVersion is the schema version of JobInfo and JobRecord JSON encoding
+	JobInfoVersion = 1
+
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+)
+
+// JobInfo is the public description of a submitted job
+type JobInfo struct {
+	Version    int       `json:"version"`
+	ID         JobID     `json:"id"`
+	Tags       []string  `json:"tags,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	// Class and Priority are set for Dispatchers with priority classes, a lower Priority runs first
+	Class    string `json:"class,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// JobRecord is the public description of a finished job
+type JobRecord struct {
+	Version    int           `json:"version"`
+	Job        JobInfo       `json:"job"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration_ns"`
+	Outcome    Outcome       `json:"outcome"`
+	Error      string        `json:"error,omitempty"`
+}
+
+type job struct {
+	id       JobID
+	fn       JobFunc
+	ech      chan error
+	task     *task
+	tags     []string
+	enqueued time.Time
+	started  time.Time
+	tenant   string
+	admitted bool
+	slotted  bool
+	class    string
+	prio     int
+	// run is set instead of fn for jobs added by Go
+	run     func()
+	retry   *retryPolicy
+	attempt int
+	// attempts are the failed attempts of a retried job, see AttemptFrom
+	attempts []Attempt
+	deadline time.Time
+	// done is called with the result of the job when it completed, before the result is sent
+	done func(err error)
+	// pinned jobs run on the worker at index worker, see AddToWorker
+	pinned bool
+	worker int
+	// reserved jobs were submitted through a Slot
+	reserved bool
+	// submitCtx and values carry the context a job was submitted with, see WithContextPropagation
+	submitCtx context.Context
+	values    []any
+	// claimed is set by the first of a worker running the job and takeQueued removing it
+	claimed int32
+	// group is the JobGroup the job is a member of
+	group *JobGroup
+	// identity is the submitter identity of a queued job, see WithFairness
+	identity string
+	// forwarded is set for jobs rerouted by ForwardTo
+	forwarded bool
+	// due is the deadline of the job, see WithDeadline
+	due time.Time
+	// token is set by Suspend, resumed by ResumeJob
+	token   Token
+	resumed *resumption
+	// holding is set while j holds a worker of a class with reserved workers, see WithClassWorkers
+	holding bool
+}
+
+// onDone chains fn to the completion hook of the job, which also runs for jobs rejected
+// or expired without running
+func onDone(fn func(err error)) JobOption {
+	return func(j *job) {
+		done := j.done
+		j.done = func(err error) {
+			if done != nil {
+				done(err)
+			}
+			fn(err)
+		}
+	}
+}
+
+func (j *job) info() JobInfo {
+	return JobInfo{
+		Version:    JobInfoVersion,
+		ID:         j.id,
+		Tags:       j.tags,
+		EnqueuedAt: j.enqueued,
+		Class:      j.class,
+		Priority:   j.prio,
+	}
+}
+
+// record describes j finished at finished, jobs which never started have a zero StartedAt
+func (j *job) record(finished time.Time, err error) JobRecord {
+	r := JobRecord{
+		Version:    JobInfoVersion,
+		Job:        j.info(),
+		StartedAt:  j.started,
+		FinishedAt: finished,
+		Outcome:    OutcomeSucceeded,
+	}
+	if !j.started.IsZero() {
+		r.Duration = finished.Sub(j.started)
+	}
+	if err != nil {
+		r.Outcome = OutcomeFailed
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// MarshalJSON always encodes the current schema version
+func (i JobInfo) MarshalJSON() ([]byte, error) {
+	type alias JobInfo
+	if i.Version == 0 {
+		i.Version = JobInfoVersion
+	}
+	return json.Marshal(alias(i))
+}
+
+// MarshalJSON always encodes the current schema version
+func (r JobRecord) MarshalJSON() ([]byte, error) {
+	type alias JobRecord
+	if r.Version == 0 {
+		r.Version = JobInfoVersion
+	}
+	return json.Marshal(alias(r))
+}
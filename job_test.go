@@ -0,0 +1,46 @@
+package gorker
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobInfo_MarshalJSON(t *testing.T) {
+	now := time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "info without version",
+			v:    JobInfo{ID: 1, EnqueuedAt: now},
+			want: `{"version":1,"id":1,"enqueued_at":"2017-07-01T00:00:00Z"}`,
+		},
+		{
+			name: "record",
+			v: JobRecord{
+				Job:        (&job{id: 2, enqueued: now}).info(),
+				StartedAt:  now,
+				FinishedAt: now.Add(time.Second),
+				Duration:   time.Second,
+				Outcome:    OutcomeFailed,
+				Error:      errors.New("fail").Error(),
+			},
+			want: `{"version":1,"job":{"version":1,"id":2,"enqueued_at":"2017-07-01T00:00:00Z"},"started_at":"2017-07-01T00:00:00Z","finished_at":"2017-07-01T00:00:01Z","duration_ns":1000000000,"outcome":"failed","error":"fail"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Package kafka dispatches the records of a Kafka consumer group to a gorker Dispatcher.
+//
+// Records of a partition are handled in offset order, or in order per key with
+// WithKeyOrdering, and offsets are committed only once every record before them was
+// handled successfully, which gives at least once processing. The package does not
+// depend on a Kafka client, Consumer is implemented on top of the client in use.
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kpango/gorker"
+)
+
+const defaultMaxInFlight = 1000
+
+// Record is a message read from a partition
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+// Offset is the next offset to consume from a partition
+type Offset struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// Consumer is a member of a consumer group
+type Consumer interface {
+	// Fetch blocks until records of the assigned partitions are available,
+	// records of a partition are returned in offset order
+	Fetch(ctx context.Context) ([]Record, error)
+	// Commit stores the next offsets to consume for the group
+	Commit(ctx context.Context, offsets []Offset) error
+}
+
+// Handler processes a record, an error stops the Processor without committing the record
+type Handler func(ctx context.Context, r Record) error
+
+// RecordError is returned by Run when a Handler failed
+type RecordError struct {
+	Record Record
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("kafka: handling %s/%d@%d: %v", e.Record.Topic, e.Record.Partition, e.Record.Offset, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Processor
+type Option func(*Processor)
+
+// WithKeyOrdering serializes records by partition and key instead of by partition,
+// records with different keys of the same partition are handled concurrently
+func WithKeyOrdering() Option {
+	return func(p *Processor) {
+		p.byKey = true
+	}
+}
+
+// WithMaxInFlight bounds how many fetched records are queued or running, the default is 1000
+func WithMaxInFlight(n int) Option {
+	return func(p *Processor) {
+		if n > 0 {
+			p.maxInFlight = n
+		}
+	}
+}
+
+// WithJobOptions applies opts to the job of every record
+func WithJobOptions(opts ...gorker.JobOption) Option {
+	return func(p *Processor) {
+		p.jobOpts = append(p.jobOpts, opts...)
+	}
+}
+
+// Processor consumes records from a Consumer and handles them on a Dispatcher
+type Processor struct {
+	dis         *gorker.Dispatcher
+	consumer    Consumer
+	handler     Handler
+	byKey       bool
+	maxInFlight int
+	jobOpts     []gorker.JobOption
+}
+
+type partition struct {
+	topic string
+	id    int32
+}
+
+type laneKey struct {
+	partition
+	key string
+}
+
+// lane holds the records which must be handled one after the other
+type lane struct {
+	records []Record
+	busy    bool
+}
+
+// watermark tracks the handled offsets of a partition
+type watermark struct {
+	pending []int64
+	done    map[int64]bool
+	next    int64
+}
+
+type result struct {
+	record Record
+	err    error
+}
+
+// New returns a Processor handling the records of c with h on d
+func New(d *gorker.Dispatcher, c Consumer, h Handler, opts ...Option) *Processor {
+	p := &Processor{
+		dis:         d,
+		consumer:    c,
+		handler:     h,
+		maxInFlight: defaultMaxInFlight,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run consumes records until ctx is canceled, Fetch or Commit fail or a Handler returns
+// an error. Running records are awaited and the offsets they completed are committed
+// before Run returns.
+func (p *Processor) Run(ctx context.Context) error {
+	fctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	slots := make(chan struct{}, p.maxInFlight)
+	records := make(chan Record)
+	fetchErr := make(chan error, 1)
+	go p.fetch(fctx, slots, records, fetchErr)
+
+	results := make(chan result, p.maxInFlight)
+	lanes := make(map[laneKey]*lane)
+	marks := make(map[partition]*watermark)
+	running := 0
+	var err error
+
+	dispatch := func(key laneKey, l *lane) {
+		if l.busy || len(l.records) == 0 {
+			return
+		}
+		r := l.records[0]
+		l.records = l.records[1:]
+		l.busy = true
+		running++
+		ech := p.dis.AddJob(func(ctx context.Context) error {
+			return p.handler(ctx, r)
+		}, p.jobOpts...)
+		go func() {
+			results <- result{record: r, err: <-ech}
+		}()
+	}
+
+	for err == nil || running > 0 {
+		var in chan Record
+		if err == nil {
+			in = records
+		}
+		select {
+		case r := <-in:
+			pt := partition{topic: r.Topic, id: r.Partition}
+			m, ok := marks[pt]
+			if !ok {
+				m = &watermark{done: make(map[int64]bool), next: -1}
+				marks[pt] = m
+			}
+			m.pending = append(m.pending, r.Offset)
+			key := laneKey{partition: pt}
+			if p.byKey {
+				key.key = string(r.Key)
+			}
+			l, ok := lanes[key]
+			if !ok {
+				l = new(lane)
+				lanes[key] = l
+			}
+			l.records = append(l.records, r)
+			dispatch(key, l)
+		case res := <-results:
+			running--
+			<-slots
+			pt := partition{topic: res.record.Topic, id: res.record.Partition}
+			key := laneKey{partition: pt}
+			if p.byKey {
+				key.key = string(res.record.Key)
+			}
+			l := lanes[key]
+			l.busy = false
+			if res.err != nil {
+				if err == nil {
+					err = &RecordError{Record: res.record, Err: res.err}
+					stop()
+				}
+				continue
+			}
+			marks[pt].complete(res.record.Offset)
+			if err == nil {
+				dispatch(key, l)
+			}
+			if len(l.records) == 0 && !l.busy {
+				delete(lanes, key)
+			}
+			if cerr := p.commit(ctx, marks); cerr != nil && err == nil {
+				err = cerr
+				stop()
+			}
+		case ferr := <-fetchErr:
+			if err == nil {
+				err = ferr
+				stop()
+			}
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+				stop()
+			}
+		}
+	}
+	if cerr := p.commit(context.WithoutCancel(ctx), marks); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// fetch reads records and forwards them one by one once a slot is free
+func (p *Processor) fetch(ctx context.Context, slots chan struct{}, records chan<- Record, errs chan<- error) {
+	for {
+		batch, err := p.consumer.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				errs <- err
+			}
+			return
+		}
+		for _, r := range batch {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case records <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// commit stores the offsets which advanced since the last commit
+func (p *Processor) commit(ctx context.Context, marks map[partition]*watermark) error {
+	var offsets []Offset
+	for pt, m := range marks {
+		if m.next < 0 {
+			continue
+		}
+		offsets = append(offsets, Offset{Topic: pt.topic, Partition: pt.id, Offset: m.next})
+		m.next = -1
+	}
+	if len(offsets) == 0 {
+		return nil
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+	return p.consumer.Commit(ctx, offsets)
+}
+
+// complete marks offset handled and advances the offset to commit past every
+// handled offset without a pending one before it
+func (m *watermark) complete(offset int64) {
+	m.done[offset] = true
+	for len(m.pending) > 0 && m.done[m.pending[0]] {
+		delete(m.done, m.pending[0])
+		m.next = m.pending[0] + 1
+		m.pending = m.pending[1:]
+	}
+}
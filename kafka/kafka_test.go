@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+// fakeConsumer returns its batches once and then blocks until canceled
+type fakeConsumer struct {
+	mu        sync.Mutex
+	batches   [][]Record
+	committed map[int32]int64
+}
+
+func (c *fakeConsumer) Fetch(ctx context.Context) ([]Record, error) {
+	c.mu.Lock()
+	if len(c.batches) > 0 {
+		b := c.batches[0]
+		c.batches = c.batches[1:]
+		c.mu.Unlock()
+		return b, nil
+	}
+	c.mu.Unlock()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *fakeConsumer) Commit(_ context.Context, offsets []Offset) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, o := range offsets {
+		if o.Offset <= c.committed[o.Partition] {
+			return errors.New("offset moved backwards")
+		}
+		c.committed[o.Partition] = o.Offset
+	}
+	return nil
+}
+
+func records(partition int32, keys ...string) []Record {
+	rs := make([]Record, len(keys))
+	for i, k := range keys {
+		rs[i] = Record{Topic: "t", Partition: partition, Offset: int64(i), Key: []byte(k)}
+	}
+	return rs
+}
+
+func TestProcessor_Run(t *testing.T) {
+	fail := errors.New("fail")
+	tests := []struct {
+		name    string
+		batches [][]Record
+		opts    []Option
+		failAt  map[int32]int64
+		want    map[int32]int64
+		wantErr error
+	}{
+		{
+			name:    "commits handled partitions",
+			batches: [][]Record{records(0, "a", "b", "c"), records(1, "a", "b")},
+			want:    map[int32]int64{0: 3, 1: 2},
+		},
+		{
+			name:    "key ordering",
+			batches: [][]Record{records(0, "a", "b", "a", "b")},
+			opts:    []Option{WithKeyOrdering(), WithMaxInFlight(2)},
+			want:    map[int32]int64{0: 4},
+		},
+		{
+			name:    "failure stops before the failed offset",
+			batches: [][]Record{records(0, "a", "b", "c", "d")},
+			failAt:  map[int32]int64{0: 2},
+			want:    map[int32]int64{0: 2},
+			wantErr: fail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := gorker.New(4).QueueRunner().Start()
+			defer d.Stop(true)
+			c := &fakeConsumer{batches: tt.batches, committed: make(map[int32]int64)}
+
+			var mu sync.Mutex
+			last := make(map[string]int64)
+			handled := 0
+			total := 0
+			for _, b := range tt.batches {
+				total += len(b)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			h := func(_ context.Context, r Record) error {
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				key := string(rune('0'+r.Partition)) + string(r.Key)
+				if prev, ok := last[key]; ok && prev > r.Offset {
+					t.Errorf("record %s@%d handled after %d", key, r.Offset, prev)
+				}
+				last[key] = r.Offset
+				if off, ok := tt.failAt[r.Partition]; ok && off == r.Offset {
+					return fail
+				}
+				if handled++; handled == total {
+					cancel()
+				}
+				return nil
+			}
+			err := New(d, c, h, tt.opts...).Run(ctx)
+			if tt.wantErr != nil {
+				var rerr *RecordError
+				if !errors.As(err, &rerr) || !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want RecordError of %v", err, tt.wantErr)
+				}
+			} else if !errors.Is(err, context.Canceled) {
+				t.Fatalf("error = %v, want context.Canceled", err)
+			}
+			if !reflect.DeepEqual(c.committed, tt.want) {
+				t.Errorf("committed = %v, want %v", c.committed, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatermark_complete(t *testing.T) {
+	m := &watermark{pending: []int64{3, 4, 5}, done: make(map[int64]bool), next: -1}
+	m.complete(4)
+	if m.next != -1 {
+		t.Fatalf("next = %d before the lowest offset was handled", m.next)
+	}
+	m.complete(3)
+	if m.next != 5 {
+		t.Errorf("next = %d, want 5", m.next)
+	}
+}
@@ -0,0 +1,107 @@
+package gorker
+
+import (
+	"context"
+	"runtime"
+)
+
+// Lane is a set of workers sized for one kind of workload, see WithLanes
+type Lane int
+
+const (
+	// LaneCPU runs CPU bound jobs, it is usually sized to GOMAXPROCS
+	LaneCPU Lane = iota
+	// LaneIO runs jobs waiting on the network or disk, it is usually much larger
+	LaneIO
+)
+
+func (l Lane) String() string {
+	switch l {
+	case LaneCPU:
+		return "cpu"
+	case LaneIO:
+		return "io"
+	}
+	return "unknown"
+}
+
+// WithLanes splits the workers of the Dispatcher into a CPU lane of cpu workers and an
+// IO lane of io workers, the worker count becomes their sum. Jobs added by AddCPU and
+// AddIO only occupy workers of their lane, jobs added by Add are not limited by lanes.
+// A cpu below 1 defaults to GOMAXPROCS. Lanes share the queue, stats and lifecycle
+// of the Dispatcher.
+func WithLanes(cpu, io int) Option {
+	return func(d *Dispatcher) {
+		if cpu < 1 {
+			cpu = runtime.GOMAXPROCS(0)
+		}
+		if io < 1 {
+			io = 1
+		}
+		d.lanes = [...]*Subpool{
+			LaneCPU: d.Subpool(cpu),
+			LaneIO:  d.Subpool(io),
+		}
+		d.workerCount = cpu + io
+		d.workers = make([]*worker, d.workerCount)
+		for i := range d.workers {
+			d.workers[i] = newWorker(d)
+		}
+		d.resetBuffer()
+	}
+}
+
+func AddCPU(fn func() error, opts ...JobOption) chan error {
+	return instance.AddCPU(fn, opts...)
+}
+
+// AddCPU adds fn to the CPU lane, it is Add for a Dispatcher without lanes
+func (d *Dispatcher) AddCPU(fn func() error, opts ...JobOption) chan error {
+	return d.AddToLane(LaneCPU, func(context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+func AddIO(fn func() error, opts ...JobOption) chan error {
+	return instance.AddIO(fn, opts...)
+}
+
+// AddIO adds fn to the IO lane, it is Add for a Dispatcher without lanes
+func (d *Dispatcher) AddIO(fn func() error, opts ...JobOption) chan error {
+	return d.AddToLane(LaneIO, func(context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+// AddToLane adds fn to lane, it is AddJob for a Dispatcher without lanes
+func (d *Dispatcher) AddToLane(lane Lane, fn JobFunc, opts ...JobOption) chan error {
+	if lane < 0 || int(lane) >= len(d.lanes) || d.lanes[lane] == nil {
+		return d.AddJob(fn, opts...)
+	}
+	return d.lanes[lane].AddJob(fn, opts...)
+}
+
+// ScaleLane resizes lane to n workers and scales the Dispatcher to the sum of its lanes,
+// it is a no-op for a Dispatcher without lanes
+func (d *Dispatcher) ScaleLane(lane Lane, n int) *Dispatcher {
+	if lane < 0 || int(lane) >= len(d.lanes) || d.lanes[lane] == nil || n < 1 {
+		return d
+	}
+	cur := d.lanes[lane].Max()
+	d.lanes[lane].SetMax(n)
+	total := d.GetWorkerCount() + n - cur
+	if n > cur {
+		d.UpScale(total)
+	} else {
+		d.DownScale(total)
+	}
+	return d
+}
+
+// LaneSize returns the worker count of lane, 0 for a Dispatcher without lanes
+func (d *Dispatcher) LaneSize(lane Lane) int {
+	if lane < 0 || int(lane) >= len(d.lanes) || d.lanes[lane] == nil {
+		return 0
+	}
+	return d.lanes[lane].Max()
+}
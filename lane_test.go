@@ -0,0 +1,70 @@
+package gorker
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithLanes(t *testing.T) {
+	d := New(1, WithLanes(2, 4)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	if got := d.GetWorkerCount(); got != 6 {
+		t.Fatalf("workers = %d, want 6", got)
+	}
+	var cpu, io, cpuPeak, ioPeak int32
+	track := func(n, peak *int32) func() error {
+		return func() error {
+			v := atomic.AddInt32(n, 1)
+			for {
+				p := atomic.LoadInt32(peak)
+				if v <= p || atomic.CompareAndSwapInt32(peak, p, v) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(n, -1)
+			return nil
+		}
+	}
+	for i := 0; i < 20; i++ {
+		d.AddCPU(track(&cpu, &cpuPeak))
+		d.AddIO(track(&io, &ioPeak))
+	}
+	d.Wait()
+	if cpuPeak != 2 || ioPeak != 4 {
+		t.Errorf("peak concurrency cpu = %d io = %d, want 2 and 4", cpuPeak, ioPeak)
+	}
+	if got := d.Stats().Succeeded; got != 40 {
+		t.Errorf("succeeded = %d, want 40", got)
+	}
+}
+
+func TestDispatcher_ScaleLane(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		lane    Lane
+		n       int
+		want    int
+		workers int
+	}{
+		{name: "grow io", opts: []Option{WithLanes(2, 4)}, lane: LaneIO, n: 8, want: 8, workers: 10},
+		{name: "shrink cpu", opts: []Option{WithLanes(2, 4)}, lane: LaneCPU, n: 1, want: 1, workers: 5},
+		{name: "without lanes", lane: LaneIO, n: 8, want: 0, workers: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(3, tt.opts...).Start()
+			defer d.Stop(true)
+			d.ScaleLane(tt.lane, tt.n)
+			if got := d.LaneSize(tt.lane); got != tt.want {
+				t.Errorf("lane size = %d, want %d", got, tt.want)
+			}
+			if got := d.GetWorkerCount(); got != tt.workers {
+				t.Errorf("workers = %d, want %d", got, tt.workers)
+			}
+		})
+	}
+}
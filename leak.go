@@ -0,0 +1,110 @@
+package gorker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LabelGoroutine is the pprof label holding the role of a goroutine started by a
+	// Dispatcher, e.g. worker or queue_runner
+	LabelGoroutine = "gorker_goroutine"
+	// labelDispatcher tells apart the goroutines of Dispatchers sharing a name
+	labelDispatcher = "gorker_dispatcher"
+)
+
+// label sets the pprof labels of the calling goroutine, which must have been started by d,
+// and returns the labeled context
+func (d *Dispatcher) label(role string) context.Context {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels(
+		LabelPool, d.name,
+		LabelGoroutine, role,
+		labelDispatcher, fmt.Sprintf("%p", d),
+	))
+	pprof.SetGoroutineLabels(ctx)
+	return ctx
+}
+
+// spawn runs fn in a goroutine labeled with role
+func (d *Dispatcher) spawn(role string, fn func()) {
+	go func() {
+		d.label(role)
+		fn()
+	}()
+}
+
+func LeakCheck() error {
+	return instance.LeakCheck()
+}
+
+// LeakCheck returns an error listing the goroutines started by d which are alive, nil when
+// there are none. Once d was stopped by Flush or Kill and its queue runner and observer
+// were stopped, its goroutines exit shortly, see gorkertest.VerifyNone for a check waiting
+// for them. Goroutines started by jobs inherit the labels of their worker while profiler
+// labels are disabled and are reported as well.
+func (d *Dispatcher) LeakCheck() error {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return err
+	}
+	id := fmt.Sprintf("%p", d)
+	roles := make(map[string]int)
+	var count int
+	sc := bufio.NewScanner(&buf)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		// a stack starts with "<count> @ <pcs>" and may be followed by its labels
+		if n, _, ok := strings.Cut(line, " @ "); ok {
+			count, _ = strconv.Atoi(n)
+			continue
+		}
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok {
+			continue
+		}
+		m := parseLabels(labels)
+		if m[labelDispatcher] == id {
+			roles[m[LabelGoroutine]] += count
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	leaked := make([]string, 0, len(roles))
+	for role, n := range roles {
+		leaked = append(leaked, fmt.Sprintf("%d %s", n, role))
+	}
+	sort.Strings(leaked)
+	return fmt.Errorf("gorker: %s has live goroutines: %s", d.name, strings.Join(leaked, ", "))
+}
+
+// parseLabels parses the labels of a goroutine profile of debug level 1, {"key":"value", ...}
+func parseLabels(s string) map[string]string {
+	m := make(map[string]string)
+	s = strings.TrimPrefix(s, "{")
+	for {
+		key, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return m
+		}
+		s = strings.TrimPrefix(s[len(key):], ":")
+		value, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return m
+		}
+		s = strings.TrimPrefix(s[len(value):], ", ")
+		k, _ := strconv.Unquote(key)
+		v, _ := strconv.Unquote(value)
+		m[k] = v
+	}
+}
@@ -0,0 +1,79 @@
+package gorker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitNoLeaks waits for the goroutines of d to exit and returns the last LeakCheck error
+func waitNoLeaks(d *Dispatcher) error {
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := d.LeakCheck()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcher_LeakCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		stop func(d *Dispatcher)
+	}{
+		{
+			name: "kill",
+			stop: func(d *Dispatcher) { d.Kill() },
+		},
+		{
+			name: "flush",
+			stop: func(d *Dispatcher) { d.Flush(context.Background()) },
+		},
+		{
+			name: "subsystems",
+			opts: []Option{
+				WithBufferPolicy(BufferAdaptive, 0),
+				WithMemoryGuard(1<<62, time.Millisecond, nil),
+				WithObserverInterval(time.Millisecond, 0),
+			},
+			stop: func(d *Dispatcher) { d.Flush(context.Background()) },
+		},
+		{
+			name: "without profiler labels",
+			opts: []Option{WithoutProfilerLabels()},
+			stop: func(d *Dispatcher) { d.Kill() },
+		},
+		{
+			name: "on demand",
+			opts: []Option{WithPrespawn(false)},
+			stop: func(d *Dispatcher) { d.Kill() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(2, tt.opts...).QueueRunner().StartWorkerObserver().Start()
+			for i := 0; i < 10; i++ {
+				<-d.Add(func() error { return nil })
+			}
+			err := d.LeakCheck()
+			if err == nil || !strings.Contains(err.Error(), "worker") || !strings.Contains(err.Error(), "queue_runner") {
+				t.Fatalf("LeakCheck of a running Dispatcher = %v, want its workers and queue runner", err)
+			}
+			tt.stop(d)
+			if err := waitNoLeaks(d); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	got := parseLabels(`{"a\"b":"x, y", "gorker_goroutine":"worker"}`)
+	if len(got) != 2 || got[`a"b`] != "x, y" || got[LabelGoroutine] != "worker" {
+		t.Errorf("parseLabels = %v", got)
+	}
+}
@@ -0,0 +1,35 @@
+package gorker
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// WithLogger makes every job run with a logger derived from l carrying its job ID, tags,
+// attempt number and the name of the Dispatcher, see LoggerFrom
+func WithLogger(l *slog.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = l
+	}
+}
+
+// LoggerFrom returns the logger of the job executed with ctx, slog.Default when the
+// Dispatcher has no logger, see WithLogger
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// withLogger returns ctx carrying the logger of j
+func (d *Dispatcher) withLogger(ctx context.Context, j *job) context.Context {
+	attrs := make([]any, 0, 4)
+	attrs = append(attrs, slog.String("pool", d.name), slog.Uint64("job_id", uint64(j.id)), slog.Int("attempt", j.attempt+1))
+	if len(j.tags) > 0 {
+		attrs = append(attrs, slog.Any("tags", j.tags))
+	}
+	return context.WithValue(ctx, loggerKey{}, d.logger.With(attrs...))
+}
@@ -0,0 +1,67 @@
+package gorker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func TestWithLogger(t *testing.T) {
+	buf := new(syncBuffer)
+	d := New(1, WithName("logged"), WithLogger(slog.New(slog.NewJSONHandler(buf, nil)))).QueueRunner().Start()
+	defer d.Stop(true)
+
+	attempts := 0
+	err := <-d.AddJob(func(ctx context.Context) error {
+		LoggerFrom(ctx).Info("attempt")
+		if attempts++; attempts == 1 {
+			return errors.New("retry me")
+		}
+		return nil
+	}, WithTags("billing"), WithRetry(1, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var rec struct {
+			Pool    string   `json:"pool"`
+			JobID   uint64   `json:"job_id"`
+			Attempt int      `json:"attempt"`
+			Tags    []string `json:"tags"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Pool != "logged" || rec.JobID != 1 || rec.Attempt != i+1 || len(rec.Tags) != 1 || rec.Tags[0] != "billing" {
+			t.Errorf("record %d = %+v", i, rec)
+		}
+	}
+}
+
+func TestLoggerFrom_Default(t *testing.T) {
+	if LoggerFrom(context.Background()) != slog.Default() {
+		t.Error("LoggerFrom without a logger is not slog.Default")
+	}
+}
@@ -0,0 +1,75 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+)
+
+// Map runs fn for every item on d and returns the results in input order.
+// It stops submitting and skips queued items on the first error or when ctx is done,
+// an item rejected by d fails Map with its RejectionError.
+func Map[T, R any](ctx context.Context, d *Dispatcher, items []T, fn func(T) (R, error)) ([]R, error) {
+	res := make([]R, len(items))
+	err := forEachIndex(ctx, d, items, func(i int, item T) error {
+		r, err := fn(item)
+		if err != nil {
+			return err
+		}
+		res[i] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ForEach runs fn for every item on d and returns the first error
+func ForEach[T any](ctx context.Context, d *Dispatcher, items []T, fn func(T) error) error {
+	return forEachIndex(ctx, d, items, func(_ int, item T) error {
+		return fn(item)
+	})
+}
+
+// forEachIndex is ForEach with the index of each item passed to fn
+func forEachIndex[T any](ctx context.Context, d *Dispatcher, items []T, fn func(int, T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			first = err
+			cancel()
+		})
+	}
+
+	for i, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		i, item := i, item
+		wg.Add(1)
+		d.Add(func() error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fn(i, item)
+		}, onDone(func(err error) {
+			if err != nil {
+				fail(err)
+			}
+			wg.Done()
+		}))
+	}
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	return ctx.Err()
+}
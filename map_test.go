@@ -0,0 +1,99 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	got, err := Map(context.Background(), d, items, func(n int) (int, error) {
+		return n * n, nil
+	})
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+	for i, v := range got {
+		if v != i*i {
+			t.Fatalf("got[%d] = %d, want %d", i, v, i*i)
+		}
+	}
+}
+
+func TestMap_Error(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	want := errors.New("fail")
+	var calls int32
+	items := make([]int, 1000)
+	_, err := Map(context.Background(), d, items, func(n int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, want
+	})
+	if !errors.Is(err, want) {
+		t.Errorf("Map() error = %v, want %v", err, want)
+	}
+	if c := atomic.LoadInt32(&calls); c == int32(len(items)) {
+		t.Errorf("fn called for every item after first error")
+	}
+}
+
+func TestForEach_Canceled(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ForEach(ctx, d, []int{1, 2, 3}, func(int) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ForEach() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestMap_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		d    func() *Dispatcher
+		want error
+	}{
+		{
+			name: "stopped",
+			d: func() *Dispatcher {
+				return New(1).Start().Kill()
+			},
+			want: ErrStopped,
+		},
+		{
+			name: "expired",
+			d: func() *Dispatcher {
+				return New(1, WithMaxQueueAge(time.Nanosecond)).QueueRunner().Start()
+			},
+			want: ErrExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.d()
+			defer d.Stop(true)
+			_, err := Map(context.Background(), d, []int{1, 2, 3}, func(n int) (int, error) {
+				time.Sleep(time.Millisecond)
+				return n, nil
+			})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Map() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
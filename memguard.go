@@ -0,0 +1,125 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// ErrMemoryLimitExceeded is the context cause of jobs aborted by the memory guard
+var ErrMemoryLimitExceeded = errors.New("gorker: process memory limit exceeded")
+
+const (
+	memoryTotalMetric    = "/memory/classes/total:bytes"
+	memoryReleasedMetric = "/memory/classes/heap/released:bytes"
+)
+
+var defaultMemoryGuardInterval = 100 * time.Millisecond
+
+type memoryGuard struct {
+	limit    uint64
+	interval time.Duration
+	onExceed func(JobInfo, uint64)
+	mu       sync.Mutex
+	running  map[JobID]guardedJob
+	sampler  memorySampler
+}
+
+type guardedJob struct {
+	info   JobInfo
+	cancel context.CancelCauseFunc
+}
+
+// WithMemoryGuard samples process memory every interval while jobs are running and
+// cancels the context of every running job with ErrMemoryLimitExceeded once usage
+// crosses limit bytes, emitting an EventMemoryExceeded for every aborted job.
+// onExceed, if not nil, is a shorthand for an OnEvent hook of those events.
+// This is best effort, a job ignoring its context keeps running.
+func WithMemoryGuard(limit uint64, interval time.Duration, onExceed func(info JobInfo, usage uint64)) Option {
+	return func(d *Dispatcher) {
+		if interval <= 0 {
+			interval = defaultMemoryGuardInterval
+		}
+		d.memGuard = &memoryGuard{
+			limit:    limit,
+			interval: interval,
+			onExceed: onExceed,
+			running:  make(map[JobID]guardedJob),
+			sampler:  newMemorySampler(),
+		}
+	}
+}
+
+func (g *memoryGuard) track(j *job, cancel context.CancelCauseFunc) {
+	g.mu.Lock()
+	g.running[j.id] = guardedJob{
+		info:   j.info(),
+		cancel: cancel,
+	}
+	g.mu.Unlock()
+}
+
+func (g *memoryGuard) untrack(id JobID) {
+	g.mu.Lock()
+	delete(g.running, id)
+	g.mu.Unlock()
+}
+
+// memorySampler reads the memory mapped by the process without the heap memory
+// already released to the OS, which the total keeps counting. It is not safe for
+// concurrent use.
+type memorySampler []metrics.Sample
+
+func newMemorySampler() memorySampler {
+	return memorySampler{{Name: memoryTotalMetric}, {Name: memoryReleasedMetric}}
+}
+
+func (s memorySampler) usage() uint64 {
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindUint64 || s[1].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s[0].Value.Uint64() - s[1].Value.Uint64()
+}
+
+func (g *memoryGuard) run(ctx context.Context, clock Clock, events *eventHooks) {
+	ticker := clock.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			g.check(events)
+		}
+	}
+}
+
+func (g *memoryGuard) check(events *eventHooks) {
+	g.mu.Lock()
+	if len(g.running) == 0 {
+		g.mu.Unlock()
+		return
+	}
+	usage := g.sampler.usage()
+	if usage <= g.limit {
+		g.mu.Unlock()
+		return
+	}
+	aborted := make([]guardedJob, 0, len(g.running))
+	for id, gj := range g.running {
+		aborted = append(aborted, gj)
+		delete(g.running, id)
+	}
+	g.mu.Unlock()
+
+	for _, gj := range aborted {
+		gj.cancel(ErrMemoryLimitExceeded)
+		events.memoryExceeded(gj.info, usage)
+		if g.onExceed != nil {
+			g.onExceed(gj.info, usage)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestWithMemoryGuard(t *testing.T) {
+	exceeded := make(chan JobInfo, 1)
+	events := make(chan Event, 1)
+	d := New(1, WithMemoryGuard(1, 5*time.Millisecond, func(info JobInfo, usage uint64) {
+		exceeded <- info
+	})).OnEvent(func(ev Event) {
+		if ev.Type == EventMemoryExceeded {
+			events <- ev
+		}
+	}).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ech := d.AddJob(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+
+	if err := <-ech; !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Errorf("job error = %v, want %v", err, ErrMemoryLimitExceeded)
+	}
+	select {
+	case info := <-exceeded:
+		if info.ID == 0 {
+			t.Error("onExceed called without job info")
+		}
+	case <-time.After(time.Second):
+		t.Error("onExceed was not called")
+	}
+	select {
+	case ev := <-events:
+		if ev.Job == nil || ev.Job.ID == 0 || ev.Usage <= 1 || !errors.Is(ev.Err, ErrMemoryLimitExceeded) {
+			t.Errorf("memory exceeded event = %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Error("memory exceeded event was not emitted")
+	}
+}
+
+func TestMemorySampler(t *testing.T) {
+	s := newMemorySampler()
+	const size = 64 << 20
+	buf := make([]byte, size)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	peak := s.usage()
+	runtime.KeepAlive(buf)
+	buf = nil
+	runtime.GC()
+	debug.FreeOSMemory()
+	if got := s.usage(); got > peak-size/2 {
+		t.Errorf("usage after releasing %d bytes = %d, want below %d", size, got, peak-size/2)
+	}
+}
@@ -0,0 +1,143 @@
+package gorker
+
+import (
+	"context"
+	"time"
+)
+
+var defaultOnDemandIdle = time.Second
+
+type onDemand struct {
+	idle    time.Duration
+	min     int
+	handoff chan *job
+	active  int
+}
+
+func newOnDemand() *onDemand {
+	return &onDemand{
+		idle:    defaultOnDemandIdle,
+		handoff: make(chan *job),
+	}
+}
+
+// ActiveWorkers returns the number of live on demand worker goroutines
+func (d *Dispatcher) ActiveWorkers() int {
+	if d.onDemand == nil {
+		return d.GetWorkerCount()
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.onDemand.active
+}
+
+func (d *Dispatcher) startOnDemand(ctx context.Context) {
+	od := d.onDemand
+	d.mu.Lock()
+	spawn := od.min - od.active
+	if spawn > d.workerCount-od.active {
+		spawn = d.workerCount - od.active
+	}
+	for i := 0; i < spawn; i++ {
+		od.active++
+		go d.onDemandWorker(ctx, nil, nil)
+	}
+	d.mu.Unlock()
+	d.spawn("on_demand", func() {
+		for {
+			d.mu.RLock()
+			qout, changed := d.qout, d.changed
+			d.mu.RUnlock()
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			case j := <-qout:
+				d.dispatchOnDemand(ctx, j)
+			}
+		}
+	})
+}
+
+func (d *Dispatcher) dispatchOnDemand(ctx context.Context, j *job) {
+	od := d.onDemand
+	select {
+	case od.handoff <- j:
+		return
+	default:
+	}
+
+	d.mu.Lock()
+	if od.active < d.workerCount {
+		od.active++
+		d.mu.Unlock()
+		go d.onDemandWorker(ctx, j, nil)
+		return
+	}
+	d.mu.Unlock()
+
+	select {
+	case od.handoff <- j:
+	case <-ctx.Done():
+		d.complete(j, ctx.Err())
+	}
+}
+
+// onDemandWorker runs j and then the handed off jobs until it was idle for the idle duration
+// while more than the minimum of workers are active, a nil j only parks the worker. Workers
+// without an idle duration never exit. scope is created when it was not initialized by Warmup.
+func (d *Dispatcher) onDemandWorker(ctx context.Context, j *job, scope *WorkerScope) {
+	od := d.onDemand
+	exited := false
+	defer func() {
+		if !exited {
+			d.mu.Lock()
+			od.active--
+			d.mu.Unlock()
+		}
+	}()
+
+	labels := d.label("worker")
+	if scope == nil {
+		scope = d.newWorkerScope(ctx)
+	}
+	scope.labels = labels
+	d.events.worker(EventWorkerStarted, scope.ID)
+	defer d.events.worker(EventWorkerStopped, scope.ID)
+	d.runJob(j, scope)
+	if od.idle <= 0 {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case j = <-od.handoff:
+				d.runJob(j, scope)
+			}
+		}
+	}
+	timer := d.clock.NewTimer(od.idle)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			d.mu.Lock()
+			if od.active > od.min {
+				od.active--
+				exited = true
+			}
+			d.mu.Unlock()
+			if exited {
+				return
+			}
+			timer.Reset(od.idle)
+		case j = <-od.handoff:
+			d.runJob(j, scope)
+			if !timer.Stop() {
+				<-timer.C()
+			}
+			timer.Reset(od.idle)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package gorker
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithOnDemandWorkers(t *testing.T) {
+	d := New(3, WithOnDemandWorkers(), WithOnDemandIdle(20*time.Millisecond)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	if got := d.ActiveWorkers(); got != 0 {
+		t.Fatalf("ActiveWorkers() before jobs = %d, want 0", got)
+	}
+
+	var running, peak int32
+	for i := 0; i < 30; i++ {
+		d.Add(func() error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	d.Wait()
+
+	if p := atomic.LoadInt32(&peak); p > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", p)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for d.ActiveWorkers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("ActiveWorkers() = %d after idle timeout, want 0", d.ActiveWorkers())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWithIdleTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		min  int
+	}{
+		{
+			name: "scale to zero",
+			min:  0,
+		},
+		{
+			name: "keep minimum",
+			min:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(4, WithIdleTimeout(20*time.Millisecond), WithMinWorkers(tt.min)).QueueRunner().Start()
+			defer d.Stop(true)
+
+			if got := d.ActiveWorkers(); got != tt.min {
+				t.Fatalf("ActiveWorkers() after Start = %d, want %d", got, tt.min)
+			}
+			release := make(chan struct{})
+			for i := 0; i < 4; i++ {
+				d.Add(func() error {
+					<-release
+					return nil
+				})
+			}
+			deadline := time.Now().Add(time.Second)
+			for d.ActiveWorkers() != 4 {
+				if time.Now().After(deadline) {
+					t.Fatalf("ActiveWorkers() = %d under load, want 4", d.ActiveWorkers())
+				}
+				time.Sleep(time.Millisecond)
+			}
+			close(release)
+			d.Wait()
+
+			deadline = time.Now().Add(time.Second)
+			for d.ActiveWorkers() != tt.min {
+				if time.Now().After(deadline) {
+					t.Fatalf("ActiveWorkers() = %d after idle timeout, want %d", d.ActiveWorkers(), tt.min)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			if err := <-d.Add(func() error { return nil }); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package gorker
+
+import "time"
+
+// Option configures a Dispatcher created by New
+type Option func(*Dispatcher)
+
+// WithOnDemandWorkers makes the Dispatcher spawn worker goroutines per job up to
+// the worker count instead of keeping them parked, idle goroutines exit after
+// the on demand idle duration.
+func WithOnDemandWorkers() Option {
+	return func(d *Dispatcher) {
+		if d.onDemand == nil {
+			d.onDemand = newOnDemand()
+		}
+	}
+}
+
+// WithOnDemandIdle sets how long an on demand worker waits for a next job before exiting
+func WithOnDemandIdle(idle time.Duration) Option {
+	return WithIdleTimeout(idle)
+}
+
+// WithIdleTimeout makes workers terminate after being idle for idle and respawn when jobs
+// arrive, up to the worker count. Without WithMinWorkers the pool scales to zero and the
+// first submitted job spins up a worker.
+func WithIdleTimeout(idle time.Duration) Option {
+	return func(d *Dispatcher) {
+		if d.onDemand == nil {
+			d.onDemand = newOnDemand()
+		}
+		if idle > 0 {
+			d.onDemand.idle = idle
+		}
+	}
+}
+
+// WithMinWorkers keeps min workers alive when they are idle, see WithIdleTimeout.
+// The workers are started with the Dispatcher.
+func WithMinWorkers(min int) Option {
+	return func(d *Dispatcher) {
+		if d.onDemand == nil {
+			d.onDemand = newOnDemand()
+		}
+		if min > 0 {
+			d.onDemand.min = min
+		}
+	}
+}
+
+// WithWorkers sets the worker count, it is meant for Reconfigure and SetDefaults since New
+// takes the worker count as an argument
+func WithWorkers(n int) Option {
+	return func(d *Dispatcher) {
+		if n < 1 || d.State() != StateCreated {
+			return
+		}
+		d.workerCount = n
+		d.workers = make([]*worker, n)
+		for i := range d.workers {
+			d.workers[i] = newWorker(d)
+		}
+		d.resetBuffer()
+	}
+}
+
+// WithSynchronous makes the Dispatcher run every job on the goroutine submitting it,
+// Add returns after the job finished. It is meant for deterministic tests.
+func WithSynchronous() Option {
+	return func(d *Dispatcher) {
+		d.synchronous = true
+	}
+}
+
+// WithObserverInterval sets how often the worker observer reconciles the worker count,
+// each wait is extended by a random duration up to jitter so that the observers of many
+// Dispatchers do not wake up together. The default is 100ms with a 10ms jitter.
+func WithObserverInterval(interval, jitter time.Duration) Option {
+	return func(d *Dispatcher) {
+		if interval > 0 {
+			d.observeInterval = interval
+		}
+		if jitter >= 0 {
+			d.observeJitter = jitter
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrQueueFull is returned by jobs rejected because the queue limit was reached
+var ErrQueueFull = errors.New("gorker: queue is full")
+
+// OverflowPolicy decides what Add does when the queue limit is reached
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the submitter until a job finished and the queue is below its limit
+	OverflowBlock OverflowPolicy = iota
+	// OverflowReject fails the job with ErrQueueFull
+	OverflowReject
+	// OverflowCallerRuns executes the job on the submitting goroutine, which
+	// slows producers down to the speed of the pool
+	OverflowCallerRuns
+)
+
+type queueLimit struct {
+	max    int
+	policy OverflowPolicy
+}
+
+// WithQueueLimit bounds the number of queued jobs to max and applies policy to submissions beyond it.
+// The limit is checked on submission, concurrent submitters may exceed it briefly.
+func WithQueueLimit(max int, policy OverflowPolicy) Option {
+	return func(d *Dispatcher) {
+		if max < 1 {
+			d.queueLimit.Store(nil)
+			return
+		}
+		d.queueLimit.Store(&queueLimit{
+			max:    max,
+			policy: policy,
+		})
+	}
+}
+
+// overflow applies the queue limit to a submission and reports whether it was rejected
+// and whether the job has to run on the caller
+func (d *Dispatcher) overflow() (reject, callerRuns bool) {
+	l := d.queueLimit.Load()
+	if l == nil {
+		return false, false
+	}
+	for d.queueDepth()+int(atomic.LoadInt64(&d.reserved)) >= l.max {
+		switch l.policy {
+		case OverflowReject:
+			return true, false
+		case OverflowCallerRuns:
+			return false, true
+		}
+		d.WaitN(1)
+	}
+	return false, false
+}
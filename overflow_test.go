@@ -0,0 +1,87 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithQueueLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		check  func(t *testing.T, d *Dispatcher, release chan struct{})
+	}{
+		{
+			name:   "reject",
+			policy: OverflowReject,
+			check: func(t *testing.T, d *Dispatcher, release chan struct{}) {
+				if err := <-d.Add(func() error { return nil }); !errors.Is(err, ErrQueueFull) {
+					t.Errorf("Add() over the limit = %v, want %v", err, ErrQueueFull)
+				}
+				close(release)
+			},
+		},
+		{
+			name:   "caller runs",
+			policy: OverflowCallerRuns,
+			check: func(t *testing.T, d *Dispatcher, release chan struct{}) {
+				ran := false
+				ech := d.Add(func() error {
+					ran = true
+					return nil
+				})
+				if !ran {
+					t.Error("job over the limit did not run on the caller")
+				}
+				if err := <-ech; err != nil {
+					t.Error(err)
+				}
+				close(release)
+			},
+		},
+		{
+			name:   "block",
+			policy: OverflowBlock,
+			check: func(t *testing.T, d *Dispatcher, release chan struct{}) {
+				added := make(chan struct{})
+				go func() {
+					d.Add(func() error { return nil })
+					close(added)
+				}()
+				select {
+				case <-added:
+					t.Fatal("Add() over the limit did not block")
+				case <-time.After(20 * time.Millisecond):
+				}
+				close(release)
+				<-added
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1, WithQueueLimit(2, tt.policy)).QueueRunner().Start()
+			defer d.Stop(true)
+
+			release := make(chan struct{})
+			block := func() error {
+				<-release
+				return nil
+			}
+			// one running job and two queued ones
+			d.Add(block)
+			deadline := time.Now().Add(time.Second)
+			for d.Stats().Running != 1 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			d.Add(block)
+			d.Add(block)
+			for d.Stats().QueueDepth != 2 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			tt.check(t, d, release)
+			d.Wait()
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultStageBuffer = 100
+
+// Stage is a step of a Pipeline turning In values into Out values, stages are created
+// with NewStage and connected with Then so that mismatched stages do not compile
+type Stage[In, Out any] interface {
+	run(ctx context.Context, p *pipeline, in <-chan In) <-chan Out
+}
+
+type stage[In, Out any] struct {
+	dis    *Dispatcher
+	buffer int
+	fn     func(In) (Out, error)
+}
+
+// NewStage creates a pipeline stage which runs fn for every input on d.
+// buffer bounds the channel between this stage and the next one.
+func NewStage[In, Out any](d *Dispatcher, buffer int, fn func(In) (Out, error)) Stage[In, Out] {
+	if buffer < 1 {
+		buffer = defaultStageBuffer
+	}
+	return &stage[In, Out]{
+		dis:    d,
+		buffer: buffer,
+		fn:     fn,
+	}
+}
+
+type chain[In, Mid, Out any] struct {
+	first  Stage[In, Mid]
+	second Stage[Mid, Out]
+}
+
+// Then connects first and second so that the output of first is the input of second
+func Then[In, Mid, Out any](first Stage[In, Mid], second Stage[Mid, Out]) Stage[In, Out] {
+	return &chain[In, Mid, Out]{
+		first:  first,
+		second: second,
+	}
+}
+
+func (c *chain[In, Mid, Out]) run(ctx context.Context, p *pipeline, in <-chan In) <-chan Out {
+	return c.second.run(ctx, p, c.first.run(ctx, p, in))
+}
+
+// Pipeline runs a Stage, usually stages connected with Then, over a channel of inputs
+type Pipeline[In, Out any] struct {
+	stage Stage[In, Out]
+	pipeline
+}
+
+// pipeline tracks the stage goroutines of a Pipeline run and its first error
+type pipeline struct {
+	wg     *sync.WaitGroup
+	once   sync.Once
+	err    error
+	cancel context.CancelFunc
+}
+
+func NewPipeline[In, Out any](s Stage[In, Out]) *Pipeline[In, Out] {
+	return &Pipeline[In, Out]{
+		stage: s,
+		pipeline: pipeline{
+			wg: new(sync.WaitGroup),
+		},
+	}
+}
+
+// Run feeds in through all stages and returns the output of the last stage.
+// The output channel is closed after in is closed and every stage has drained,
+// or after the first stage error or ctx cancellation.
+func (p *Pipeline[In, Out]) Run(ctx context.Context, in <-chan In) <-chan Out {
+	ctx, p.cancel = context.WithCancel(ctx)
+	return p.stage.run(ctx, &p.pipeline, in)
+}
+
+// Wait blocks until every stage has finished and returns the first stage error,
+// or the error of the context given to Run when it ended the run early
+func (p *Pipeline[In, Out]) Wait() error {
+	return p.wait()
+}
+
+func (p *pipeline) wait() error {
+	p.wg.Wait()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return p.err
+}
+
+func (p *pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+func (s *stage[In, Out]) run(ctx context.Context, p *pipeline, in <-chan In) <-chan Out {
+	out := make(chan Out, s.buffer)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(out)
+		inflight := new(sync.WaitGroup)
+		defer inflight.Wait()
+		for {
+			select {
+			case <-ctx.Done():
+				// a no-op after a stage error, which canceled ctx
+				p.fail(ctx.Err())
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				inflight.Add(1)
+				s.dis.Add(func() error {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					res, err := s.fn(v)
+					if err != nil {
+						return err
+					}
+					select {
+					case out <- res:
+					case <-ctx.Done():
+					}
+					return nil
+				}, onDone(func(err error) {
+					// items rejected by the Dispatcher of the stage fail the pipeline too
+					if err != nil && err != ctx.Err() {
+						p.fail(err)
+					}
+					inflight.Done()
+				}))
+			}
+		}
+	}()
+	return out
+}
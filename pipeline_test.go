@@ -0,0 +1,124 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	d1 := New(3).QueueRunner().Start()
+	d2 := New(2).QueueRunner().Start()
+	defer d1.Stop(true)
+	defer d2.Stop(true)
+
+	p := NewPipeline(Then(
+		NewStage(d1, 10, func(n int) (int, error) {
+			return n * 2, nil
+		}),
+		NewStage(d2, 10, func(n int) (string, error) {
+			return strconv.Itoa(n), nil
+		}),
+	))
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 100; i++ {
+			in <- i
+		}
+	}()
+
+	got := make([]string, 0, 100)
+	for v := range p.Run(context.Background(), in) {
+		got = append(got, v)
+	}
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if len(got) != 100 {
+		t.Fatalf("output length = %d, want 100", len(got))
+	}
+	sort.Slice(got, func(i, j int) bool {
+		a, _ := strconv.Atoi(got[i])
+		b, _ := strconv.Atoi(got[j])
+		return a < b
+	})
+	if got[99] != "198" {
+		t.Errorf("last output = %s, want 198", got[99])
+	}
+}
+
+func TestPipeline_Error(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	want := errors.New("stage failed")
+	p := NewPipeline(NewStage(d, 1, func(n int) (int, error) {
+		if n == 3 {
+			return 0, want
+		}
+		return n, nil
+	}))
+
+	in := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		in <- i
+	}
+	close(in)
+
+	for range p.Run(context.Background(), in) {
+	}
+	if err := p.Wait(); !errors.Is(err, want) {
+		t.Errorf("Wait() = %v, want %v", err, want)
+	}
+}
+
+func TestPipeline_Rejected(t *testing.T) {
+	d1 := New(2).QueueRunner().Start()
+	defer d1.Stop(true)
+	d2 := New(2).Start().Kill()
+
+	p := NewPipeline(Then(
+		NewStage(d1, 1, func(n int) (int, error) {
+			return n, nil
+		}),
+		NewStage(d2, 1, func(n int) (int, error) {
+			return n, nil
+		}),
+	))
+
+	in := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		in <- i
+	}
+	close(in)
+
+	for range p.Run(context.Background(), in) {
+	}
+	if err := p.Wait(); !errors.Is(err, ErrStopped) {
+		t.Errorf("Wait() = %v, want %v", err, ErrStopped)
+	}
+}
+
+func TestPipeline_Canceled(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	p := NewPipeline(NewStage(d, 1, func(n int) (int, error) {
+		return n, nil
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out := p.Run(ctx, in)
+	in <- 1
+	<-out
+	cancel()
+	for range out {
+	}
+	if err := p.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,52 @@
+package gorker
+
+import "sync"
+
+// WithPrespawn selects whether the worker goroutines are all spawned at Start, the default,
+// or lazily when jobs arrive. Lazily spawned workers are kept once started, combine
+// WithIdleTimeout with it to let idle workers exit. See Warmup.
+func WithPrespawn(prespawn bool) Option {
+	return func(d *Dispatcher) {
+		if prespawn {
+			d.onDemand = nil
+			return
+		}
+		if d.onDemand == nil {
+			d.onDemand = newOnDemand()
+		}
+		d.onDemand.idle = 0
+	}
+}
+
+func Warmup(n int) *Dispatcher {
+	return instance.Warmup(n)
+}
+
+// Warmup spawns lazily spawned workers of a started Dispatcher until n of them, at most the
+// worker count, are alive and returns once the init hooks of the new workers completed, see
+// WithWorkerInit. Prespawned workers are initialized at Start, Warmup does nothing for them.
+func (d *Dispatcher) Warmup(n int) *Dispatcher {
+	od := d.onDemand
+	if od == nil || !d.State().active() {
+		return d
+	}
+	d.mu.Lock()
+	spawn := min(n, d.workerCount) - od.active
+	if spawn > 0 {
+		od.active += spawn
+	}
+	ctx := d.ctx
+	d.mu.Unlock()
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < spawn; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scope := d.newWorkerScope(ctx)
+			go d.onDemandWorker(ctx, nil, scope)
+		}()
+	}
+	wg.Wait()
+	return d
+}
@@ -0,0 +1,65 @@
+package gorker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithPrespawn(t *testing.T) {
+	tests := []struct {
+		name     string
+		prespawn bool
+		want     int
+	}{
+		{name: "prespawn", prespawn: true, want: 4},
+		{name: "lazy", prespawn: false, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inits int32
+			d := New(4, WithPrespawn(tt.prespawn), WithWorkerInit(func(context.Context, *WorkerScope) error {
+				atomic.AddInt32(&inits, 1)
+				return nil
+			})).QueueRunner().Start()
+			defer d.Stop(true)
+
+			if got := d.ActiveWorkers(); got != tt.want {
+				t.Errorf("active workers = %d, want %d", got, tt.want)
+			}
+			if err := <-d.Add(func() error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.prespawn && atomic.LoadInt32(&inits) != 1 {
+				t.Errorf("inits = %d after the first job, want 1", inits)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Warmup(t *testing.T) {
+	var inits int32
+	d := New(3, WithPrespawn(false), WithWorkerInit(func(context.Context, *WorkerScope) error {
+		atomic.AddInt32(&inits, 1)
+		return nil
+	})).QueueRunner()
+	defer d.Stop(true)
+
+	d.Warmup(2)
+	if got := atomic.LoadInt32(&inits); got != 0 {
+		t.Fatalf("Warmup before Start initialized %d workers", got)
+	}
+	d.Start().Warmup(5)
+	if got := atomic.LoadInt32(&inits); got != 3 {
+		t.Errorf("inits = %d after Warmup, want the worker count of 3", got)
+	}
+	if got := d.ActiveWorkers(); got != 3 {
+		t.Errorf("active workers = %d, want 3", got)
+	}
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&inits); got != 3 {
+		t.Errorf("a warm pool initialized another worker, inits = %d", got)
+	}
+}
@@ -0,0 +1,205 @@
+package gorker
+
+// classWindow is the number of dispatches over which minimum class shares are enforced
+const classWindow = 1000
+
+type priorityClasses struct {
+	names  []string
+	index  map[string]int
+	shares map[string]float64
+	limits map[string]int
+	stats  *tagStats
+	// served counts the dispatches of each class in the current window, guarded by Dispatcher.mu
+	served []int
+	total  int
+	// reserved are the workers reserved per class, busy counts the dispatched jobs of each
+	// class until they complete and inUse their sum, see WithClassWorkers
+	reserved map[string]int
+	busy     map[string]int
+	inUse    int
+}
+
+func (d *Dispatcher) priorityClasses() *priorityClasses {
+	if d.classes == nil {
+		d.classes = &priorityClasses{
+			index:    make(map[string]int),
+			shares:   make(map[string]float64),
+			limits:   make(map[string]int),
+			stats:    newTagStats(),
+			reserved: make(map[string]int),
+			busy:     make(map[string]int),
+		}
+	}
+	return d.classes
+}
+
+// WithPriorityClasses creates one queue class per name, from the highest priority to the lowest.
+// Queued jobs of a class are dispatched before any job of a lower class, see WithClassShare.
+// Jobs without a class, or with an unknown one, belong to the class "default" if there is
+// one and to the lowest class otherwise.
+func WithPriorityClasses(names ...string) Option {
+	return func(d *Dispatcher) {
+		if len(names) == 0 {
+			return
+		}
+		pc := d.priorityClasses()
+		pc.names = append(pc.names[:0], names...)
+		pc.served = make([]int, len(names))
+		for i, name := range names {
+			pc.index[name] = i
+		}
+	}
+}
+
+// WithClassShare guarantees the class name at least share of the dispatches while it has
+// queued jobs, so that strict priority does not starve it
+func WithClassShare(name string, share float64) Option {
+	return func(d *Dispatcher) {
+		d.priorityClasses().shares[name] = share
+	}
+}
+
+// WithClassLimit fails submissions to the class name with ErrQueueFull while maxQueued
+// of its jobs are queued
+func WithClassLimit(name string, maxQueued int) Option {
+	return func(d *Dispatcher) {
+		d.priorityClasses().limits[name] = maxQueued
+	}
+}
+
+// WithClass submits the job to the priority class name, see WithPriorityClasses
+func WithClass(name string) JobOption {
+	return func(j *job) {
+		j.class = name
+	}
+}
+
+func ClassStats() map[string]TagStats {
+	return instance.ClassStats()
+}
+
+// ClassStats returns the counters of every priority class
+func (d *Dispatcher) ClassStats() map[string]TagStats {
+	if d.classes == nil {
+		return nil
+	}
+	st := d.classes.stats
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	res := make(map[string]TagStats, len(d.classes.names))
+	for _, name := range d.classes.names {
+		if s, ok := st.stats[name]; ok {
+			res[name] = *s
+		} else {
+			res[name] = TagStats{}
+		}
+	}
+	return res
+}
+
+func (pc *priorityClasses) defaultPrio() int {
+	if prio, ok := pc.index["default"]; ok {
+		return prio
+	}
+	return len(pc.names) - 1
+}
+
+// assign resolves the class of j and reports whether the class accepts another job
+func (pc *priorityClasses) assign(j *job) bool {
+	prio, ok := pc.index[j.class]
+	if !ok {
+		prio = pc.defaultPrio()
+	}
+	j.class = pc.names[prio]
+	j.prio = prio
+	if limit := pc.limits[j.class]; limit > 0 && pc.queued(j.class) >= int64(limit) {
+		j.class = ""
+		return false
+	}
+	pc.stats.submitted(j.classTags())
+	return true
+}
+
+func (pc *priorityClasses) queued(class string) int64 {
+	pc.stats.mu.Lock()
+	defer pc.stats.mu.Unlock()
+	if s, ok := pc.stats.stats[class]; ok {
+		return s.Queued
+	}
+	return 0
+}
+
+// push inserts j behind the queued jobs of its class and of every higher class, and under
+// earliest deadline first ahead of the jobs of its class due later
+func (d *Dispatcher) push(queue []*job, j *job) []*job {
+	if d.fairness != nil {
+		d.fairness.pushed(j)
+	}
+	classes := d.classes != nil && len(d.classes.names) > 0
+	if !classes && (!d.edf || j.due.IsZero()) {
+		return append(queue, j)
+	}
+	i := len(queue)
+	for i > 0 && (queue[i-1].prio > j.prio || d.edf && queue[i-1].prio == j.prio && j.dueBefore(queue[i-1])) {
+		i--
+	}
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = j
+	return queue
+}
+
+// next returns the job to dispatch, the head of the queue unless a class is below its share
+// or another submitter has its turn, see WithFairness. It returns nil when reserved workers
+// leave no room for the queued jobs, see WithClassWorkers.
+// d.mu must be held.
+func (d *Dispatcher) next() *job {
+	j := d.pick()
+	if pc := d.classes; pc != nil && len(pc.reserved) > 0 && !pc.admits(j, d.workerCount) {
+		return pc.reservedJob(d.queue, d.workerCount)
+	}
+	return j
+}
+
+func (d *Dispatcher) pick() *job {
+	if pc := d.classes; pc != nil && len(pc.shares) > 0 && pc.total > 0 {
+		for class, share := range pc.shares {
+			prio, ok := pc.index[class]
+			if !ok || float64(pc.served[prio]) >= share*float64(pc.total) || prio == d.queue[0].prio {
+				continue
+			}
+			for _, j := range d.queue {
+				if j.prio == prio {
+					return j
+				}
+			}
+		}
+	}
+	if d.fairness != nil {
+		return d.fairness.next(d.queue)
+	}
+	return d.queue[0]
+}
+
+// dispatched accounts a dispatch of j, d.mu must be held
+func (pc *priorityClasses) dispatched(j *job) {
+	if len(pc.served) == 0 {
+		return
+	}
+	pc.served[j.prio]++
+	pc.total++
+	if pc.total >= classWindow {
+		pc.total = 0
+		for i := range pc.served {
+			pc.served[i] /= 2
+			pc.total += pc.served[i]
+		}
+	}
+}
+
+func (j *job) classTags() []string {
+	if j.class == "" {
+		return nil
+	}
+	return []string{j.class}
+}
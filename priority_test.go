@@ -0,0 +1,92 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithPriorityClasses(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(t *testing.T, order []string)
+	}{
+		{
+			name: "strict priority",
+			check: func(t *testing.T, order []string) {
+				for i, class := range order {
+					want := []string{"high", "default", "low"}[i/10]
+					if class != want {
+						t.Fatalf("job %d ran from class %s, want %s: %v", i, class, want, order)
+					}
+				}
+			},
+		},
+		{
+			name: "minimum share",
+			opts: []Option{WithClassShare("low", 0.3)},
+			check: func(t *testing.T, order []string) {
+				low := 0
+				for _, class := range order[:15] {
+					if class == "low" {
+						low++
+					}
+				}
+				if low < 3 {
+					t.Errorf("low class ran %d of the first 15 jobs, want at least 3: %v", low, order)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithPriorityClasses("high", "default", "low")}, tt.opts...)
+			d := New(1, opts...).QueueRunner().Start().Pause()
+			defer d.Stop(true)
+
+			var (
+				mu    sync.Mutex
+				order []string
+			)
+			add := func(class string, opts ...JobOption) {
+				d.Add(func() error {
+					mu.Lock()
+					order = append(order, class)
+					mu.Unlock()
+					return nil
+				}, opts...)
+			}
+			for i := 0; i < 10; i++ {
+				add("low", WithClass("low"))
+				add("default")
+				add("high", WithClass("high"))
+			}
+			deadline := time.Now().Add(time.Second)
+			// wait for the queue runner to move every job from the submission channel to the queue
+			for len(d.SampleQueue(30)) != 30 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			d.Resume()
+			d.Wait()
+			tt.check(t, order)
+
+			if s := d.ClassStats()["low"]; s.Succeeded != 10 || s.Queued != 0 {
+				t.Errorf("low class stats = %+v", s)
+			}
+		})
+	}
+}
+
+func TestWithClassLimit(t *testing.T) {
+	d := New(1, WithPriorityClasses("high", "low"), WithClassLimit("low", 2)).Pause()
+	d.Add(func() error { return nil }, WithClass("low"))
+	d.Add(func() error { return nil }, WithClass("low"))
+	if err := <-d.Add(func() error { return nil }, WithClass("low")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Add() over the class limit = %v, want %v", err, ErrQueueFull)
+	}
+	if got := d.ClassStats()["low"].Queued; got != 2 {
+		t.Errorf("queued low jobs = %d, want 2", got)
+	}
+}
@@ -0,0 +1,55 @@
+package gorker
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+)
+
+const (
+	// LabelPool is the pprof label holding the name of the Dispatcher running a job
+	LabelPool = "gorker_pool"
+	// LabelTag is the pprof label holding the comma separated tags of a job
+	LabelTag = "job_tag"
+
+	defaultName = "default"
+)
+
+// WithName names the Dispatcher, the name is reported in the gorker_pool pprof label
+func WithName(name string) Option {
+	return func(d *Dispatcher) {
+		if name != "" {
+			d.name = name
+		}
+	}
+}
+
+// WithoutProfilerLabels stops labelling the worker goroutines with the pool name and
+// job tags while jobs run, removing the cost of setting the labels on every job
+func WithoutProfilerLabels() Option {
+	return func(d *Dispatcher) {
+		d.noLabels = true
+	}
+}
+
+// Name returns the name of the Dispatcher set by WithName
+func (d *Dispatcher) Name() string {
+	return d.name
+}
+
+// profiled runs j with the pprof labels of the Dispatcher and the job, so CPU profiles
+// attribute the time spent in j to its pool and tags
+func (d *Dispatcher) profiled(ctx context.Context, j *job) (err error) {
+	if d.noLabels {
+		return d.attempt(ctx, j)
+	}
+	labels := pprof.Labels(LabelPool, d.name, LabelTag, strings.Join(j.tags, ","))
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		err = d.attempt(ctx, j)
+	})
+	// Do leaves the goroutine with the labels of ctx, the worker keeps its own
+	if scope := WorkerScopeFrom(ctx); scope != nil && scope.labels != nil {
+		pprof.SetGoroutineLabels(scope.labels)
+	}
+	return err
+}
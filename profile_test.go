@@ -0,0 +1,77 @@
+package gorker
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestDispatcher_profilerLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		tags     []string
+		wantPool string
+		wantTag  string
+		labelled bool
+	}{
+		{
+			name:     "default name",
+			wantPool: defaultName,
+			labelled: true,
+		},
+		{
+			name:     "named pool with tags",
+			opts:     []Option{WithName("mail")},
+			tags:     []string{"send", "bulk"},
+			wantPool: "mail",
+			wantTag:  "send,bulk",
+			labelled: true,
+		},
+		{
+			name: "disabled",
+			opts: []Option{WithName("mail"), WithoutProfilerLabels()},
+			tags: []string{"send"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1, tt.opts...).QueueRunner().Start()
+			defer d.Stop(true)
+
+			var pool, tag string
+			var ok bool
+			err := <-d.AddJob(func(ctx context.Context) error {
+				pool, ok = pprof.Label(ctx, LabelPool)
+				tag, _ = pprof.Label(ctx, LabelTag)
+				return nil
+			}, WithTags(tt.tags...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.labelled {
+				t.Fatalf("labelled = %v, want %v", ok, tt.labelled)
+			}
+			if pool != tt.wantPool || tag != tt.wantTag {
+				t.Errorf("labels = %q %q, want %q %q", pool, tag, tt.wantPool, tt.wantTag)
+			}
+		})
+	}
+}
+
+func benchmarkProfilerLabels(b *testing.B, opts ...Option) {
+	d := New(1, append(opts, WithSynchronous())...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		<-d.Add(func() error { return nil }, WithTags("bench"))
+	}
+}
+
+func BenchmarkProfilerLabels_Enabled(b *testing.B) {
+	benchmarkProfilerLabels(b)
+}
+
+func BenchmarkProfilerLabels_Disabled(b *testing.B) {
+	benchmarkProfilerLabels(b, WithoutProfilerLabels())
+}
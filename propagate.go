@@ -0,0 +1,80 @@
+package gorker
+
+import "context"
+
+// PropagateFunc returns the execution context of a job derived from run with the
+// values it needs from submit, the context the job was submitted with
+type PropagateFunc func(run, submit context.Context) context.Context
+
+type propagation struct {
+	keys  []any
+	funcs []PropagateFunc
+}
+
+// WithContextPropagation copies the values of keys from the context a job is submitted
+// with, see WithSubmitContext and AddContext, into the context it is executed with.
+// The values are captured on submission, the submit context is not retained.
+func WithContextPropagation(keys ...any) Option {
+	return func(d *Dispatcher) {
+		d.propagation.keys = append(d.propagation.keys, keys...)
+	}
+}
+
+// WithPropagateFunc calls fn to derive the execution context of jobs submitted with a
+// context, for values stored under unexported keys such as trace spans. The submit
+// context is retained without its cancellation until the job finished.
+func WithPropagateFunc(fn PropagateFunc) Option {
+	return func(d *Dispatcher) {
+		if fn != nil {
+			d.propagation.funcs = append(d.propagation.funcs, fn)
+		}
+	}
+}
+
+// WithSubmitContext sets the context the job is submitted with. Its cancellation does not
+// affect the job, only the values selected by WithContextPropagation and WithPropagateFunc
+// are passed on. Spilled and snapshotted jobs lose these values.
+func WithSubmitContext(ctx context.Context) JobOption {
+	return func(j *job) {
+		j.submitCtx = ctx
+	}
+}
+
+func AddContext(ctx context.Context, fn JobFunc, opts ...JobOption) chan error {
+	return instance.AddContext(ctx, fn, opts...)
+}
+
+// AddContext is AddJob with WithSubmitContext(ctx)
+func (d *Dispatcher) AddContext(ctx context.Context, fn JobFunc, opts ...JobOption) chan error {
+	return d.AddJob(fn, append(opts, WithSubmitContext(ctx))...)
+}
+
+// capture takes the propagated values from the submit context of j
+func (p *propagation) capture(j *job) {
+	ctx := j.submitCtx
+	if ctx == nil {
+		return
+	}
+	j.submitCtx = nil
+	for _, key := range p.keys {
+		if v := ctx.Value(key); v != nil {
+			j.values = append(j.values, key, v)
+		}
+	}
+	if len(p.funcs) > 0 {
+		j.submitCtx = context.WithoutCancel(ctx)
+	}
+}
+
+// inject adds the values captured for j to its execution context
+func (p *propagation) inject(ctx context.Context, j *job) context.Context {
+	for i := 0; i+1 < len(j.values); i += 2 {
+		ctx = context.WithValue(ctx, j.values[i], j.values[i+1])
+	}
+	if j.submitCtx != nil {
+		for _, fn := range p.funcs {
+			ctx = fn(ctx, j.submitCtx)
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,49 @@
+package gorker
+
+import (
+	"context"
+	"testing"
+)
+
+type propagateKey string
+
+func TestWithContextPropagation(t *testing.T) {
+	span := struct{ name string }{"span"}
+	d := New(1,
+		WithContextPropagation(propagateKey("request"), propagateKey("tenant")),
+		WithPropagateFunc(func(run, submit context.Context) context.Context {
+			return context.WithValue(run, &span, submit.Value(&span))
+		}),
+	).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ctx := context.WithValue(context.Background(), propagateKey("request"), "r1")
+	ctx = context.WithValue(ctx, propagateKey("locale"), "ja")
+	ctx = context.WithValue(ctx, &span, "s1")
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	tests := []struct {
+		key  any
+		want any
+	}{
+		{key: propagateKey("request"), want: "r1"},
+		{key: propagateKey("tenant"), want: nil},
+		{key: propagateKey("locale"), want: nil},
+		{key: &span, want: "s1"},
+	}
+	err := <-d.AddContext(ctx, func(ctx context.Context) error {
+		if ctx.Err() != nil {
+			t.Errorf("job context canceled by the submit context: %v", ctx.Err())
+		}
+		for _, tt := range tests {
+			if got := ctx.Value(tt.key); got != tt.want {
+				t.Errorf("value of %v = %v, want %v", tt.key, got, tt.want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,148 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQuarantined is returned by jobs skipped because their key is quarantined
+var ErrQuarantined = errors.New("gorker: job key is quarantined")
+
+// QuarantineConfig configures the poison pill detection of a Dispatcher
+type QuarantineConfig struct {
+	// Key returns the key failures are counted for, jobs with an empty key are not tracked
+	Key func(JobInfo) string
+	// MaxFailures is the number of consecutive failures quarantining a key, 3 by default
+	MaxFailures int
+	// Decay is how long a key stays quarantined before one job of it runs again, 1 minute
+	// by default. A failure of that job quarantines the key again, a success releases it.
+	Decay time.Duration
+	// Match selects the errors counted as failures, every error when nil
+	Match func(error) bool
+	// DeadLetter receives the jobs skipped while their key is quarantined with the last
+	// failure of the key. It is called from the worker goroutine and must not block.
+	DeadLetter func(info JobInfo, err error)
+}
+
+type quarantine struct {
+	cfg QuarantineConfig
+	mu  sync.Mutex
+	m   map[string]*poison
+}
+
+type poison struct {
+	failures int
+	last     error
+	// until is the end of the quarantine, probing is set while a job runs after it
+	until   time.Time
+	probing bool
+}
+
+// WithQuarantine skips the jobs of keys which failed repeatedly, so that a poison pill
+// does not consume workers and retry budgets forever. Retries of a job stop once its key
+// was quarantined.
+func WithQuarantine(cfg QuarantineConfig) Option {
+	return func(d *Dispatcher) {
+		if cfg.Key == nil {
+			d.quarantine = nil
+			return
+		}
+		if cfg.MaxFailures < 1 {
+			cfg.MaxFailures = 3
+		}
+		if cfg.Decay <= 0 {
+			cfg.Decay = time.Minute
+		}
+		d.quarantine = &quarantine{
+			cfg: cfg,
+			m:   make(map[string]*poison),
+		}
+	}
+}
+
+// allow reports whether a job of key may run now and otherwise the failure which quarantined key
+func (q *quarantine) allow(key string, now time.Time) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	p, ok := q.m[key]
+	if !ok || p.failures < q.cfg.MaxFailures {
+		return true, nil
+	}
+	if now.Before(p.until) || p.probing {
+		return false, p.last
+	}
+	p.probing = true
+	return true, nil
+}
+
+// record counts the result of a job of key
+func (q *quarantine) record(key string, err error, now time.Time) {
+	if err != nil && q.cfg.Match != nil && !q.cfg.Match(err) {
+		err = nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	p, ok := q.m[key]
+	if err == nil {
+		if ok {
+			delete(q.m, key)
+		}
+		return
+	}
+	if !ok {
+		p = new(poison)
+		q.m[key] = p
+	}
+	p.failures++
+	p.last = err
+	p.probing = false
+	if p.failures >= q.cfg.MaxFailures {
+		p.until = now.Add(q.cfg.Decay)
+	}
+}
+
+// release frees the probe of key taken by allow for a job which did not run
+func (q *quarantine) release(key string) {
+	q.mu.Lock()
+	if p, ok := q.m[key]; ok {
+		p.probing = false
+	}
+	q.mu.Unlock()
+}
+
+// quarantined returns the quarantined keys with the end of their quarantine
+func (q *quarantine) quarantined() map[string]time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var keys map[string]time.Time
+	for key, p := range q.m {
+		if p.failures < q.cfg.MaxFailures {
+			continue
+		}
+		if keys == nil {
+			keys = make(map[string]time.Time)
+		}
+		keys[key] = p.until
+	}
+	return keys
+}
+
+// screen checks the quarantine before j runs and returns the key of j. A job of a
+// quarantined key is handed to the dead letter hook and completed with ErrQuarantined.
+func (d *Dispatcher) screen(j *job) (string, bool) {
+	q := d.quarantine
+	key := q.cfg.Key(j.info())
+	if key == "" {
+		return "", true
+	}
+	ok, last := q.allow(key, d.clock.Now())
+	if ok {
+		return key, true
+	}
+	if q.cfg.DeadLetter != nil {
+		q.cfg.DeadLetter(j.info(), last)
+	}
+	d.finish(j, ErrQuarantined)
+	return key, false
+}
@@ -0,0 +1,92 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithQuarantine(t *testing.T) {
+	var (
+		runs, dead int32
+		errBad     = errors.New("bad record")
+	)
+	d := New(1, WithQuarantine(QuarantineConfig{
+		Key: func(info JobInfo) string {
+			return info.Tags[0]
+		},
+		MaxFailures: 2,
+		Decay:       50 * time.Millisecond,
+		DeadLetter: func(_ JobInfo, err error) {
+			if errors.Is(err, errBad) {
+				atomic.AddInt32(&dead, 1)
+			}
+		},
+	})).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := func() error {
+		atomic.AddInt32(&runs, 1)
+		return errBad
+	}
+	// the retries stop once the key is quarantined
+	if err := <-d.Add(fail, WithTags("poison"), WithRetry(10, time.Millisecond)); !errors.Is(err, ErrQuarantined) {
+		t.Fatalf("error = %v, want ErrQuarantined", err)
+	}
+	if got := atomic.LoadInt32(&runs); got != 2 {
+		t.Errorf("runs = %d, want 2", got)
+	}
+	if err := <-d.Add(fail, WithTags("poison")); !errors.Is(err, ErrQuarantined) {
+		t.Errorf("error = %v, want ErrQuarantined", err)
+	}
+	if got := atomic.LoadInt32(&dead); got != 2 {
+		t.Errorf("dead letters = %d, want 2", got)
+	}
+	if _, ok := d.Stats().Quarantined["poison"]; !ok {
+		t.Error("poison is not reported as quarantined")
+	}
+	// other keys are not affected
+	if err := <-d.Add(func() error { return nil }, WithTags("good")); err != nil {
+		t.Errorf("good job = %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := <-d.Add(func() error { return nil }, WithTags("poison")); err != nil {
+		t.Errorf("probe after the decay = %v", err)
+	}
+	if len(d.Stats().Quarantined) != 0 {
+		t.Error("successful probe did not release the key")
+	}
+}
+
+func TestWithQuarantine_BreakerProbe(t *testing.T) {
+	key := func(info JobInfo) string {
+		return info.Tags[0]
+	}
+	d := New(1,
+		WithCircuitBreaker(BreakerConfig{Key: key, FailureRate: 1, MinRequests: 1, Cooldown: 10 * time.Millisecond}),
+		WithQuarantine(QuarantineConfig{Key: key, MaxFailures: 1, Decay: 300 * time.Millisecond}),
+	).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("down")
+	if err := <-d.Add(func() error { return fail }, WithTags("g")); !errors.Is(err, fail) {
+		t.Fatalf("first job error = %v, want %v", err, fail)
+	}
+	// the breaker cooled down while the key is still quarantined
+	time.Sleep(50 * time.Millisecond)
+	if err := <-d.Add(func() error { return nil }, WithTags("g")); !errors.Is(err, ErrQuarantined) {
+		t.Fatalf("quarantined job error = %v, want %v", err, ErrQuarantined)
+	}
+	if got := d.Stats().Breakers["g"]; got != BreakerOpen {
+		t.Errorf("breaker = %s after a quarantined job, want %s", got, BreakerOpen)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if err := <-d.Add(func() error { return nil }, WithTags("g")); err != nil {
+		t.Fatalf("probe error = %v, want nil", err)
+	}
+	if got := d.Stats().Breakers["g"]; got != BreakerClosed {
+		t.Errorf("breaker = %s after a successful probe, want %s", got, BreakerClosed)
+	}
+}
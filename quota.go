@@ -0,0 +1,116 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrQuotaExceeded is returned by jobs rejected because their tenant has too many queued jobs
+var ErrQuotaExceeded = errors.New("gorker: tenant quota exceeded")
+
+type quota struct {
+	key        func(JobInfo) string
+	maxQueued  int
+	maxRunning int
+	mu         sync.Mutex
+	queued     map[string]int
+	running    map[string]int
+	parked     map[string][]*job
+}
+
+// WithQuota limits the jobs of each tenant returned by key, jobs with an empty key are exempt.
+// A submission beyond maxQueued queued jobs of its tenant fails with ErrQuotaExceeded, a job
+// beyond maxRunning running jobs of its tenant is parked until one of them finishes.
+// A limit below 1 disables that limit.
+func WithQuota(key func(JobInfo) string, maxQueued, maxRunning int) Option {
+	return func(d *Dispatcher) {
+		if key == nil {
+			return
+		}
+		d.quota = &quota{
+			key:        key,
+			maxQueued:  maxQueued,
+			maxRunning: maxRunning,
+			queued:     make(map[string]int),
+			running:    make(map[string]int),
+			parked:     make(map[string][]*job),
+		}
+	}
+}
+
+// admit counts j as queued for its tenant and reports whether the tenant is within its quota
+func (q *quota) admit(j *job) bool {
+	tenant := q.key(j.info())
+	if tenant == "" {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxQueued > 0 && q.queued[tenant] >= q.maxQueued {
+		return false
+	}
+	q.queued[tenant]++
+	j.tenant = tenant
+	return true
+}
+
+// acquire moves j from queued to running, or parks it and returns false
+func (q *quota) acquire(j *job) bool {
+	if j.tenant == "" {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxRunning > 0 && q.running[j.tenant] >= q.maxRunning {
+		q.parked[j.tenant] = append(q.parked[j.tenant], j)
+		return false
+	}
+	if !j.admitted {
+		// a job parked by a circuit breaker runs again but only left the queue once
+		q.queued[j.tenant]--
+		j.admitted = true
+	}
+	q.running[j.tenant]++
+	j.slotted = true
+	return true
+}
+
+// release frees the running slot of j and returns a parked job of the same tenant to the queue
+func (q *quota) release(d *Dispatcher, j *job) {
+	if !j.slotted {
+		return
+	}
+	j.slotted = false
+	q.mu.Lock()
+	q.running[j.tenant]--
+	var next *job
+	if parked := q.parked[j.tenant]; len(parked) > 0 {
+		next = parked[0]
+		q.parked[j.tenant] = parked[1:]
+	}
+	q.cleanup(j.tenant)
+	q.mu.Unlock()
+	if next != nil {
+		d.requeue([]*job{next})
+	}
+}
+
+// dropped forgets j which was queued but never ran
+func (q *quota) dropped(j *job) {
+	if j.tenant == "" || j.admitted {
+		return
+	}
+	q.mu.Lock()
+	q.queued[j.tenant]--
+	q.cleanup(j.tenant)
+	q.mu.Unlock()
+}
+
+// cleanup removes the counters of an idle tenant, q.mu must be held
+func (q *quota) cleanup(tenant string) {
+	if q.queued[tenant] == 0 && q.running[tenant] == 0 && len(q.parked[tenant]) == 0 {
+		delete(q.queued, tenant)
+		delete(q.running, tenant)
+		delete(q.parked, tenant)
+	}
+}
@@ -0,0 +1,66 @@
+package gorker
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithQuota(t *testing.T) {
+	tenant := func(info JobInfo) string {
+		if len(info.Tags) == 0 {
+			return ""
+		}
+		return info.Tags[0]
+	}
+	d := New(4, WithQuota(tenant, 5, 1)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	var (
+		running, peak int32
+		wg            sync.WaitGroup
+	)
+	release := make(chan struct{})
+	job := func() error {
+		defer wg.Done()
+		n := atomic.AddInt32(&running, 1)
+		if n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	echs := make([]chan error, 0, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		echs = append(echs, d.Add(job, WithTags("a")))
+	}
+	if err := <-d.Add(func() error { return nil }, WithTags("a")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("submission over the queued quota = %v, want %v", err, ErrQuotaExceeded)
+	}
+	// other tenants and exempt jobs are not limited by tenant a
+	if err := <-d.Add(func() error { return nil }, WithTags("b")); err != nil {
+		t.Error(err)
+	}
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Error(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&running); got != 1 {
+		t.Errorf("running jobs of tenant a = %d, want 1", got)
+	}
+	close(release)
+	wg.Wait()
+	for _, ech := range echs {
+		if err := <-ech; err != nil {
+			t.Error(err)
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p != 1 {
+		t.Errorf("peak running jobs of tenant a = %d, want 1", p)
+	}
+}
@@ -0,0 +1,153 @@
+package gorker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidConfig is returned by Reconfigure for options which cannot be applied
+var ErrInvalidConfig = errors.New("gorker: invalid configuration")
+
+// config are the settings of a Dispatcher which Reconfigure can change
+type config struct {
+	workers    int
+	limiter    *Limiter
+	queueLimit *queueLimit
+	maxAge     time.Duration
+	grace      time.Duration
+}
+
+func (d *Dispatcher) config() config {
+	d.mu.RLock()
+	workers := d.workerCount
+	d.mu.RUnlock()
+	return config{
+		workers:    workers,
+		limiter:    d.limiter.Load(),
+		queueLimit: d.queueLimit.Load(),
+		maxAge:     time.Duration(d.maxQueueAge.Load()),
+		grace:      d.shutdown.gracePeriod(),
+	}
+}
+
+// diff returns the names of the settings differing between c and next
+func (c config) diff(next config) []string {
+	var changed []string
+	if c.workers != next.workers {
+		changed = append(changed, "workers")
+	}
+	if c.limiter != next.limiter {
+		changed = append(changed, "rate_limit")
+	}
+	if c.queueLimit != next.queueLimit {
+		changed = append(changed, "queue_limit")
+	}
+	if c.maxAge != next.maxAge {
+		changed = append(changed, "max_queue_age")
+	}
+	if c.grace != next.grace {
+		changed = append(changed, "grace_period")
+	}
+	return changed
+}
+
+func Reconfigure(opts ...Option) error {
+	return instance.Reconfigure(opts...)
+}
+
+// Reconfigure applies opts to d while it runs. Only the worker count, see WithWorkers,
+// WithRateLimit, WithQueueLimit, WithMaxQueueAge and WithGracePeriod can be changed.
+// Options are validated first, any other option or an invalid combination fails with
+// ErrInvalidConfig and leaves d unchanged. Settings which changed are reported by an
+// EventConfigChanged event.
+func (d *Dispatcher) Reconfigure(opts ...Option) error {
+	d.reconfiguring.Lock()
+	defer d.reconfiguring.Unlock()
+
+	cur := d.config()
+	scratch := newDispatcher(cur.workers)
+	scratch.limiter.Store(cur.limiter)
+	scratch.queueLimit.Store(cur.queueLimit)
+	scratch.maxQueueAge.Store(int64(cur.maxAge))
+	scratch.shutdown.grace = cur.grace
+	for _, opt := range opts {
+		opt(scratch)
+	}
+	if err := scratch.reloadable(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	next := scratch.config()
+	changed := cur.diff(next)
+	if len(changed) == 0 {
+		return nil
+	}
+	if next.workers != cur.workers {
+		if d.onDemand == nil && (d.schedule != nil || d.burst != nil || d.adaptive != nil) {
+			return fmt.Errorf("%w: the worker count is managed by a scaling controller", ErrInvalidConfig)
+		}
+	}
+	if l := next.queueLimit; l != nil && l.policy == OverflowBlock && d.synchronous {
+		return fmt.Errorf("%w: a blocking queue limit never drains in synchronous mode", ErrInvalidConfig)
+	}
+
+	d.limiter.Store(next.limiter)
+	d.queueLimit.Store(next.queueLimit)
+	d.maxQueueAge.Store(int64(next.maxAge))
+	d.shutdown.mu.Lock()
+	d.shutdown.grace = next.grace
+	d.shutdown.mu.Unlock()
+	switch {
+	case next.workers > cur.workers:
+		d.upScale(next.workers, ScaleReasonReconfigure)
+	case next.workers < cur.workers:
+		d.downScale(next.workers, ScaleReasonReconfigure)
+	}
+	d.events.emit(Event{
+		Type:    EventConfigChanged,
+		Changed: changed,
+	})
+	return nil
+}
+
+// reloadable returns an error when an option applied to d, a scratch Dispatcher created by
+// Reconfigure, configured a setting which cannot change while a Dispatcher runs
+func (d *Dispatcher) reloadable() error {
+	fixed := []struct {
+		name string
+		set  bool
+	}{
+		{"on demand workers", d.onDemand != nil},
+		{"memory guard", d.memGuard != nil},
+		{"budget", d.budget != nil},
+		{"circuit breaker", d.breakers != nil},
+		{"quota", d.quota != nil},
+		{"priority classes", d.classes != nil},
+		{"spill", d.spill != nil},
+		{"concurrency", d.burst != nil},
+		{"adaptive concurrency", d.adaptive != nil},
+		{"fairness", d.fairness != nil},
+		{"scale schedule", d.schedule != nil},
+		{"quarantine", d.quarantine != nil},
+		{"slo", d.slo != nil},
+		{"load shedding", d.shedder != nil},
+		{"stats reporter", d.reporter != nil},
+		{"inline threshold", d.inline != nil},
+		{"event trail", d.trail != nil},
+		{"logger", d.logger != nil},
+		{"worker init", d.workerInit != nil},
+		{"affinity", d.affinity != nil},
+		{"history", len(d.history) > 0},
+		{"synchronous", d.synchronous},
+		{"earliest deadline first", d.edf},
+		{"direct dispatch", d.direct},
+		{"name", d.name != defaultName},
+		{"profiler labels", d.noLabels},
+	}
+	for _, f := range fixed {
+		if f.set {
+			return fmt.Errorf("%s cannot be changed by Reconfigure", f.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package gorker
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Reconfigure(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Kill()
+
+	var changed [][]string
+	d.OnEvent(func(ev Event) {
+		if ev.Type == EventConfigChanged {
+			changed = append(changed, ev.Changed)
+		}
+	})
+
+	l := NewLimiter(100, 1)
+	err := d.Reconfigure(
+		WithWorkers(4),
+		WithRateLimit(l),
+		WithQueueLimit(10, OverflowReject),
+		WithMaxQueueAge(time.Minute),
+		WithGracePeriod(time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.GetWorkerCount(); got != 4 {
+		t.Errorf("workers = %d, want 4", got)
+	}
+	if d.limiter.Load() != l || d.queueLimit.Load().max != 10 || d.maxQueueAge.Load() != int64(time.Minute) || d.shutdown.gracePeriod() != time.Second {
+		t.Error("settings were not applied")
+	}
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"workers", "rate_limit", "queue_limit", "max_queue_age", "grace_period"}
+	if len(changed) != 1 || !slices.Equal(changed[0], want) {
+		t.Errorf("changed = %v, want [%v]", changed, want)
+	}
+
+	// options left out keep their value
+	if err := d.Reconfigure(WithWorkers(1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.GetWorkerCount(); got != 1 || d.limiter.Load() != l {
+		t.Errorf("workers = %d and limiter kept = %v", got, d.limiter.Load() == l)
+	}
+	if err := d.Reconfigure(WithWorkers(1)); err != nil || len(changed) != 2 {
+		t.Errorf("unchanged Reconfigure = %v with %d events, want no event", err, len(changed))
+	}
+}
+
+func TestDispatcher_Reconfigure_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *Dispatcher
+		opts []Option
+	}{
+		{
+			name: "fixed option",
+			d:    New(1),
+			opts: []Option{WithWorkers(2), WithPriorityClasses("high", "low")},
+		},
+		{
+			name: "scaling controller",
+			d:    New(1, WithConcurrency(1, 4)),
+			opts: []Option{WithWorkers(2)},
+		},
+		{
+			name: "blocking limit in synchronous mode",
+			d:    New(1, WithSynchronous()),
+			opts: []Option{WithQueueLimit(1, OverflowBlock)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.d.Reconfigure(tt.opts...); !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Reconfigure = %v, want ErrInvalidConfig", err)
+			}
+			if got := tt.d.GetWorkerCount(); got != 1 {
+				t.Errorf("workers = %d after failed Reconfigure, want 1", got)
+			}
+			if tt.d.queueLimit.Load() != nil {
+				t.Error("queue limit applied by failed Reconfigure")
+			}
+		})
+	}
+}
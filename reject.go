@@ -0,0 +1,68 @@
+package gorker
+
+import "errors"
+
+// RejectionReason is why a job was completed without running
+type RejectionReason string
+
+const (
+	RejectedQueueFull RejectionReason = "queue_full"
+	RejectedStopped   RejectionReason = "stopped"
+	RejectedDraining  RejectionReason = "draining"
+	RejectedQuota     RejectionReason = "quota_exceeded"
+	RejectedExpired   RejectionReason = "expired"
+	RejectedShed      RejectionReason = "shed"
+)
+
+var (
+	// ErrPoolStopped is ErrStopped
+	ErrPoolStopped = ErrStopped
+	// ErrDraining is returned by jobs submitted to a draining Dispatcher, they also match ErrStopped
+	ErrDraining = errors.New("gorker: dispatcher is draining")
+	// ErrJobTimeout is matched by errors.Is for ErrAttemptTimeout, ErrTotalTimeout and
+	// ErrTimeBudgetExceeded
+	ErrJobTimeout = errors.New("gorker: job timed out")
+)
+
+// RejectionError is returned by jobs which were rejected on submission or expired in the
+// queue, errors.Is matches it with the sentinel error of its reason
+type RejectionError struct {
+	Reason RejectionReason
+	Job    JobID
+	Err    error
+}
+
+func (e *RejectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RejectionError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrStopped for draining rejections, which used to be reported as stopped
+func (e *RejectionError) Is(target error) bool {
+	return e.Reason == RejectedDraining && target == ErrStopped
+}
+
+// reject completes j which did not run with a RejectionError
+func (d *Dispatcher) reject(j *job, reason RejectionReason, err error) {
+	d.complete(j, &RejectionError{
+		Reason: reason,
+		Job:    j.id,
+		Err:    err,
+	})
+}
+
+// timeoutError is a sentinel error matching ErrJobTimeout
+type timeoutError struct {
+	msg string
+}
+
+func (e *timeoutError) Error() string {
+	return e.msg
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrJobTimeout
+}
@@ -0,0 +1,112 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRejectionError(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		submit  func(t *testing.T, d *Dispatcher) chan error
+		reason  RejectionReason
+		matches []error
+	}{
+		{
+			name: "stopped",
+			submit: func(t *testing.T, d *Dispatcher) chan error {
+				d.Start().Stop(true)
+				return d.Add(func() error { return nil })
+			},
+			reason:  RejectedStopped,
+			matches: []error{ErrStopped, ErrPoolStopped},
+		},
+		{
+			name: "draining",
+			submit: func(t *testing.T, d *Dispatcher) chan error {
+				block := make(chan struct{})
+				d.Start().Add(func() error {
+					<-block
+					return nil
+				})
+				go d.Stop(false)
+				for d.State() != StateDraining {
+					time.Sleep(time.Millisecond)
+				}
+				defer close(block)
+				return d.Add(func() error { return nil })
+			},
+			reason:  RejectedDraining,
+			matches: []error{ErrDraining, ErrStopped},
+		},
+		{
+			name: "queue full",
+			opts: []Option{WithQueueLimit(1, OverflowReject)},
+			submit: func(t *testing.T, d *Dispatcher) chan error {
+				d.Add(func() error { return nil })
+				return d.Add(func() error { return nil })
+			},
+			reason:  RejectedQueueFull,
+			matches: []error{ErrQueueFull},
+		},
+		{
+			name: "quota",
+			opts: []Option{WithQuota(func(JobInfo) string { return "tenant" }, 1, 0)},
+			submit: func(t *testing.T, d *Dispatcher) chan error {
+				d.Add(func() error { return nil })
+				return d.Add(func() error { return nil })
+			},
+			reason:  RejectedQuota,
+			matches: []error{ErrQuotaExceeded},
+		},
+		{
+			name: "expired",
+			opts: []Option{WithMaxQueueAge(time.Millisecond)},
+			submit: func(t *testing.T, d *Dispatcher) chan error {
+				ech := d.Add(func() error { return nil })
+				time.Sleep(5 * time.Millisecond)
+				d.Start()
+				return ech
+			},
+			reason:  RejectedExpired,
+			matches: []error{ErrExpired},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rejection RejectionReason
+			d := New(1, tt.opts...).OnEvent(func(ev Event) {
+				if ev.Type == EventDropped {
+					rejection = ev.Rejection
+				}
+			}).QueueRunner()
+			defer d.Stop(true)
+			err := <-tt.submit(t, d)
+			var re *RejectionError
+			if !errors.As(err, &re) || re.Reason != tt.reason || re.Job == 0 {
+				t.Fatalf("error = %#v, want a RejectionError for %s", err, tt.reason)
+			}
+			for _, target := range tt.matches {
+				if !errors.Is(err, target) {
+					t.Errorf("error %v does not match %v", err, target)
+				}
+			}
+			if rejection != tt.reason {
+				t.Errorf("event rejection = %q, want %q", rejection, tt.reason)
+			}
+		})
+	}
+}
+
+func TestErrJobTimeout(t *testing.T) {
+	for _, err := range []error{ErrAttemptTimeout, ErrTotalTimeout, ErrTimeBudgetExceeded} {
+		if !errors.Is(err, ErrJobTimeout) {
+			t.Errorf("%v does not match ErrJobTimeout", err)
+		}
+	}
+	if errors.Is(ErrExpired, ErrJobTimeout) {
+		t.Error("ErrExpired matches ErrJobTimeout")
+	}
+}
@@ -0,0 +1,62 @@
+package gorker
+
+import (
+	"context"
+	"time"
+
+	"github.com/kpango/glg"
+)
+
+var defaultReportInterval = 10 * time.Second
+
+// StatsReporter receives the Stats of a Dispatcher periodically, see WithStatsReporter.
+// elapsed is the time since the previous report, or since d was started for the first one.
+type StatsReporter interface {
+	Report(s Stats, elapsed time.Duration) error
+}
+
+// StatsReporterFunc is a StatsReporter calling a function
+type StatsReporterFunc func(s Stats, elapsed time.Duration) error
+
+// Report calls f
+func (f StatsReporterFunc) Report(s Stats, elapsed time.Duration) error {
+	return f(s, elapsed)
+}
+
+type reporter struct {
+	r        StatsReporter
+	interval time.Duration
+}
+
+// WithStatsReporter pushes the Stats of the Dispatcher to r every interval, 10s when
+// interval is not positive, from a background goroutine running while d is started.
+// Errors returned by r are logged.
+func WithStatsReporter(r StatsReporter, interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		if r == nil {
+			d.reporter = nil
+			return
+		}
+		if interval <= 0 {
+			interval = defaultReportInterval
+		}
+		d.reporter = &reporter{r: r, interval: interval}
+	}
+}
+
+func (r *reporter) run(ctx context.Context, d *Dispatcher) {
+	ticker := d.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+	last := d.clock.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			if err := r.r.Report(d.Stats(), now.Sub(last)); err != nil {
+				glg.Warnf("gorker: failed to report stats: %v", err)
+			}
+			last = now
+		}
+	}
+}
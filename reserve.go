@@ -0,0 +1,112 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrSlotUsed is returned by a Slot which was already submitted or released
+var ErrSlotUsed = errors.New("gorker: slot already used")
+
+// Slot is queue capacity reserved by Reserve, it is used by exactly one Submit or Release
+type Slot struct {
+	d    *Dispatcher
+	used *int32
+}
+
+func Reserve(ctx context.Context) (Slot, error) {
+	return instance.Reserve(ctx)
+}
+
+// Reserve acquires room for one job in the queue limited by WithQueueLimit, waiting
+// until a job finished or ctx is done. Producers reserve before building an expensive
+// job so no work is wasted when the pool is saturated. Without a queue limit it
+// returns immediately, a draining or stopped Dispatcher returns ErrStopped.
+// Reserved slots count against the queue limit until they are submitted or released.
+func (d *Dispatcher) Reserve(ctx context.Context) (Slot, error) {
+	for {
+		if !d.State().accepting() {
+			return Slot{}, ErrStopped
+		}
+		finished, reserved := d.finishedJobs(), atomic.LoadInt64(&d.reserved)
+		if d.tryReserve() {
+			return Slot{d: d, used: new(int32)}, nil
+		}
+		if err := d.waitFinished(ctx, finished, reserved); err != nil {
+			return Slot{}, err
+		}
+	}
+}
+
+// Submit adds fn to the queue using the reserved capacity, it never applies the overflow policy
+func (s Slot) Submit(fn func() error, opts ...JobOption) chan error {
+	return s.SubmitJob(func(context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+// SubmitJob adds fn to the queue using the reserved capacity, it never applies the overflow policy
+func (s Slot) SubmitJob(fn JobFunc, opts ...JobOption) chan error {
+	if !s.take() {
+		ech := make(chan error, 1)
+		ech <- ErrSlotUsed
+		return ech
+	}
+	ech := s.d.submit(&job{fn: fn, reserved: true}, opts)
+	s.d.unreserve()
+	return ech
+}
+
+// Release gives the reserved capacity back without submitting a job
+func (s Slot) Release() {
+	if s.take() {
+		s.d.unreserve()
+	}
+}
+
+func (s Slot) take() bool {
+	return s.used != nil && atomic.CompareAndSwapInt32(s.used, 0, 1)
+}
+
+// tryReserve counts a reservation when the queue limit allows one more job
+func (d *Dispatcher) tryReserve() bool {
+	l := d.queueLimit.Load()
+	for {
+		r := atomic.LoadInt64(&d.reserved)
+		if l != nil && d.queueDepth()+int(r) >= l.max {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&d.reserved, r, r+1) {
+			return true
+		}
+	}
+}
+
+func (d *Dispatcher) unreserve() {
+	atomic.AddInt64(&d.reserved, -1)
+	d.completions.notify()
+}
+
+// waitFinished blocks until more than finished jobs finished, the reservations dropped
+// below reserved or ctx is done
+func (d *Dispatcher) waitFinished(ctx context.Context, finished uint64, reserved int64) error {
+	c := d.completions
+	atomic.AddInt32(&c.waiting, 1)
+	defer atomic.AddInt32(&c.waiting, -1)
+	stop := context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		c.cond.Broadcast()
+		c.mu.Unlock()
+	})
+	defer stop()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for d.finishedJobs() <= finished && atomic.LoadInt64(&d.reserved) >= reserved {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.cond.Wait()
+	}
+	return nil
+}
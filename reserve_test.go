@@ -0,0 +1,87 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Reserve(t *testing.T) {
+	d := New(1, WithQueueLimit(2, OverflowReject)).QueueRunner().Start().Pause()
+	defer d.Stop(true)
+
+	s1, err := d.Reserve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := d.Reserve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reservations count against the limit
+	if err := <-d.Add(func() error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Add() with reserved capacity = %v, want %v", err, ErrQueueFull)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.Reserve(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Reserve() on a saturated queue = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// a released slot wakes a waiting Reserve
+	reserved := make(chan Slot)
+	go func() {
+		s, err := d.Reserve(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		reserved <- s
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s2.Release()
+	s3 := <-reserved
+
+	e1 := s1.Submit(func() error { return nil })
+	e3 := s3.Submit(func() error { return nil })
+	if err := <-s1.Submit(func() error { return nil }); !errors.Is(err, ErrSlotUsed) {
+		t.Errorf("second Submit() = %v, want %v", err, ErrSlotUsed)
+	}
+	s2.Release()
+	d.Resume()
+	for _, ech := range []chan error{e1, e3} {
+		if err := <-ech; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDispatcher_Reserve_unlimited(t *testing.T) {
+	tests := []struct {
+		name string
+		stop bool
+		want error
+	}{
+		{name: "running"},
+		{name: "stopped", stop: true, want: ErrStopped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1).QueueRunner().Start()
+			defer d.Stop(true)
+			if tt.stop {
+				d.Stop(true)
+			}
+			s, err := d.Reserve(context.Background())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Reserve() = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if err := <-s.Submit(func() error { return nil }); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
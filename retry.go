@@ -0,0 +1,150 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrAttemptTimeout is matched by errors.Is for jobs whose last attempt ran out of its
+	// per attempt timeout, see WithAttemptTimeout
+	ErrAttemptTimeout error = &timeoutError{"gorker: job attempt timed out"}
+	// ErrTotalTimeout is matched by errors.Is for jobs which ran out of their total time
+	// budget across all attempts, see WithTotalTimeout
+	ErrTotalTimeout error = &timeoutError{"gorker: job total timeout exceeded"}
+)
+
+// Attempt describes a failed attempt of a retried job, see AttemptFrom
+type Attempt struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Err        error
+}
+
+// AttemptInfo describes the attempts of the job owning a context, see AttemptFrom
+type AttemptInfo struct {
+	// Number is the current attempt, 1 for the first one
+	Number int
+	// Previous are the failed attempts before the current one, oldest first
+	Previous []Attempt
+}
+
+// AttemptFrom returns the attempts of the job owning ctx, so that a retried job can adapt
+// to the failures of its previous attempts. It returns false outside of a job.
+func AttemptFrom(ctx context.Context) (AttemptInfo, bool) {
+	s, ok := ctx.Value(jobKey{}).(jobScope)
+	if !ok {
+		return AttemptInfo{}, false
+	}
+	return AttemptInfo{
+		Number:   s.j.attempt + 1,
+		Previous: slices.Clone(s.j.attempts),
+	}, true
+}
+
+type retryPolicy struct {
+	max            int
+	backoff        time.Duration
+	attemptTimeout time.Duration
+	totalTimeout   time.Duration
+}
+
+func (j *job) retryPolicy() *retryPolicy {
+	if j.retry == nil {
+		j.retry = new(retryPolicy)
+	}
+	return j.retry
+}
+
+// WithRetry retries a failed job up to max times, waiting backoff before the first retry
+// and twice as long before each following one. The job is requeued while it waits.
+func WithRetry(max int, backoff time.Duration) JobOption {
+	return func(j *job) {
+		rp := j.retryPolicy()
+		rp.max = max
+		rp.backoff = backoff
+	}
+}
+
+// WithAttemptTimeout cancels each attempt of the job after timeout
+func WithAttemptTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.retryPolicy().attemptTimeout = timeout
+	}
+}
+
+// WithTotalTimeout bounds all attempts of the job, including the waits between them,
+// to timeout from the start of the first attempt
+func WithTotalTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.retryPolicy().totalTimeout = timeout
+	}
+}
+
+// attempt runs a single attempt of j within its timeouts and reports an exhausted budget in the error
+func (d *Dispatcher) attempt(ctx context.Context, j *job) error {
+	rp := j.retry
+	if rp == nil {
+		return j.fn(ctx)
+	}
+	if rp.totalTimeout > 0 {
+		now := d.clock.Now()
+		if j.deadline.IsZero() {
+			j.deadline = now.Add(rp.totalTimeout)
+		}
+		// the deadline is in the time of d.clock, the context gets the budget left of it
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, j.deadline.Sub(now), ErrTotalTimeout)
+		defer cancel()
+	}
+	if rp.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, rp.attemptTimeout, ErrAttemptTimeout)
+		defer cancel()
+	}
+	err := j.fn(ctx)
+	if err != nil && ctx.Err() != nil {
+		cause := context.Cause(ctx)
+		if (cause == ErrAttemptTimeout || cause == ErrTotalTimeout) && !errors.Is(err, cause) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
+	}
+	return err
+}
+
+// retry requeues j after its backoff if it has attempts and time budget left.
+// It returns false with the final error of j otherwise.
+func (d *Dispatcher) retry(j *job, err error) (bool, error) {
+	rp := j.retry
+	if err == nil || rp == nil || j.attempt >= rp.max || errors.Is(err, ErrTotalTimeout) {
+		return false, err
+	}
+	wait := rp.backoff << j.attempt
+	if !j.deadline.IsZero() && d.clock.Now().Add(wait).After(j.deadline) {
+		return false, fmt.Errorf("%w: %w", ErrTotalTimeout, err)
+	}
+	j.attempts = append(j.attempts, Attempt{
+		StartedAt:  j.started,
+		FinishedAt: d.clock.Now(),
+		Err:        err,
+	})
+	j.attempt++
+	d.tags.retried(j.tags)
+	if d.classes != nil {
+		d.classes.stats.retried(j.classTags())
+	}
+	if d.quota != nil {
+		d.quota.release(d, j)
+	}
+	if j.holding {
+		d.releaseWorker(j)
+	}
+	d.events.retried(j, err)
+	d.clock.AfterFunc(wait, func() {
+		d.requeue([]*job{j})
+	})
+	return true, nil
+}
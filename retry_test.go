@@ -0,0 +1,137 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithTotalTimeout(t *testing.T) {
+	fail := errors.New("fail")
+	tests := []struct {
+		name     string
+		opts     []JobOption
+		fn       func(attempt int32, ctx context.Context) error
+		want     []error
+		attempts int32
+	}{
+		{
+			name: "retry until success",
+			opts: []JobOption{WithRetry(3, time.Millisecond)},
+			fn: func(attempt int32, ctx context.Context) error {
+				if attempt < 3 {
+					return fail
+				}
+				return nil
+			},
+			attempts: 3,
+		},
+		{
+			name: "retries exhausted",
+			opts: []JobOption{WithRetry(2, time.Millisecond)},
+			fn: func(int32, context.Context) error {
+				return fail
+			},
+			want:     []error{fail},
+			attempts: 3,
+		},
+		{
+			name: "attempt timeout",
+			opts: []JobOption{WithRetry(1, time.Millisecond), WithAttemptTimeout(5 * time.Millisecond)},
+			fn: func(_ int32, ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			want:     []error{ErrAttemptTimeout, context.DeadlineExceeded},
+			attempts: 2,
+		},
+		{
+			name: "total timeout across attempts",
+			opts: []JobOption{WithRetry(100, time.Millisecond), WithAttemptTimeout(10 * time.Millisecond), WithTotalTimeout(30 * time.Millisecond)},
+			fn: func(_ int32, ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			want: []error{ErrTotalTimeout},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1).QueueRunner().Start()
+			defer d.Stop(true)
+
+			var attempts int32
+			retried := int32(0)
+			d.OnEvent(func(ev Event) {
+				if ev.Type == EventRetried {
+					atomic.AddInt32(&retried, 1)
+				}
+			})
+			err := <-d.AddJob(func(ctx context.Context) error {
+				return tt.fn(atomic.AddInt32(&attempts, 1), ctx)
+			}, tt.opts...)
+			if len(tt.want) == 0 && err != nil {
+				t.Errorf("error = %v, want nil", err)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("error = %v, want %v", err, want)
+				}
+			}
+			got := atomic.LoadInt32(&attempts)
+			if tt.attempts > 0 && got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+			if r := atomic.LoadInt32(&retried); r != got-1 {
+				t.Errorf("retried events = %d, want %d", r, got-1)
+			}
+			if st := d.Stats(); st.Succeeded+st.Failed != 1 {
+				t.Errorf("finished jobs = %d, want 1", st.Succeeded+st.Failed)
+			}
+		})
+	}
+}
+
+func TestAttemptFrom(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	if _, ok := AttemptFrom(context.Background()); ok {
+		t.Error("AttemptFrom outside of a job = true")
+	}
+	fail := errors.New("fail")
+	var infos []AttemptInfo
+	err := <-d.AddJob(func(ctx context.Context) error {
+		info, ok := AttemptFrom(ctx)
+		if !ok {
+			return errors.New("AttemptFrom in a job = false")
+		}
+		infos = append(infos, info)
+		if info.Number < 3 {
+			return fmt.Errorf("attempt %d: %w", info.Number, fail)
+		}
+		return nil
+	}, WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(infos))
+	}
+	for i, info := range infos {
+		if info.Number != i+1 || len(info.Previous) != i {
+			t.Fatalf("attempt %d = %+v", i+1, info)
+		}
+		for n, a := range info.Previous {
+			if !errors.Is(a.Err, fail) || a.Err.Error() != fmt.Sprintf("attempt %d: fail", n+1) {
+				t.Errorf("attempt %d previous %d error = %v", i+1, n+1, a.Err)
+			}
+			if a.StartedAt.IsZero() || a.FinishedAt.Before(a.StartedAt) {
+				t.Errorf("attempt %d previous %d timing = %v - %v", i+1, n+1, a.StartedAt, a.FinishedAt)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package gorker
+
+import "sync"
+
+var jobPool = sync.Pool{
+	New: func() any {
+		return &job{
+			ech: make(chan error, 1),
+		}
+	},
+}
+
+// Result is the pending result of a job added by AddReusing
+type Result struct {
+	j *job
+}
+
+// Wait blocks until the job finished and returns its error.
+// It must be called exactly once, the job envelope is recycled afterwards.
+func (r Result) Wait() error {
+	ech := r.j.ech
+	err := <-ech
+	*r.j = job{ech: ech}
+	jobPool.Put(r.j)
+	return err
+}
+
+func AddReusing(fn JobFunc, opts ...JobOption) Result {
+	return instance.AddReusing(fn, opts...)
+}
+
+// AddReusing adds fn like AddJob but takes the job envelope and its error channel from
+// a pool, which saves their allocations for many small jobs. The result must be
+// received with Result.Wait exactly once.
+func (d *Dispatcher) AddReusing(fn JobFunc, opts ...JobOption) Result {
+	j := jobPool.Get().(*job)
+	j.fn = fn
+	d.submit(j, opts)
+	return Result{j: j}
+}
@@ -0,0 +1,51 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDispatcher_AddReusing(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("fail")
+	for i := 0; i < 100; i++ {
+		want := error(nil)
+		if i%3 == 0 {
+			want = fail
+		}
+		res := d.AddReusing(func(context.Context) error {
+			return want
+		}, WithTags("reuse"))
+		if err := res.Wait(); err != want {
+			t.Fatalf("job %d error = %v, want %v", i, err, want)
+		}
+	}
+	if s := d.StatsByTag("reuse")["reuse"]; s.Succeeded != 66 || s.Failed != 34 {
+		t.Errorf("tag stats = %+v", s)
+	}
+}
+
+func BenchmarkDispatcher_AddJob(b *testing.B) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+	fn := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		<-d.AddJob(fn)
+	}
+}
+
+func BenchmarkDispatcher_AddReusing(b *testing.B) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+	fn := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.AddReusing(fn).Wait()
+	}
+}
@@ -0,0 +1,35 @@
+package gorker
+
+import (
+	"context"
+	"fmt"
+)
+
+func Run(ctx context.Context) error {
+	return instance.Run(ctx)
+}
+
+// Run starts d and its queue runner and blocks until ctx is done, then drains d like Flush
+// within the grace period set by WithGracePeriod and kills it when the jobs did not finish
+// in time. It returns nil once d drained, an error wrapping context.DeadlineExceeded when
+// the grace period ran out, and returns early with nil when d was stopped by other means.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	d.QueueRunner()
+	defer d.StopQueueRunner()
+	d.Start()
+	d.mu.RLock()
+	stopped := d.ctx.Done()
+	d.mu.RUnlock()
+	select {
+	case <-ctx.Done():
+	case <-stopped:
+		return nil
+	}
+	grace, cancel := context.WithTimeout(context.WithoutCancel(ctx), d.shutdown.gracePeriod())
+	defer cancel()
+	if err := d.Flush(grace); err != nil {
+		d.Kill()
+		return fmt.Errorf("gorker: drain did not complete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Run(t *testing.T) {
+	tests := []struct {
+		name  string
+		job   time.Duration
+		grace time.Duration
+		want  error
+		done  int32
+	}{
+		{name: "drained", job: 10 * time.Millisecond, grace: time.Second, done: 1},
+		{name: "grace exceeded", job: time.Second, grace: 10 * time.Millisecond, want: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1, WithGracePeriod(tt.grace))
+			ctx, cancel := context.WithCancel(context.Background())
+			errc := make(chan error, 1)
+			go func() {
+				errc <- d.Run(ctx)
+			}()
+
+			var done int32
+			started := make(chan struct{})
+			d.AddJob(func(ctx context.Context) error {
+				close(started)
+				select {
+				case <-time.After(tt.job):
+					atomic.AddInt32(&done, 1)
+				case <-ctx.Done():
+				}
+				return nil
+			})
+			<-started
+			cancel()
+			if err := <-errc; !errors.Is(err, tt.want) {
+				t.Fatalf("Run = %v, want %v", err, tt.want)
+			}
+			if got := atomic.LoadInt32(&done); got != tt.done {
+				t.Errorf("completed jobs = %d, want %d", got, tt.done)
+			}
+			if d.State() != StateStopped {
+				t.Errorf("state = %v, want stopped", d.State())
+			}
+		})
+	}
+}
+
+func TestDispatcher_Run_Killed(t *testing.T) {
+	d := New(1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- d.Run(context.Background())
+	}()
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	d.Kill()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("Run = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Kill")
+	}
+}
@@ -0,0 +1,49 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+)
+
+func RunAll(ctx context.Context, jobs ...func() error) error {
+	return instance.RunAll(ctx, jobs...)
+}
+
+// RunAll submits jobs to d and waits for them, it returns the failures joined by errors.Join
+// or nil when every job succeeded. Once ctx is done jobs which did not start yet are skipped
+// and RunAll returns without waiting for the running ones, ctx.Err() is part of the result.
+func (d *Dispatcher) RunAll(ctx context.Context, jobs ...func() error) error {
+	echs := make([]chan error, len(jobs))
+	for i, fn := range jobs {
+		fn := fn
+		echs[i] = d.Add(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn()
+		})
+	}
+	var (
+		errs    []error
+		skipped bool
+	)
+	for _, ech := range echs {
+		select {
+		case err := <-ech:
+			if err == nil {
+				continue
+			}
+			if cerr := ctx.Err(); cerr != nil && err == cerr {
+				skipped = true
+				continue
+			}
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	if skipped {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
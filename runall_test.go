@@ -0,0 +1,69 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_RunAll(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	ok := func() error { return nil }
+	tests := []struct {
+		name string
+		jobs []func() error
+		want []error
+	}{
+		{name: "no jobs"},
+		{name: "all succeed", jobs: []func() error{ok, ok, ok}},
+		{
+			name: "failures joined",
+			jobs: []func() error{ok, func() error { return errA }, ok, func() error { return errB }},
+			want: []error{errA, errB},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(2).QueueRunner().Start()
+			defer d.Stop(true)
+			err := d.RunAll(context.Background(), tt.jobs...)
+			if len(tt.want) == 0 && err != nil {
+				t.Fatalf("RunAll() = %v, want nil", err)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("RunAll() = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_RunAll_Canceled(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := 0
+	jobs := []func() error{
+		func() error {
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	}
+	for i := 0; i < 5; i++ {
+		jobs = append(jobs, func() error {
+			ran++
+			return nil
+		})
+	}
+	if err := d.RunAll(ctx, jobs...); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunAll() = %v, want %v", err, context.Canceled)
+	}
+	d.Wait()
+	if ran != 0 {
+		t.Errorf("%d jobs ran after cancellation", ran)
+	}
+}
@@ -0,0 +1,80 @@
+package gorker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrCanceled is the context cause of jobs canceled through RunningJob.Cancel
+var ErrCanceled = errors.New("gorker: job canceled")
+
+// RunningJob describes a job being run by a worker
+type RunningJob struct {
+	ID        JobID     `json:"id"`
+	Tags      []string  `json:"tags,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	cancel    context.CancelCauseFunc
+}
+
+// Cancel cancels the context of the job with ErrCanceled, the other jobs are not affected.
+// The job keeps running until it observes its context and then fails or is retried
+// like any other job returning an error.
+func (r RunningJob) Cancel() {
+	if r.cancel != nil {
+		r.cancel(ErrCanceled)
+	}
+}
+
+type runningJobs struct {
+	mu   sync.Mutex
+	jobs map[JobID]RunningJob
+}
+
+func newRunningJobs() *runningJobs {
+	return &runningJobs{
+		jobs: make(map[JobID]RunningJob),
+	}
+}
+
+func (r *runningJobs) track(j *job, cancel context.CancelCauseFunc) {
+	r.mu.Lock()
+	r.jobs[j.id] = RunningJob{
+		ID:        j.id,
+		Tags:      j.tags,
+		StartedAt: j.started,
+		cancel:    cancel,
+	}
+	r.mu.Unlock()
+}
+
+func (r *runningJobs) untrack(id JobID) {
+	r.mu.Lock()
+	delete(r.jobs, id)
+	r.mu.Unlock()
+}
+
+func Running() []RunningJob {
+	return instance.Running()
+}
+
+// Running returns the jobs being run by the workers of d, the longest running first.
+// Jobs added by Go are not listed.
+func (d *Dispatcher) Running() []RunningJob {
+	d.running.mu.Lock()
+	jobs := make([]RunningJob, 0, len(d.running.jobs))
+	for _, r := range d.running.jobs {
+		jobs = append(jobs, r)
+	}
+	d.running.mu.Unlock()
+	slices.SortFunc(jobs, func(a, b RunningJob) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return jobs
+}
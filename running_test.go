@@ -0,0 +1,47 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Running(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Kill()
+
+	started := make(chan struct{}, 2)
+	job := func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}
+	stuck := d.AddJob(job, WithTags("stuck"))
+	<-started
+	other := d.AddJob(job, WithTags("other"))
+	<-started
+
+	running := d.Running()
+	if len(running) != 2 {
+		t.Fatalf("Running = %d jobs, want 2", len(running))
+	}
+	if got := running[0].Tags; len(got) != 1 || got[0] != "stuck" {
+		t.Fatalf("longest running tags = %v, want [stuck]", got)
+	}
+	if running[0].ID == 0 || running[0].StartedAt.IsZero() {
+		t.Errorf("running job = %+v", running[0])
+	}
+	running[0].Cancel()
+	if err := <-stuck; !errors.Is(err, ErrCanceled) {
+		t.Errorf("canceled job = %v, want ErrCanceled", err)
+	}
+	select {
+	case err := <-other:
+		t.Fatalf("other job finished with %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if got := d.Running(); len(got) != 1 || got[0].ID != running[1].ID {
+		t.Errorf("Running after cancel = %+v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package gorker
+
+import (
+	"sync"
+	"time"
+)
+
+// Reasons of scaling decisions
+const (
+	ScaleReasonUpScale     = "upscale"
+	ScaleReasonDownScale   = "downscale"
+	ScaleReasonAutoScale   = "autoscale"
+	ScaleReasonReconfigure = "reconfigure"
+)
+
+// scaleHistorySize is the number of scaling decisions kept for Stats
+const scaleHistorySize = 16
+
+// ScaleDecision is a resize of the worker pool
+type ScaleDecision struct {
+	Time   time.Time `json:"time"`
+	From   int       `json:"from"`
+	To     int       `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+type scaleHistory struct {
+	mu        sync.Mutex
+	decisions []ScaleDecision
+}
+
+func (h *scaleHistory) add(sd ScaleDecision) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.decisions) == scaleHistorySize {
+		copy(h.decisions, h.decisions[1:])
+		h.decisions = h.decisions[:scaleHistorySize-1]
+	}
+	h.decisions = append(h.decisions, sd)
+}
+
+func (h *scaleHistory) list() []ScaleDecision {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.decisions) == 0 {
+		return nil
+	}
+	return append([]ScaleDecision(nil), h.decisions...)
+}
+
+func OnScale(fn func(old, new int, reason string)) *Dispatcher {
+	return instance.OnScale(fn)
+}
+
+// OnScale registers fn to be called after every resize of the worker pool by UpScale,
+// DownScale or AutoScale, including the worker observer. fn must not block.
+func (d *Dispatcher) OnScale(fn func(old, new int, reason string)) *Dispatcher {
+	return d.OnEvent(func(ev Event) {
+		if ev.Type == EventScaled {
+			fn(ev.From, ev.To, ev.Reason)
+		}
+	})
+}
+
+// scaled records a resize of the worker pool and emits its event
+func (d *Dispatcher) scaled(from, to int, reason string) {
+	if from == to {
+		return
+	}
+	now := d.clock.Now()
+	d.scaleHistory.add(ScaleDecision{
+		Time:   now,
+		From:   from,
+		To:     to,
+		Reason: reason,
+	})
+	d.events.scaled(now, from, to, reason)
+}
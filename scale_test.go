@@ -0,0 +1,53 @@
+package gorker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDispatcher_OnScale(t *testing.T) {
+	d := New(2)
+	type call struct {
+		old, new int
+		reason   string
+	}
+	var calls []call
+	d.OnScale(func(old, new int, reason string) {
+		calls = append(calls, call{old, new, reason})
+	})
+
+	d.UpScale(4)
+	d.DownScale(1)
+	d.workerCount = 3
+	d.AutoScale()
+	d.UpScale(3)
+
+	want := []call{
+		{2, 4, ScaleReasonUpScale},
+		{4, 1, ScaleReasonDownScale},
+		{1, 3, ScaleReasonAutoScale},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("OnScale calls = %v, want %v", calls, want)
+	}
+	history := d.Stats().Scaling
+	if len(history) != len(want) {
+		t.Fatalf("Stats().Scaling = %v, want %d decisions", history, len(want))
+	}
+	for i, sd := range history {
+		if sd.From != want[i].old || sd.To != want[i].new || sd.Reason != want[i].reason || sd.Time.IsZero() {
+			t.Errorf("decision %d = %+v, want %+v", i, sd, want[i])
+		}
+	}
+}
+
+func TestScaleHistory(t *testing.T) {
+	h := new(scaleHistory)
+	for i := 0; i < scaleHistorySize+5; i++ {
+		h.add(ScaleDecision{To: i})
+	}
+	got := h.list()
+	if len(got) != scaleHistorySize || got[0].To != 5 || got[len(got)-1].To != scaleHistorySize+4 {
+		t.Errorf("history = %v, want the latest %d decisions", got, scaleHistorySize)
+	}
+}
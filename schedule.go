@@ -0,0 +1,179 @@
+package gorker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScaleReasonSchedule is the reason of resizes by WithScaleSchedule
+const ScaleReasonSchedule = "schedule"
+
+// ScaleWindow is a daily time window with a target worker count, see WithScaleSchedule
+type ScaleWindow struct {
+	// Days are the weekdays the window starts on, every day when empty
+	Days []time.Weekday
+	// From and To are offsets from midnight, a window with From after To ends on the next day
+	From, To time.Duration
+	Workers  int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseScaleWindow parses a window like "Mon-Fri 09:00-18:00", "Sat,Sun 00:00-24:00"
+// or "22:00-06:00" running workers workers
+func ParseScaleWindow(spec string, workers int) (ScaleWindow, error) {
+	w := ScaleWindow{Workers: workers}
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("gorker: invalid scale window %q", spec)
+	}
+	if len(fields) == 2 {
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return w, fmt.Errorf("gorker: invalid scale window %q: %w", spec, err)
+		}
+		w.Days = days
+	}
+	from, to, ok := strings.Cut(fields[len(fields)-1], "-")
+	var err error
+	if !ok {
+		err = errors.New("missing time range")
+	}
+	if err == nil {
+		w.From, err = parseClock(from)
+	}
+	if err == nil {
+		w.To, err = parseClock(to)
+	}
+	if err != nil {
+		return w, fmt.Errorf("gorker: invalid scale window %q: %w", spec, err)
+	}
+	return w, nil
+}
+
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := weekdays[strings.ToLower(first)]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", first)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[strings.ToLower(last)]; !ok {
+				return nil, fmt.Errorf("unknown weekday %q", last)
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseClock parses HH:MM into an offset from midnight, 24:00 is the end of the day
+func parseClock(s string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// contains reports whether t, in the location of the schedule, falls into w
+func (w ScaleWindow) contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	if w.From <= w.To {
+		return w.onDay(t.Weekday()) && offset >= w.From && offset < w.To
+	}
+	// the window wraps past midnight, the morning part belongs to the window of the day before
+	if offset >= w.From {
+		return w.onDay(t.Weekday())
+	}
+	return offset < w.To && w.onDay((t.Weekday()+6)%7)
+}
+
+func (w ScaleWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+type scaleSchedule struct {
+	loc      *time.Location
+	fallback int
+	windows  []ScaleWindow
+
+	mu sync.Mutex
+	// last is the target applied last, so that manual scaling within a window is kept
+	last int
+}
+
+// WithScaleSchedule resizes the pool to the worker count of the first of windows containing
+// the current time in loc, and to fallback outside of them, time.Local is used when loc is nil.
+// A fallback below 1 keeps the worker count outside of the windows. The schedule is applied by
+// the worker observer, see StartWorkerObserver and ObserveOnce, when the target changes.
+func WithScaleSchedule(loc *time.Location, fallback int, windows ...ScaleWindow) Option {
+	return func(d *Dispatcher) {
+		if len(windows) == 0 {
+			d.schedule = nil
+			return
+		}
+		if loc == nil {
+			loc = time.Local
+		}
+		d.schedule = &scaleSchedule{
+			loc:      loc,
+			fallback: fallback,
+			windows:  append([]ScaleWindow(nil), windows...),
+		}
+	}
+}
+
+// target returns the worker count scheduled at now, 0 when there is none
+func (s *scaleSchedule) target(now time.Time) int {
+	now = now.In(s.loc)
+	for _, w := range s.windows {
+		if w.contains(now) && w.Workers > 0 {
+			return w.Workers
+		}
+	}
+	return max(s.fallback, 0)
+}
+
+// apply resizes d when the scheduled worker count changed since the last call
+func (s *scaleSchedule) apply(d *Dispatcher, now time.Time) {
+	target := s.target(now)
+	s.mu.Lock()
+	changed := target != s.last
+	s.last = target
+	s.mu.Unlock()
+	if !changed || target == 0 {
+		return
+	}
+	d.mu.RLock()
+	current := d.workerCount
+	d.mu.RUnlock()
+	if target > current {
+		d.upScale(target, ScaleReasonSchedule)
+	} else {
+		d.downScale(target, ScaleReasonSchedule)
+	}
+}
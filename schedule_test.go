@@ -0,0 +1,110 @@
+package gorker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScaleWindow(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ScaleWindow
+		wantErr bool
+	}{
+		{
+			spec: "09:00-18:00",
+			want: ScaleWindow{From: 9 * time.Hour, To: 18 * time.Hour, Workers: 5},
+		},
+		{
+			spec: "Mon-Fri 09:30-18:00",
+			want: ScaleWindow{
+				Days:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+				From:    9*time.Hour + 30*time.Minute,
+				To:      18 * time.Hour,
+				Workers: 5,
+			},
+		},
+		{
+			spec: "Fri-Sun,Wed 22:00-24:00",
+			want: ScaleWindow{
+				Days:    []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Wednesday},
+				From:    22 * time.Hour,
+				To:      24 * time.Hour,
+				Workers: 5,
+			},
+		},
+		{spec: "", wantErr: true},
+		{spec: "Someday 09:00-10:00", wantErr: true},
+		{spec: "09:00", wantErr: true},
+		{spec: "09:00-25:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseScaleWindow(tt.spec, 5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.From != tt.want.From || got.To != tt.want.To || got.Workers != tt.want.Workers || len(got.Days) != len(tt.want.Days) {
+				t.Fatalf("window = %+v, want %+v", got, tt.want)
+			}
+			for i := range got.Days {
+				if got.Days[i] != tt.want.Days[i] {
+					t.Fatalf("days = %v, want %v", got.Days, tt.want.Days)
+				}
+			}
+		})
+	}
+}
+
+func TestScaleSchedule_target(t *testing.T) {
+	business, _ := ParseScaleWindow("Mon-Fri 09:00-18:00", 50)
+	night, _ := ParseScaleWindow("Fri 22:00-06:00", 20)
+	s := &scaleSchedule{
+		loc:      time.UTC,
+		fallback: 5,
+		windows:  []ScaleWindow{business, night},
+	}
+	tests := []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{name: "monday noon", at: time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC), want: 50},
+		{name: "monday evening", at: time.Date(2026, 10, 12, 18, 0, 0, 0, time.UTC), want: 5},
+		{name: "friday night", at: time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), want: 20},
+		{name: "saturday morning", at: time.Date(2026, 10, 17, 5, 0, 0, 0, time.UTC), want: 20},
+		{name: "sunday morning", at: time.Date(2026, 10, 18, 5, 0, 0, 0, time.UTC), want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.target(tt.at); got != tt.want {
+				t.Errorf("target = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithScaleSchedule(t *testing.T) {
+	window := ScaleWindow{From: 0, To: 24 * time.Hour, Workers: 4}
+	d := New(1, WithScaleSchedule(time.UTC, 0, window))
+	var reason string
+	d.OnScale(func(_, _ int, r string) {
+		reason = r
+	})
+	d.ObserveOnce()
+	if got := len(d.workers); got != 4 {
+		t.Fatalf("workers = %d, want 4", got)
+	}
+	if reason != ScaleReasonSchedule {
+		t.Errorf("reason = %q, want %q", reason, ScaleReasonSchedule)
+	}
+	// manual scaling within the window is kept until the target changes
+	d.DownScale(2)
+	d.ObserveOnce()
+	if got := len(d.workers); got != 2 {
+		t.Errorf("workers = %d after manual scaling, want 2", got)
+	}
+}
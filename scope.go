@@ -0,0 +1,72 @@
+package gorker
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/kpango/glg"
+)
+
+// WorkerScope holds the resources owned by a single worker goroutine,
+// such as a connection to a downstream service
+type WorkerScope struct {
+	// ID identifies the worker goroutine within its Dispatcher
+	ID uint64
+	// Limiter throttles the jobs executed by this worker only,
+	// e.g. to respect the limits of the worker's connection
+	Limiter *Limiter
+	// Value is the worker scoped resource set by the worker init hook
+	Value any
+	// labels are the pprof labels of the worker goroutine, see LeakCheck
+	labels context.Context
+}
+
+type workerScopeKey struct{}
+
+// WorkerScopeFrom returns the scope of the worker executing the job owning ctx
+func WorkerScopeFrom(ctx context.Context) *WorkerScope {
+	s, _ := ctx.Value(workerScopeKey{}).(*WorkerScope)
+	return s
+}
+
+// WithWorkerInit calls fn in every worker goroutine before it executes its first job,
+// so that fn can attach a resource and a per worker Limiter to the scope
+func WithWorkerInit(fn func(ctx context.Context, scope *WorkerScope) error) Option {
+	return func(d *Dispatcher) {
+		d.workerInit = fn
+	}
+}
+
+// WithRateLimit throttles job executions of the whole Dispatcher.
+// When a worker scope also has a Limiter, the worker limiter is waited for first
+// so that global tokens are never held by a worker that is still throttled locally.
+func WithRateLimit(l *Limiter) Option {
+	return func(d *Dispatcher) {
+		d.limiter.Store(l)
+	}
+}
+
+func (d *Dispatcher) newWorkerScope(ctx context.Context) *WorkerScope {
+	scope := &WorkerScope{
+		ID: atomic.AddUint64(&d.workerSeq, 1),
+	}
+	if d.workerInit != nil {
+		if err := d.workerInit(ctx, scope); err != nil {
+			glg.Errorf("gorker: worker %d init failed: %v", scope.ID, err)
+		}
+	}
+	return scope
+}
+
+// throttle waits for the worker limiter and then for the Dispatcher limiter
+func (d *Dispatcher) throttle(ctx context.Context, scope *WorkerScope) error {
+	if scope != nil && scope.Limiter != nil {
+		if err := scope.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if l := d.limiter.Load(); l != nil {
+		return l.Wait(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	l := NewLimiter(100, 1)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("5 events at 100/s took %v, want >= 40ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewLimiter(0.001, 1).Wait(ctx); err != nil {
+		t.Errorf("Wait() with available burst = %v, want nil", err)
+	}
+	l = NewLimiter(0.001, 1)
+	l.Wait(context.Background())
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWithWorkerInit(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		scopes = make(map[uint64]string)
+	)
+	d := New(2, WithWorkerInit(func(ctx context.Context, scope *WorkerScope) error {
+		scope.Value = "conn"
+		scope.Limiter = NewLimiter(200, 1)
+		return nil
+	}), WithRateLimit(NewLimiter(1000, 10))).QueueRunner().Start()
+	defer d.Stop(true)
+
+	start := time.Now()
+	echs := make([]chan error, 0, 10)
+	for i := 0; i < 10; i++ {
+		echs = append(echs, d.AddJob(func(ctx context.Context) error {
+			scope := WorkerScopeFrom(ctx)
+			if scope == nil {
+				return errors.New("no worker scope")
+			}
+			mu.Lock()
+			scopes[scope.ID] = scope.Value.(string)
+			mu.Unlock()
+			return nil
+		}))
+	}
+	for _, ech := range echs {
+		if err := <-ech; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// each worker may run one job per 5ms after its first one
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("jobs finished in %v, worker limiters not applied", elapsed)
+	}
+	if len(scopes) == 0 || len(scopes) > 2 {
+		t.Errorf("jobs ran on %d worker scopes, want 1 or 2", len(scopes))
+	}
+}
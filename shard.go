@@ -0,0 +1,170 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"sync"
+	"time"
+)
+
+// ShardedDispatcher spreads jobs over independent Dispatchers by a hash of a job key,
+// so that submissions do not contend on the lock and queue of a single Dispatcher.
+// Jobs with the same key always run on the same shard.
+type ShardedDispatcher struct {
+	shards []*Dispatcher
+	hash   func(key string) uint64
+}
+
+// NewSharded creates shards Dispatchers of workers workers each, configured with opts.
+// The shards of a named Dispatcher are named after it with their index as a suffix.
+func NewSharded(shards, workers int, opts ...Option) *ShardedDispatcher {
+	if shards < 1 {
+		shards = 1
+	}
+	s := &ShardedDispatcher{
+		shards: make([]*Dispatcher, shards),
+		hash:   fnvHash,
+	}
+	for i := range s.shards {
+		d := New(workers, opts...)
+		d.name = fmt.Sprintf("%s-%d", d.name, i)
+		s.shards[i] = d
+	}
+	return s
+}
+
+func fnvHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// WithHash replaces the FNV-1a hash mapping job keys to shards, it must be set before
+// jobs are submitted
+func (s *ShardedDispatcher) WithHash(hash func(key string) uint64) *ShardedDispatcher {
+	if hash != nil {
+		s.hash = hash
+	}
+	return s
+}
+
+// Shard returns the Dispatcher running the jobs of key
+func (s *ShardedDispatcher) Shard(key string) *Dispatcher {
+	return s.shards[s.hash(key)%uint64(len(s.shards))]
+}
+
+// Shards returns the Dispatchers of s
+func (s *ShardedDispatcher) Shards() []*Dispatcher {
+	return append([]*Dispatcher(nil), s.shards...)
+}
+
+// Add adds fn to the shard of key
+func (s *ShardedDispatcher) Add(key string, fn func() error, opts ...JobOption) chan error {
+	return s.Shard(key).Add(fn, opts...)
+}
+
+// AddJob adds fn to the shard of key
+func (s *ShardedDispatcher) AddJob(key string, fn JobFunc, opts ...JobOption) chan error {
+	return s.Shard(key).AddJob(fn, opts...)
+}
+
+// Start starts the queue runner and the workers of every shard
+func (s *ShardedDispatcher) Start() *ShardedDispatcher {
+	for _, d := range s.shards {
+		d.QueueRunner().Start()
+	}
+	return s
+}
+
+// Wait blocks until the jobs submitted to every shard before the call completed
+func (s *ShardedDispatcher) Wait() {
+	for _, b := range s.barriers() {
+		b.Wait()
+	}
+}
+
+func (s *ShardedDispatcher) barriers() []*Barrier {
+	barriers := make([]*Barrier, len(s.shards))
+	for i, d := range s.shards {
+		barriers[i] = d.Barrier()
+	}
+	return barriers
+}
+
+// Drain stops intake on every shard at once and flushes them concurrently, see Flush.
+// It returns the errors of the shards which did not drain before ctx was done.
+func (s *ShardedDispatcher) Drain(ctx context.Context) error {
+	for _, d := range s.shards {
+		d.StopIntake()
+	}
+	errs := make([]error, len(s.shards))
+	wg := new(sync.WaitGroup)
+	for i, d := range s.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.Flush(ctx); err != nil {
+				errs[i] = fmt.Errorf("shard %d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Stop kills every shard and stops their queue runners, see Kill
+func (s *ShardedDispatcher) Stop() *ShardedDispatcher {
+	for _, d := range s.shards {
+		d.Kill().StopQueueRunner()
+	}
+	return s
+}
+
+// Stats returns the counters of the shards summed up, Utilization is weighted by the
+// workers of each shard, the percentiles are those of the merged digests and the scaling
+// decisions are left out
+func (s *ShardedDispatcher) Stats() Stats {
+	var (
+		merged    Stats
+		busy      float64
+		wait, run Digest
+	)
+	for _, d := range s.shards {
+		wait.Merge(&d.latency.wait)
+		run.Merge(&d.latency.run)
+		st := d.Stats()
+		merged.Workers += st.Workers
+		merged.QueueDepth += st.QueueDepth
+		merged.Spilled += st.Spilled
+		merged.Running += st.Running
+		merged.Submitted += st.Submitted
+		merged.Succeeded += st.Succeeded
+		merged.Failed += st.Failed
+		merged.Detached += st.Detached
+		merged.Expired += st.Expired
+		merged.Shed += st.Shed
+		merged.BudgetViolations += st.BudgetViolations
+		busy += st.Utilization * float64(st.Workers)
+		if len(st.Breakers) > 0 {
+			if merged.Breakers == nil {
+				merged.Breakers = make(map[string]BreakerState)
+			}
+			maps.Copy(merged.Breakers, st.Breakers)
+		}
+		if len(st.Quarantined) > 0 {
+			if merged.Quarantined == nil {
+				merged.Quarantined = make(map[string]time.Time)
+			}
+			maps.Copy(merged.Quarantined, st.Quarantined)
+		}
+	}
+	if merged.Workers > 0 {
+		merged.Utilization = busy / float64(merged.Workers)
+	}
+	merged.QueueWait = wait.Percentiles()
+	merged.Duration = run.Percentiles()
+	return merged
+}
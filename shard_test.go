@@ -0,0 +1,76 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedDispatcher(t *testing.T) {
+	s := NewSharded(4, 2, WithName("sharded")).Start()
+	defer s.Stop()
+
+	var (
+		mu     sync.Mutex
+		shards = make(map[string]*Dispatcher)
+	)
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i % 10)
+		s.Add(key, func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			if d, ok := shards[key]; ok && d != s.Shard(key) {
+				t.Errorf("key %s ran on two shards", key)
+			}
+			shards[key] = s.Shard(key)
+			return nil
+		})
+	}
+	s.Wait()
+
+	st := s.Stats()
+	if st.Workers != 8 || st.Submitted != 100 || st.Succeeded != 100 {
+		t.Errorf("stats = %+v", st)
+	}
+	used := make(map[*Dispatcher]bool)
+	for _, d := range shards {
+		used[d] = true
+	}
+	if len(used) < 2 {
+		t.Errorf("10 keys used %d shards", len(used))
+	}
+	if got := s.Shards()[3].name; got != "sharded-3" {
+		t.Errorf("shard name = %q", got)
+	}
+}
+
+func TestShardedDispatcher_Drain(t *testing.T) {
+	s := NewSharded(2, 1).Start()
+	defer s.Stop()
+
+	block := make(chan struct{})
+	s.Add("a", func() error {
+		<-block
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain = %v, want DeadlineExceeded", err)
+	}
+	if err := <-s.Add("a", func() error { return nil }); !errors.Is(err, ErrDraining) {
+		t.Errorf("Add while draining = %v, want ErrDraining", err)
+	}
+	close(block)
+	if err := s.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range s.Shards() {
+		if d.State() != StateStopped {
+			t.Errorf("shard state = %v, want stopped", d.State())
+		}
+	}
+}
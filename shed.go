@@ -0,0 +1,96 @@
+package gorker
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShed is returned by jobs dropped on submission by load shedding, see WithLoadShedding
+var ErrShed = errors.New("gorker: job shed under load")
+
+var defaultShedInterval = 100 * time.Millisecond
+
+// SheddingPolicy configures WithLoadShedding
+type SheddingPolicy struct {
+	// Target is the queue wait tolerated by the Dispatcher
+	Target time.Duration
+	// Interval is how long the queue wait has to stay above Target before jobs are shed,
+	// 100ms by default
+	Interval time.Duration
+	// Sheddable reports whether a job may be shed. By default every job but those of the
+	// highest priority class may be shed.
+	Sheddable func(JobInfo) bool
+}
+
+type shedder struct {
+	policy SheddingPolicy
+	mu     sync.Mutex
+	// above is when the queue wait went above the target, zero while it is below
+	above time.Time
+	// count is the number of escalations since shedding started, next the earliest next one
+	count int
+	next  time.Time
+	// prob is the probability of shedding a sheddable submission, as float64 bits
+	prob uint64
+	shed uint64
+}
+
+// WithLoadShedding drops sheddable submissions with ErrShed when the time jobs wait in the
+// queue stays above policy.Target, following the control law of CoDel: once the wait was
+// above the target for an interval submissions are shed with a probability growing with
+// the square root of the number of intervals the overload lasted, until a job waited less
+// than the target again. Shed jobs are counted in Stats().Shed.
+func WithLoadShedding(policy SheddingPolicy) Option {
+	return func(d *Dispatcher) {
+		if policy.Target <= 0 {
+			d.shedder = nil
+			return
+		}
+		if policy.Interval <= 0 {
+			policy.Interval = defaultShedInterval
+		}
+		if policy.Sheddable == nil {
+			policy.Sheddable = func(info JobInfo) bool {
+				return info.Class == "" || info.Priority > 0
+			}
+		}
+		d.shedder = &shedder{policy: policy}
+	}
+}
+
+// observe accounts the queue wait of a job started at now
+func (s *shedder) observe(wait time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if wait < s.policy.Target {
+		s.above = time.Time{}
+		s.count = 0
+		atomic.StoreUint64(&s.prob, 0)
+		return
+	}
+	if s.above.IsZero() {
+		s.above = now
+		s.next = now.Add(s.policy.Interval)
+		return
+	}
+	if now.Before(s.next) {
+		return
+	}
+	s.count++
+	s.next = now.Add(time.Duration(float64(s.policy.Interval) / math.Sqrt(float64(s.count))))
+	atomic.StoreUint64(&s.prob, math.Float64bits(1-1/math.Sqrt(float64(s.count+1))))
+}
+
+// drop reports whether j is shed
+func (s *shedder) drop(j *job) bool {
+	p := math.Float64frombits(atomic.LoadUint64(&s.prob))
+	if p <= 0 || !s.policy.Sheddable(j.info()) || rand.Float64() >= p {
+		return false
+	}
+	atomic.AddUint64(&s.shed, 1)
+	return true
+}
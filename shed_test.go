@@ -0,0 +1,75 @@
+package gorker
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShedder_observe(t *testing.T) {
+	s := &shedder{policy: SheddingPolicy{Target: 10 * time.Millisecond, Interval: 100 * time.Millisecond}}
+	prob := func() float64 {
+		return math.Float64frombits(atomic.LoadUint64(&s.prob))
+	}
+	start := time.Unix(0, 0)
+	tests := []struct {
+		name string
+		wait time.Duration
+		at   time.Duration
+		want float64
+	}{
+		{name: "below target", wait: time.Millisecond, at: 0, want: 0},
+		{name: "above target", wait: 20 * time.Millisecond, at: 10 * time.Millisecond, want: 0},
+		{name: "within interval", wait: 20 * time.Millisecond, at: 50 * time.Millisecond, want: 0},
+		{name: "after interval", wait: 20 * time.Millisecond, at: 110 * time.Millisecond, want: 1 - 1/math.Sqrt2},
+		{name: "before next", wait: 20 * time.Millisecond, at: 150 * time.Millisecond, want: 1 - 1/math.Sqrt2},
+		{name: "escalates", wait: 20 * time.Millisecond, at: 210 * time.Millisecond, want: 1 - 1/math.Sqrt(3)},
+		{name: "recovers", wait: 5 * time.Millisecond, at: 220 * time.Millisecond, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.observe(tt.wait, start.Add(tt.at))
+			if got := prob(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("probability = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLoadShedding(t *testing.T) {
+	d := New(1, WithPriorityClasses("high", "low"), WithLoadShedding(SheddingPolicy{Target: time.Millisecond})).QueueRunner().Start()
+	defer d.Stop(true)
+	atomic.StoreUint64(&d.shedder.prob, math.Float64bits(1))
+
+	err := <-d.Add(func() error { return nil }, WithClass("low"))
+	if !errors.Is(err, ErrShed) {
+		t.Fatalf("low priority error = %v, want ErrShed", err)
+	}
+	var rerr *RejectionError
+	if !errors.As(err, &rerr) || rerr.Reason != RejectedShed {
+		t.Errorf("error = %v, want a RejectionError with reason %q", err, RejectedShed)
+	}
+	if err := <-d.Add(func() error { return nil }, WithClass("high")); err != nil {
+		t.Errorf("high priority error = %v, want nil", err)
+	}
+	if got := d.Stats().Shed; got != 1 {
+		t.Errorf("Shed = %d, want 1", got)
+	}
+}
+
+func TestWithLoadShedding_Recovers(t *testing.T) {
+	d := New(1, WithLoadShedding(SheddingPolicy{Target: time.Hour})).QueueRunner().Start()
+	defer d.Stop(true)
+	atomic.StoreUint64(&d.shedder.prob, math.Float64bits(1))
+
+	if err := <-d.Add(func() error { return nil }); !errors.Is(err, ErrShed) {
+		t.Fatalf("error = %v, want ErrShed", err)
+	}
+	// a job waiting less than the target stops the shedding
+	d.shedder.observe(0, time.Now())
+	if err := <-d.Add(func() error { return nil }); err != nil {
+		t.Errorf("error = %v, want nil", err)
+	}
+}
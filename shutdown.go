@@ -0,0 +1,125 @@
+package gorker
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var defaultGracePeriod = 30 * time.Second
+
+type shutdown struct {
+	mu    sync.Mutex
+	hooks []func()
+	grace time.Duration
+	once  sync.Once
+	done  chan struct{}
+	err   error
+}
+
+func newShutdown() *shutdown {
+	return &shutdown{
+		grace: defaultGracePeriod,
+		done:  make(chan struct{}),
+	}
+}
+
+// WithGracePeriod bounds how long a shutdown started by HandleSignals waits for
+// the submitted jobs before stopping immediately, the default is 30 seconds
+func WithGracePeriod(grace time.Duration) Option {
+	return func(d *Dispatcher) {
+		if grace > 0 {
+			d.shutdown.mu.Lock()
+			d.shutdown.grace = grace
+			d.shutdown.mu.Unlock()
+		}
+	}
+}
+
+func OnShutdown(fn func()) *Dispatcher {
+	return instance.OnShutdown(fn)
+}
+
+// OnShutdown registers fn to run once Shutdown drained and stopped d, hooks run in
+// registration order
+func (d *Dispatcher) OnShutdown(fn func()) *Dispatcher {
+	s := d.shutdown
+	s.mu.Lock()
+	s.hooks = append(s.hooks, fn)
+	s.mu.Unlock()
+	return d
+}
+
+func Shutdown(ctx context.Context) error {
+	return instance.Shutdown(ctx)
+}
+
+// Shutdown drains d: new jobs are rejected with ErrStopped while the submitted ones
+// finish. d is stopped when they did or when ctx is done, then the OnShutdown hooks run.
+// It returns ctx.Err() when jobs were still running. Shutdown runs once, later calls wait
+// for the first one and return its result.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	s := d.shutdown
+	s.once.Do(func() {
+		d.StopIntake()
+		s.err = d.WaitContext(ctx)
+		d.Stop(true)
+		s.mu.Lock()
+		hooks := s.hooks
+		s.mu.Unlock()
+		for _, hook := range hooks {
+			hook()
+		}
+		close(s.done)
+	})
+	<-s.done
+	return s.err
+}
+
+func (s *shutdown) gracePeriod() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.grace
+}
+
+// Done returns a channel closed once Shutdown completed
+func (d *Dispatcher) Done() <-chan struct{} {
+	return d.shutdown.done
+}
+
+func HandleSignals(signals ...os.Signal) *Dispatcher {
+	return instance.HandleSignals(signals...)
+}
+
+// HandleSignals starts Shutdown when one of signals is received, SIGTERM and SIGINT
+// when none are given. The shutdown waits for the grace period set by WithGracePeriod,
+// a second signal stops d immediately. Use Done to wait for the shutdown to complete.
+func (d *Dispatcher) HandleSignals(signals ...os.Signal) *Dispatcher {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer signal.Stop(ch)
+		select {
+		case <-ch:
+		case <-d.shutdown.done:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), d.shutdown.gracePeriod())
+		defer cancel()
+		go func() {
+			select {
+			case <-ch:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		d.Shutdown(ctx)
+	}()
+	return d
+}
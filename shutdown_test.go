@@ -0,0 +1,91 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Shutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     time.Duration
+		timeout time.Duration
+		want    error
+	}{
+		{name: "drained", job: 10 * time.Millisecond, timeout: time.Second},
+		{name: "grace period exceeded", job: time.Second, timeout: 10 * time.Millisecond, want: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1).QueueRunner().Start()
+			var hooks []int
+			d.OnShutdown(func() { hooks = append(hooks, 1) }).OnShutdown(func() { hooks = append(hooks, 2) })
+
+			ech := d.Add(func() error {
+				time.Sleep(tt.job)
+				return nil
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			if err := d.Shutdown(ctx); !errors.Is(err, tt.want) {
+				t.Errorf("Shutdown() = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil {
+				if err := <-ech; err != nil {
+					t.Error(err)
+				}
+			}
+			if got := d.State(); got != StateStopped {
+				t.Errorf("State() = %v, want %v", got, StateStopped)
+			}
+			if len(hooks) != 2 || hooks[0] != 1 || hooks[1] != 2 {
+				t.Errorf("hooks ran as %v, want [1 2]", hooks)
+			}
+			// later calls return the first result without running the hooks again
+			if err := d.Shutdown(context.Background()); !errors.Is(err, tt.want) {
+				t.Errorf("second Shutdown() = %v, want %v", err, tt.want)
+			}
+			if len(hooks) != 2 {
+				t.Errorf("hooks ran %d times", len(hooks))
+			}
+			select {
+			case <-d.Done():
+			default:
+				t.Error("Done() not closed after Shutdown")
+			}
+		})
+	}
+}
+
+func TestDispatcher_HandleSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals is not supported")
+	}
+	d := New(1, WithGracePeriod(time.Second)).QueueRunner().Start().HandleSignals(os.Interrupt)
+	ech := d.Add(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-d.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("signal did not shut down the dispatcher")
+	}
+	if err := <-ech; err != nil {
+		t.Error(err)
+	}
+	if got := d.State(); got != StateStopped {
+		t.Errorf("State() = %v, want %v", got, StateStopped)
+	}
+}
@@ -0,0 +1,134 @@
+package gorker
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	defaultSLOInterval = time.Second
+	// sloSamples bounds the queue waits kept per interval, the latest ones are kept
+	sloSamples = 1024
+)
+
+// SLOReport describes the queue waits of an interval which breached the SLO of WithSLO
+type SLOReport struct {
+	Time time.Time `json:"time"`
+	// Target is the p99 queue wait of the SLO
+	Target time.Duration `json:"target_ns"`
+	P50    time.Duration `json:"p50_ns"`
+	P99    time.Duration `json:"p99_ns"`
+	Max    time.Duration `json:"max_ns"`
+	// Samples is the number of jobs the percentiles were computed from
+	Samples    int `json:"samples"`
+	Workers    int `json:"workers"`
+	QueueDepth int `json:"queue_depth"`
+	// SuggestedWorkers scales the worker count by how far the p99 is above the target
+	SuggestedWorkers int `json:"suggested_workers"`
+}
+
+type slo struct {
+	target   time.Duration
+	interval time.Duration
+	fn       func(SLOReport)
+
+	mu    sync.Mutex
+	waits []time.Duration
+	next  int
+	seen  int
+}
+
+// WithSLO measures how long jobs wait in the queue and calls onViolation from a background
+// goroutine when the p99 wait of an interval exceeds queueWaitP99. Waits are evaluated every
+// second, see WithSLOInterval, and only the latest 1024 waits of an interval are kept.
+func WithSLO(queueWaitP99 time.Duration, onViolation func(SLOReport)) Option {
+	return func(d *Dispatcher) {
+		if queueWaitP99 <= 0 || onViolation == nil {
+			d.slo = nil
+			return
+		}
+		d.slo = &slo{
+			target:   queueWaitP99,
+			interval: defaultSLOInterval,
+			fn:       onViolation,
+			waits:    make([]time.Duration, 0, sloSamples),
+		}
+	}
+}
+
+// WithSLOInterval sets how often the SLO of WithSLO is evaluated, it must follow WithSLO
+func WithSLOInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		if d.slo != nil && interval > 0 {
+			d.slo.interval = interval
+		}
+	}
+}
+
+// dequeued records the queue wait of a job taken by a worker at now
+func (s *slo) dequeued(j *job, now time.Time) {
+	wait := now.Sub(j.enqueued)
+	s.mu.Lock()
+	if len(s.waits) < cap(s.waits) {
+		s.waits = append(s.waits, wait)
+	} else {
+		s.waits[s.next] = wait
+		s.next = (s.next + 1) % len(s.waits)
+	}
+	s.seen++
+	s.mu.Unlock()
+}
+
+func (s *slo) run(ctx context.Context, d *Dispatcher) {
+	ticker := d.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			if r, violated := s.evaluate(d, now); violated {
+				s.fn(r)
+			}
+		}
+	}
+}
+
+// evaluate computes the report of the waits recorded since the last call
+func (s *slo) evaluate(d *Dispatcher, now time.Time) (SLOReport, bool) {
+	s.mu.Lock()
+	waits := slices.Clone(s.waits)
+	seen := s.seen
+	s.waits, s.next, s.seen = s.waits[:0], 0, 0
+	s.mu.Unlock()
+	if len(waits) == 0 {
+		return SLOReport{}, false
+	}
+	slices.Sort(waits)
+	r := SLOReport{
+		Time:    now,
+		Target:  s.target,
+		P50:     percentile(waits, 0.5),
+		P99:     percentile(waits, 0.99),
+		Max:     waits[len(waits)-1],
+		Samples: seen,
+	}
+	if r.P99 <= s.target {
+		return r, false
+	}
+	d.mu.RLock()
+	r.Workers = d.workerCount
+	d.mu.RUnlock()
+	r.QueueDepth = d.queueDepth()
+	r.SuggestedWorkers = max(r.Workers+1, int(math.Ceil(float64(r.Workers)*float64(r.P99)/float64(s.target))))
+	return r, true
+}
+
+// percentile returns the nearest rank percentile p of sorted
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,80 @@
+package gorker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLO_evaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		waits     []time.Duration
+		violated  bool
+		p99       time.Duration
+		suggested int
+	}{
+		{name: "no samples"},
+		{
+			name:  "within target",
+			waits: []time.Duration{time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond},
+			p99:   5 * time.Millisecond,
+		},
+		{
+			name:      "breached",
+			waits:     []time.Duration{time.Millisecond, 10 * time.Millisecond, 30 * time.Millisecond},
+			violated:  true,
+			p99:       30 * time.Millisecond,
+			suggested: 12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(4)
+			s := &slo{target: 10 * time.Millisecond, waits: make([]time.Duration, 0, 2)}
+			now := time.Now()
+			for _, w := range tt.waits {
+				s.dequeued(&job{enqueued: now.Add(-w)}, now)
+			}
+			r, violated := s.evaluate(d, now)
+			if violated != tt.violated {
+				t.Fatalf("violated = %v, want %v", violated, tt.violated)
+			}
+			if !violated {
+				return
+			}
+			// the ring keeps the latest waits only
+			if r.P99 != tt.p99 || r.Samples != len(tt.waits) || r.Workers != 4 || r.SuggestedWorkers != tt.suggested {
+				t.Errorf("report = %+v", r)
+			}
+			if _, again := s.evaluate(d, now); again {
+				t.Error("samples were not reset")
+			}
+		})
+	}
+}
+
+func TestWithSLO(t *testing.T) {
+	reports := make(chan SLOReport, 1)
+	d := New(1, WithSLO(time.Millisecond, func(r SLOReport) {
+		select {
+		case reports <- r:
+		default:
+		}
+	}), WithSLOInterval(10*time.Millisecond)).QueueRunner().Start()
+	defer d.Stop(true)
+
+	for i := 0; i < 5; i++ {
+		d.Add(func() error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		})
+	}
+	select {
+	case r := <-reports:
+		if r.P99 <= time.Millisecond || r.SuggestedWorkers < 2 {
+			t.Errorf("report = %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no SLO violation reported")
+	}
+}
@@ -0,0 +1,94 @@
+package gorker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ErrInvalidSnapshot is returned by Restore for truncated, corrupted or unknown snapshots
+var ErrInvalidSnapshot = errors.New("gorker: invalid snapshot")
+
+// SnapshotVersion is the encoding version written by Snapshot
+const SnapshotVersion = 1
+
+var snapshotMagic = []byte("GORKERS")
+
+// snapshotHeaderSize is the magic, the version byte and the task count
+var snapshotHeaderSize = len(snapshotMagic) + 1 + 4
+
+func Snapshot() ([]byte, error) {
+	return instance.Snapshot()
+}
+
+// Snapshot encodes every queued task added by AddTask, including spilled ones, in queue order.
+// The tasks stay queued. Plain jobs cannot be serialized and are not included, neither are
+// tasks already handed to the workers, Pause d first for a consistent checkpoint.
+func (d *Dispatcher) Snapshot() ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	buf := append(make([]byte, 0, 4096), snapshotMagic...)
+	buf = append(buf, SnapshotVersion)
+	buf = binary.BigEndian.AppendUint32(buf, 0)
+	var count uint32
+	for _, j := range d.queue {
+		if j.task != nil {
+			buf = appendTask(buf, j)
+			count++
+		}
+	}
+	if d.spill != nil && d.spill.pending > 0 {
+		spilled := make([]byte, d.spill.woff-d.spill.roff)
+		if _, err := d.spill.file.ReadAt(spilled, d.spill.roff); err != nil {
+			return nil, fmt.Errorf("gorker: failed to read spilled tasks: %w", err)
+		}
+		buf = append(buf, spilled...)
+		count += uint32(d.spill.pending)
+	}
+	binary.BigEndian.PutUint32(buf[snapshotHeaderSize-4:], count)
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf)), nil
+}
+
+func Restore(data []byte) error {
+	return instance.Restore(data)
+}
+
+// Restore enqueues the tasks of a snapshot taken by Snapshot. The snapshot is validated
+// completely first, so a truncated or corrupted snapshot enqueues nothing.
+// Restored tasks get new job IDs but keep their original enqueue time and tags.
+func (d *Dispatcher) Restore(data []byte) error {
+	if len(data) < snapshotHeaderSize+4 || !bytes.HasPrefix(data, snapshotMagic) {
+		return ErrInvalidSnapshot
+	}
+	if v := data[len(snapshotMagic)]; v != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, v)
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidSnapshot)
+	}
+	count := binary.BigEndian.Uint32(body[snapshotHeaderSize-4:])
+	r := bytes.NewReader(body[snapshotHeaderSize:])
+	recs := make([]taskRecord, 0, count)
+	for i := uint32(0); i < count; i++ {
+		rec, _, err := readTask(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("%w: task %d: %v", ErrInvalidSnapshot, i, err)
+		}
+		recs = append(recs, rec)
+	}
+	if r.Len() > 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidSnapshot, r.Len())
+	}
+	for _, rec := range recs {
+		d.submit(d.newTask(rec), nil)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestDispatcher_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+	src := New(1, WithSpill(dir, 4))
+	defer src.release()
+	for i := 0; i < 10; i++ {
+		src.AddTask("record", []byte(strconv.Itoa(i)), WithTags("type:record"))
+	}
+	src.Add(func() error { return nil })
+	for i := 0; i < 11; i++ {
+		src.enqueue(<-src.qin)
+	}
+
+	data, err := src.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := src.Stats().QueueDepth + src.Stats().Spilled; got != 11 {
+		t.Errorf("queued after Snapshot = %d, want 11", got)
+	}
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	dst := New(1).Handle("record", func(ctx context.Context, payload []byte) error {
+		mu.Lock()
+		got = append(got, string(payload))
+		mu.Unlock()
+		return nil
+	}).QueueRunner().Start()
+	defer dst.Stop(true)
+
+	if err := dst.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	dst.Wait()
+	if len(got) != 10 || got[0] != "0" || got[9] != "9" {
+		t.Errorf("restored tasks = %v", got)
+	}
+}
+
+func TestDispatcher_Restore_Invalid(t *testing.T) {
+	src := New(1)
+	for i := 0; i < 3; i++ {
+		src.AddTask("record", []byte(strconv.Itoa(i)))
+		src.enqueue(<-src.qin)
+	}
+	data, err := src.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)/2] ^= 0xff
+	version := append([]byte(nil), data...)
+	version[len(snapshotMagic)] = SnapshotVersion + 1
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "garbage", data: []byte("not a snapshot at all")},
+		{name: "truncated", data: data[:len(data)-7]},
+		{name: "corrupted", data: corrupt},
+		{name: "unknown version", data: version},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1)
+			if err := d.Restore(tt.data); !errors.Is(err, ErrInvalidSnapshot) {
+				t.Errorf("Restore() = %v, want %v", err, ErrInvalidSnapshot)
+			}
+			if got := d.Stats().Submitted; got != 0 {
+				t.Errorf("submitted = %d after invalid Restore, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package gorker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kpango/glg"
+)
+
+// ErrSpillLost is returned by spilled tasks which could not be read back from disk
+var ErrSpillLost = errors.New("gorker: spilled job lost")
+
+// spill keeps serializable tasks in an append only segment file while the
+// in-memory queue is above its threshold and pages them back in as it drains.
+type spill struct {
+	dir       string
+	threshold int
+	file      *os.File
+	woff      int64
+	roff      int64
+	pending   int
+	// spilled keeps the spilled tasks without their payload, so that they keep their
+	// options when they are paged in
+	spilled map[JobID]*job
+	// lost are the tasks dropped while d.mu was held, see takeLost
+	lost []lostTask
+}
+
+type lostTask struct {
+	j   *job
+	err error
+}
+
+// WithSpill spills tasks added by AddTask to a segment file in dir while more than
+// threshold jobs are queued in memory, and pages them back in once the queue has
+// drained to half of threshold. Jobs which are not tasks always stay in memory.
+func WithSpill(dir string, threshold int) Option {
+	return func(d *Dispatcher) {
+		if threshold < 1 {
+			threshold = 1
+		}
+		d.spill = &spill{
+			dir:       dir,
+			threshold: threshold,
+			spilled:   make(map[JobID]*job),
+		}
+	}
+}
+
+// accept reports whether j has to be spilled, once anything is spilled
+// tasks keep going to disk so that they are paged in in submission order
+func (s *spill) accept(j *job, queued int) bool {
+	return j.task != nil && (s.pending > 0 || queued >= s.threshold)
+}
+
+func (s *spill) write(j *job) error {
+	if s.file == nil {
+		f, err := os.CreateTemp(s.dir, "gorker-spill-*.seg")
+		if err != nil {
+			return err
+		}
+		s.file = f
+	}
+	buf := appendTask(nil, j)
+	n, err := s.file.WriteAt(buf, s.woff)
+	s.woff += int64(n)
+	if err != nil {
+		return err
+	}
+	// the payload is on disk, read restores the task and its function
+	j.task = nil
+	j.fn = nil
+	s.spilled[j.id] = j
+	s.pending++
+	return nil
+}
+
+func (s *spill) read(d *Dispatcher) (*job, error) {
+	rec, n, err := readTask(io.NewSectionReader(s.file, s.roff, s.woff-s.roff))
+	if err != nil {
+		return nil, err
+	}
+	j, ok := s.spilled[rec.id]
+	if !ok {
+		// a damaged segment can decode to a record which was never spilled
+		return nil, fmt.Errorf("record of unknown job %d at offset %d", rec.id, s.roff)
+	}
+	s.roff += int64(n)
+	delete(s.spilled, rec.id)
+	j.task = &task{
+		name:        rec.name,
+		payload:     rec.payload,
+		contentType: rec.contentType,
+	}
+	j.fn = d.taskFunc(j.task)
+	s.pending--
+	return j, nil
+}
+
+func (s *spill) pageIn(d *Dispatcher, queue []*job) []*job {
+	for s.pending > 0 && len(queue) <= s.threshold/2 {
+		j, err := s.read(d)
+		if err != nil {
+			glg.Errorf("gorker: failed to read spilled job: %v", err)
+			s.drop(err)
+			break
+		}
+		queue = d.push(queue, j)
+	}
+	if s.pending == 0 && s.woff > 0 {
+		s.woff, s.roff = 0, 0
+		s.file.Truncate(0)
+	}
+	return queue
+}
+
+// drop removes every spilled task which can no longer be read back, d.mu must be held.
+// They are completed by failLost once d.mu was released, as completion runs user hooks.
+func (s *spill) drop(err error) {
+	for id, j := range s.spilled {
+		s.lost = append(s.lost, lostTask{j: j, err: fmt.Errorf("%w: %v", ErrSpillLost, err)})
+		delete(s.spilled, id)
+	}
+	s.pending = 0
+}
+
+// takeLost removes the tasks dropped by drop, d.mu must be held
+func (s *spill) takeLost() []lostTask {
+	lost := s.lost
+	s.lost = nil
+	return lost
+}
+
+// failLost completes the tasks returned by takeLost, d.mu must not be held
+func (d *Dispatcher) failLost(lost []lostTask) {
+	for _, l := range lost {
+		d.complete(l.j, l.err)
+	}
+}
+
+func (s *spill) close() {
+	if s.file == nil {
+		return
+	}
+	name := s.file.Name()
+	s.file.Close()
+	os.Remove(name)
+	s.file = nil
+}
@@ -0,0 +1,175 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithSpill(t *testing.T) {
+	d := New(1, WithSpill(t.TempDir(), 4))
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	d.Handle("record", func(ctx context.Context, payload []byte) error {
+		mu.Lock()
+		got = append(got, string(payload))
+		mu.Unlock()
+		return nil
+	})
+
+	block := make(chan struct{})
+	d.QueueRunner().Start()
+	defer d.Stop(true)
+	d.Add(func() error {
+		<-block
+		return nil
+	})
+
+	// more tasks than the qout buffer holds so that the queue overflows
+	const n = 300
+	echs := make([]chan error, 0, n)
+	for i := 0; i < n; i++ {
+		echs = append(echs, d.AddTask("record", []byte(strconv.Itoa(i))))
+	}
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Spilled == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if d.Stats().Spilled == 0 {
+		t.Error("no task was spilled")
+	}
+	close(block)
+
+	for i, ech := range echs {
+		if err := <-ech; err != nil {
+			t.Errorf("task %d error = %v", i, err)
+		}
+	}
+	if len(got) != n {
+		t.Fatalf("executed %d tasks, want %d", len(got), n)
+	}
+	for i, v := range got {
+		if v != strconv.Itoa(i) {
+			t.Fatalf("task %d payload = %s, want %d", i, v, i)
+		}
+	}
+	if s := d.Stats().Spilled; s != 0 {
+		t.Errorf("Spilled = %d after drain, want 0", s)
+	}
+}
+
+func TestDispatcher_AddTask_UnknownHandler(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	if err := <-d.AddTask("missing", nil); err == nil {
+		t.Error("AddTask() with unknown handler succeeded")
+	}
+}
+
+func TestWithSpill_KeepsOptions(t *testing.T) {
+	d := New(1, WithSpill(t.TempDir(), 1))
+	var attempts sync.Map
+	d.Handle("flaky", func(ctx context.Context, payload []byte) error {
+		if _, loaded := attempts.LoadOrStore(string(payload), true); !loaded {
+			return errors.New("first attempt")
+		}
+		return nil
+	})
+
+	block := make(chan struct{})
+	d.QueueRunner().Start()
+	defer d.Stop(true)
+	d.Add(func() error {
+		<-block
+		return nil
+	})
+
+	const n = 300
+	var done atomic.Int32
+	echs := make([]chan error, 0, n)
+	for i := 0; i < n; i++ {
+		echs = append(echs, d.AddTask("flaky", []byte(strconv.Itoa(i)),
+			WithRetry(1, time.Millisecond),
+			onDone(func(error) { done.Add(1) })))
+	}
+	waitSpilled(t, d)
+	close(block)
+
+	for i, ech := range echs {
+		if err := <-ech; err != nil {
+			t.Errorf("task %d error = %v, want the retry to succeed", i, err)
+		}
+	}
+	if got := done.Load(); got != n {
+		t.Errorf("done hooks = %d, want %d", got, n)
+	}
+}
+
+func TestWithSpill_Lost(t *testing.T) {
+	d := New(1, WithSpill(t.TempDir(), 1))
+	d.Handle("noop", func(context.Context, []byte) error { return nil })
+
+	block := make(chan struct{})
+	d.QueueRunner().Start()
+	defer d.Stop(true)
+	d.Add(func() error {
+		<-block
+		return nil
+	})
+
+	const n = 300
+	echs := make([]chan error, 0, n)
+	for i := 0; i < n; i++ {
+		echs = append(echs, d.AddTask("noop", nil))
+	}
+	// the hooks of lost tasks call back into the Dispatcher, which must not be locked
+	d.OnEvent(func(e Event) {
+		if errors.Is(e.Err, ErrSpillLost) {
+			d.Stats()
+		}
+	})
+	// truncate once every task is queued or spilled, later writes would leave a hole
+	deadline := time.Now().Add(time.Second)
+	for st := d.Stats(); st.Spilled+st.QueueDepth < n && time.Now().Before(deadline); st = d.Stats() {
+		time.Sleep(time.Millisecond)
+	}
+	waitSpilled(t, d)
+	d.mu.Lock()
+	d.spill.file.Truncate(0)
+	d.mu.Unlock()
+	close(block)
+
+	lost := 0
+	for _, ech := range echs {
+		select {
+		case err := <-ech:
+			if errors.Is(err, ErrSpillLost) {
+				lost++
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("task did not complete")
+		}
+	}
+	if lost == 0 {
+		t.Error("no spilled task was reported lost")
+	}
+}
+
+func waitSpilled(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Spilled == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if d.Stats().Spilled == 0 {
+		t.Fatal("no task was spilled")
+	}
+}
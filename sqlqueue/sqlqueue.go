@@ -0,0 +1,366 @@
+// Package sqlqueue stores the tasks of a gorker Dispatcher in a SQL table, so that a fleet
+// of processes shares a durable queue.
+//
+// Tasks are claimed with SELECT ... FOR UPDATE SKIP LOCKED, which PostgreSQL 9.5 and
+// MySQL 8 support, and stay invisible to other claimers for the visibility timeout.
+// A task is deleted once its handler succeeded and retried with backoff otherwise
+// until it ran out of attempts, after which it is kept in the dead state. A task whose
+// claimer crashed becomes visible again once the visibility timeout passed, so handlers
+// run at least once and must tolerate repeated execution. Times are taken from the
+// clock of the claiming process, the clocks of a fleet have to be synchronized.
+//
+// The package does not import a database driver, the *sql.DB passed to New is opened
+// with the driver of the chosen Dialect. Handlers are registered on the Dispatcher with
+// Handle under the names tasks are enqueued with.
+package sqlqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kpango/glg"
+	"github.com/kpango/gorker"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultMaxInFlight  = 1000
+	defaultVisibility   = 5 * time.Minute
+	defaultMaxAttempts  = 5
+	defaultBackoff      = time.Second
+	maxBackoff          = time.Hour
+
+	statePending = "pending"
+	stateDead    = "dead"
+)
+
+// Dialect is the SQL flavor of a database
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	MySQL
+)
+
+// placeholders returns the query of d with the ? placeholders of q rewritten for d
+func (d Dialect) placeholders(q string) string {
+	if d != Postgres {
+		return q
+	}
+	var sb strings.Builder
+	n := 0
+	for _, c := range q {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&sb, "$%d", n)
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+func (d Dialect) schema(table string) string {
+	id, blob := "BIGSERIAL PRIMARY KEY", "BYTEA"
+	if d == MySQL {
+		id, blob = "BIGINT AUTO_INCREMENT PRIMARY KEY", "LONGBLOB"
+	}
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id %s,
+	name VARCHAR(255) NOT NULL,
+	payload %s,
+	state VARCHAR(16) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL,
+	run_at TIMESTAMP(6) NOT NULL,
+	locked_until TIMESTAMP(6) NULL,
+	lock_token VARCHAR(32) NULL,
+	last_error TEXT NULL
+)`, table, id, blob)
+}
+
+// Option configures a Queue
+type Option func(*Queue)
+
+// WithTable sets the table of the queue, the default is gorker_tasks
+func WithTable(table string) Option {
+	return func(q *Queue) {
+		if table != "" {
+			q.table = table
+		}
+	}
+}
+
+// WithPollInterval sets how often Run polls for due tasks while the table has none, the default is 1s
+func WithPollInterval(interval time.Duration) Option {
+	return func(q *Queue) {
+		if interval > 0 {
+			q.pollInterval = interval
+		}
+	}
+}
+
+// WithBatchSize sets how many tasks a poll claims at most, the default is 100
+func WithBatchSize(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.batchSize = n
+		}
+	}
+}
+
+// WithMaxInFlight bounds how many claimed tasks are queued or running, the default is 1000
+func WithMaxInFlight(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.maxInFlight = n
+		}
+	}
+}
+
+// WithVisibilityTimeout sets how long a claimed task is hidden from other claimers,
+// it has to exceed the time a task waits in the Dispatcher and runs. The default is 5m.
+func WithVisibilityTimeout(timeout time.Duration) Option {
+	return func(q *Queue) {
+		if timeout > 0 {
+			q.visibility = timeout
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry of a failed task, it doubles with
+// every further attempt up to an hour. The default is 1s.
+func WithBackoff(backoff time.Duration) Option {
+	return func(q *Queue) {
+		if backoff > 0 {
+			q.backoff = backoff
+		}
+	}
+}
+
+// Queue is a durable task queue in a SQL table feeding a Dispatcher
+type Queue struct {
+	db           *sql.DB
+	dialect      Dialect
+	dis          *gorker.Dispatcher
+	table        string
+	pollInterval time.Duration
+	batchSize    int
+	maxInFlight  int
+	visibility   time.Duration
+	backoff      time.Duration
+	now          func() time.Time
+}
+
+// New returns a Queue of the tasks in db for d
+func New(db *sql.DB, dialect Dialect, d *gorker.Dispatcher, opts ...Option) *Queue {
+	q := &Queue{
+		db:           db,
+		dialect:      dialect,
+		dis:          d,
+		table:        "gorker_tasks",
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+		maxInFlight:  defaultMaxInFlight,
+		visibility:   defaultVisibility,
+		backoff:      defaultBackoff,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// CreateTable creates the table of q unless it exists
+func (q *Queue) CreateTable(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, q.dialect.schema(q.table))
+	return err
+}
+
+func (q *Queue) query(format string) string {
+	return q.dialect.placeholders(fmt.Sprintf(format, q.table))
+}
+
+type enqueueConfig struct {
+	runAt       time.Time
+	maxAttempts int
+}
+
+// EnqueueOption configures a task added by Enqueue
+type EnqueueOption func(*enqueueConfig)
+
+// RunAt delays the task until t
+func RunAt(t time.Time) EnqueueOption {
+	return func(c *enqueueConfig) {
+		c.runAt = t
+	}
+}
+
+// MaxAttempts sets how often the task runs before it is dead, the default is 5
+func MaxAttempts(n int) EnqueueOption {
+	return func(c *enqueueConfig) {
+		if n > 0 {
+			c.maxAttempts = n
+		}
+	}
+}
+
+// Enqueue stores a task for the handler registered under name
+func (q *Queue) Enqueue(ctx context.Context, name string, payload []byte, opts ...EnqueueOption) error {
+	cfg := enqueueConfig{
+		runAt:       q.now(),
+		maxAttempts: defaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	_, err := q.db.ExecContext(ctx,
+		q.query("INSERT INTO %s (name, payload, state, attempts, max_attempts, run_at) VALUES (?, ?, ?, 0, ?, ?)"),
+		name, payload, statePending, cfg.maxAttempts, cfg.runAt.UTC())
+	return err
+}
+
+// claimed is a task claimed by this process
+type claimed struct {
+	id          int64
+	name        string
+	payload     []byte
+	attempts    int
+	maxAttempts int
+	token       string
+}
+
+// claim hides up to n due tasks from other claimers and returns them
+func (q *Queue) claim(ctx context.Context, n int) ([]claimed, error) {
+	now := q.now().UTC()
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, q.query("SELECT id, name, payload, attempts, max_attempts FROM %s"+
+		" WHERE state = ? AND run_at <= ? AND (locked_until IS NULL OR locked_until <= ?)"+
+		fmt.Sprintf(" ORDER BY run_at, id LIMIT %d FOR UPDATE SKIP LOCKED", n)),
+		statePending, now, now)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []claimed
+	for rows.Next() {
+		var t claimed
+		if err := rows.Scan(&t.id, &t.name, &t.payload, &t.attempts, &t.maxAttempts); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	until := now.Add(q.visibility)
+	for i := range tasks {
+		t := &tasks[i]
+		t.token = newToken()
+		t.attempts++
+		if _, err := tx.ExecContext(ctx, q.query("UPDATE %s SET locked_until = ?, lock_token = ?, attempts = ? WHERE id = ?"),
+			until, t.token, t.attempts, t.id); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, tx.Commit()
+}
+
+// settle deletes a succeeded task, or schedules the retry of a failed one
+func (q *Queue) settle(ctx context.Context, t claimed, err error) error {
+	if err == nil {
+		_, err := q.db.ExecContext(ctx, q.query("DELETE FROM %s WHERE id = ? AND lock_token = ?"), t.id, t.token)
+		return err
+	}
+	if t.attempts >= t.maxAttempts {
+		_, err := q.db.ExecContext(ctx, q.query("UPDATE %s SET state = ?, locked_until = NULL, lock_token = NULL, last_error = ? WHERE id = ? AND lock_token = ?"),
+			stateDead, err.Error(), t.id, t.token)
+		return err
+	}
+	backoff := q.backoff << (t.attempts - 1)
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	_, err = q.db.ExecContext(ctx, q.query("UPDATE %s SET run_at = ?, locked_until = NULL, lock_token = NULL, last_error = ? WHERE id = ? AND lock_token = ?"),
+		q.now().UTC().Add(backoff), err.Error(), t.id, t.token)
+	return err
+}
+
+type result struct {
+	task claimed
+	err  error
+}
+
+// Run claims due tasks and handles them on the Dispatcher until ctx is canceled or the
+// database fails, and returns the reason. Running tasks are awaited and settled before
+// Run returns.
+func (q *Queue) Run(ctx context.Context) error {
+	results := make(chan result, q.maxInFlight)
+	running := 0
+	var err error
+	// settling uses its own context so that tasks finished after ctx was canceled are recorded
+	settleCtx := context.WithoutCancel(ctx)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for err == nil || running > 0 {
+		var (
+			poll <-chan time.Time
+			done <-chan struct{}
+		)
+		if err == nil {
+			done = ctx.Done()
+			if running < q.maxInFlight {
+				poll = timer.C
+			}
+		}
+		select {
+		case <-done:
+			if err == nil {
+				err = ctx.Err()
+			}
+		case <-poll:
+			tasks, cerr := q.claim(ctx, min(q.batchSize, q.maxInFlight-running))
+			if cerr != nil {
+				err = fmt.Errorf("sqlqueue: claim: %w", cerr)
+				continue
+			}
+			for _, t := range tasks {
+				running++
+				ech := q.dis.AddTask(t.name, t.payload)
+				go func() {
+					results <- result{task: t, err: <-ech}
+				}()
+			}
+			wait := q.pollInterval
+			if len(tasks) > 0 {
+				// more tasks may be due, poll again right away
+				wait = 0
+			}
+			timer.Reset(wait)
+		case res := <-results:
+			running--
+			if serr := q.settle(settleCtx, res.task, res.err); serr != nil {
+				glg.Errorf("sqlqueue: failed to settle task %d: %v", res.task.id, serr)
+			}
+		}
+	}
+	return err
+}
+
+func newToken() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
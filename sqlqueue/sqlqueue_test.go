@@ -0,0 +1,230 @@
+package sqlqueue
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kpango/gorker"
+)
+
+// fakeDB is an in-memory table understanding the statements of Queue in the MySQL dialect
+type fakeDB struct {
+	mu   sync.Mutex
+	rows []*fakeRow
+	seq  int64
+}
+
+type fakeRow struct {
+	id          int64
+	name        string
+	payload     []byte
+	state       string
+	attempts    int64
+	maxAttempts int64
+	runAt       time.Time
+	lockedUntil time.Time
+	token       string
+	lastError   string
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (db *fakeDB) find(id int64, token string) *fakeRow {
+	for _, r := range db.rows {
+		if r.id == id && r.token == token {
+			return r
+		}
+	}
+	return nil
+}
+
+func (db *fakeDB) exec(query string, args []driver.NamedValue) (*fakeRows, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	arg := func(i int) any { return args[i].Value }
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+	case strings.HasPrefix(query, "INSERT"):
+		db.seq++
+		db.rows = append(db.rows, &fakeRow{
+			id:          db.seq,
+			name:        arg(0).(string),
+			payload:     arg(1).([]byte),
+			state:       arg(2).(string),
+			maxAttempts: arg(3).(int64),
+			runAt:       arg(4).(time.Time),
+		})
+	case strings.HasPrefix(query, "SELECT"):
+		limit, _ := strconv.Atoi(strings.Fields(query[strings.Index(query, "LIMIT"):])[1])
+		now := arg(1).(time.Time)
+		res := new(fakeRows)
+		for _, r := range db.rows {
+			if r.state == arg(0).(string) && !r.runAt.After(now) && !r.lockedUntil.After(now) && len(res.rows) < limit {
+				res.rows = append(res.rows, []driver.Value{r.id, r.name, r.payload, r.attempts, r.maxAttempts})
+			}
+		}
+		return res, nil
+	case strings.HasPrefix(query, "UPDATE gorker_tasks SET locked_until"):
+		for _, r := range db.rows {
+			if r.id == arg(3).(int64) {
+				r.lockedUntil, r.token, r.attempts = arg(0).(time.Time), arg(1).(string), arg(2).(int64)
+			}
+		}
+	case strings.HasPrefix(query, "DELETE"):
+		for i, r := range db.rows {
+			if r.id == arg(0).(int64) && r.token == arg(1).(string) {
+				db.rows = append(db.rows[:i], db.rows[i+1:]...)
+				break
+			}
+		}
+	case strings.HasPrefix(query, "UPDATE gorker_tasks SET state"):
+		if r := db.find(arg(2).(int64), arg(3).(string)); r != nil {
+			r.state, r.lastError, r.lockedUntil, r.token = arg(0).(string), arg(1).(string), time.Time{}, ""
+		}
+	case strings.HasPrefix(query, "UPDATE gorker_tasks SET run_at"):
+		if r := db.find(arg(2).(int64), arg(3).(string)); r != nil {
+			r.runAt, r.lastError, r.lockedUntil, r.token = arg(0).(time.Time), arg(1).(string), time.Time{}, ""
+		}
+	default:
+		return nil, errors.New("unexpected query " + query)
+	}
+	return new(fakeRows), nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (fakeConn) Close() error                                { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                   { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s fakeStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	_, err := s.db.exec(s.query, args)
+	return driver.RowsAffected(1), err
+}
+
+func (s fakeStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.db.exec(s.query, args)
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (*fakeRows) Columns() []string {
+	return []string{"id", "name", "payload", "attempts", "max_attempts"}
+}
+
+func (*fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestDialect_placeholders(t *testing.T) {
+	q := "UPDATE t SET a = ? WHERE id = ? AND b = ?"
+	if got, want := Postgres.placeholders(q), "UPDATE t SET a = $1 WHERE id = $2 AND b = $3"; got != want {
+		t.Errorf("Postgres = %q, want %q", got, want)
+	}
+	if got := MySQL.placeholders(q); got != q {
+		t.Errorf("MySQL = %q, want %q", got, q)
+	}
+}
+
+func TestQueue_Run(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int)
+	)
+	handler := func(ctx context.Context, payload []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		name := string(payload)
+		calls[name]++
+		if name == "bad" || (name == "flaky" && calls[name] == 1) {
+			return errors.New(name)
+		}
+		return nil
+	}
+	d := gorker.New(2).Handle("task", handler).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fake := new(fakeDB)
+	q := New(sql.OpenDB(fake), MySQL, d, WithBackoff(time.Millisecond), WithPollInterval(5*time.Millisecond))
+	ctx := context.Background()
+	if err := q.CreateTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	enqueue := []struct {
+		payload string
+		opts    []EnqueueOption
+	}{
+		{payload: "ok"},
+		{payload: "flaky"},
+		{payload: "bad", opts: []EnqueueOption{MaxAttempts(2)}},
+		{payload: "later", opts: []EnqueueOption{RunAt(time.Now().Add(time.Hour))}},
+	}
+	for _, e := range enqueue {
+		if err := q.Enqueue(ctx, "task", []byte(e.payload), e.opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if err := q.Run(rctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]int{"ok": 1, "flaky": 2, "bad": 2}
+	for name, n := range want {
+		if calls[name] != n {
+			t.Errorf("%s ran %d times, want %d", name, calls[name], n)
+		}
+	}
+	var left []string
+	for _, r := range fake.rows {
+		left = append(left, string(r.payload)+":"+r.state+":"+strconv.FormatInt(r.attempts, 10))
+	}
+	sort.Strings(left)
+	if got := strings.Join(left, ","); got != "bad:dead:2,later:pending:0" {
+		t.Errorf("rows left = %s", got)
+	}
+}
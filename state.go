@@ -0,0 +1,82 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrStopped is returned by jobs submitted to a stopped Dispatcher, and matched by
+// errors.Is for jobs submitted to a draining one, see ErrDraining
+var ErrStopped = errors.New("gorker: dispatcher is stopped")
+
+// State is the lifecycle state of a Dispatcher
+type State int32
+
+const (
+	// StateCreated is a Dispatcher which was not started yet, submitted jobs are queued
+	StateCreated State = iota
+	// StateRunning is a started Dispatcher running jobs
+	StateRunning
+	// StatePaused is a started Dispatcher which holds queued jobs back until Resume
+	StatePaused
+	// StateDraining is a Dispatcher stopping once the submitted jobs finished,
+	// it rejects new jobs with ErrDraining
+	StateDraining
+	// StateStopped is a Dispatcher stopped by Stop, it rejects new jobs with ErrStopped
+	// and keeps the queued ones until it is started again
+	StateStopped
+)
+
+var stateNames = [...]string{
+	StateCreated:  "created",
+	StateRunning:  "running",
+	StatePaused:   "paused",
+	StateDraining: "draining",
+	StateStopped:  "stopped",
+}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return "unknown"
+	}
+	return stateNames[s]
+}
+
+// active reports whether workers exist in s
+func (s State) active() bool {
+	return s == StateRunning || s == StatePaused || s == StateDraining
+}
+
+// dispatching reports whether queued jobs are handed to the workers in s
+func (s State) dispatching() bool {
+	return s == StateRunning || s == StateDraining
+}
+
+// accepting reports whether jobs can be submitted in s
+func (s State) accepting() bool {
+	return s != StateDraining && s != StateStopped
+}
+
+func GetState() State {
+	return instance.State()
+}
+
+// State returns the lifecycle state of d
+func (d *Dispatcher) State() State {
+	return State(atomic.LoadInt32(&d.state))
+}
+
+// transition moves d to the state to when it is in one of from and reports whether it did
+func (d *Dispatcher) transition(to State, from ...State) bool {
+	for _, f := range from {
+		if atomic.CompareAndSwapInt32(&d.state, int32(f), int32(to)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isScaling reports whether the worker count is being changed
+func (d *Dispatcher) isScaling() bool {
+	return atomic.LoadInt32(&d.scaling) != 0
+}
@@ -0,0 +1,78 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_State(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  func(d *Dispatcher)
+		want State
+	}{
+		{name: "created", ops: func(*Dispatcher) {}, want: StateCreated},
+		{name: "pause before start is a no-op", ops: func(d *Dispatcher) { d.Pause() }, want: StateCreated},
+		{name: "running", ops: func(d *Dispatcher) { d.Start() }, want: StateRunning},
+		{name: "paused", ops: func(d *Dispatcher) { d.Start().Pause() }, want: StatePaused},
+		{name: "resumed", ops: func(d *Dispatcher) { d.Start().Pause().Resume() }, want: StateRunning},
+		{name: "stopped", ops: func(d *Dispatcher) { d.Start().Stop(true) }, want: StateStopped},
+		{name: "stopped while paused", ops: func(d *Dispatcher) { d.Start().Pause().Stop(true) }, want: StateStopped},
+		{name: "resume after stop is a no-op", ops: func(d *Dispatcher) { d.Start().Stop(true).Resume() }, want: StateStopped},
+		{name: "restarted", ops: func(d *Dispatcher) { d.Start().Stop(true).Start() }, want: StateRunning},
+		{name: "restart keeps pause", ops: func(d *Dispatcher) { d.Start().Pause().Restart() }, want: StatePaused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1).QueueRunner()
+			defer d.Stop(true)
+			tt.ops(d)
+			if got := d.State(); got != tt.want {
+				t.Errorf("State() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Stop_Draining(t *testing.T) {
+	d := New(1).QueueRunner().Start().Pause()
+
+	block := make(chan struct{})
+	queued := d.Add(func() error {
+		<-block
+		return nil
+	})
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop(false)
+		close(stopped)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for d.State() != StateDraining && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := d.State(); got != StateDraining {
+		t.Fatalf("State() = %v, want %v", got, StateDraining)
+	}
+	if err := <-d.Add(func() error { return nil }); !errors.Is(err, ErrStopped) {
+		t.Errorf("Add() while draining = %v, want %v", err, ErrStopped)
+	}
+	// the paused queue is dispatched while draining
+	close(block)
+	if err := <-queued; err != nil {
+		t.Fatal(err)
+	}
+	<-stopped
+	if got := d.State(); got != StateStopped {
+		t.Errorf("State() = %v, want %v", got, StateStopped)
+	}
+}
+
+func TestState_String(t *testing.T) {
+	for s, want := range map[State]string{StateCreated: "created", StateDraining: "draining", State(42): "unknown"} {
+		if got := s.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package gorker
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a point in time view of a Dispatcher
+type Stats struct {
+	Workers    int    `json:"workers"`
+	QueueDepth int    `json:"queue_depth"`
+	Spilled    int    `json:"spilled"`
+	Running    int64  `json:"running"`
+	Submitted  uint64 `json:"submitted"`
+	Succeeded  uint64 `json:"succeeded"`
+	Failed     uint64 `json:"failed"`
+	Detached   uint64 `json:"detached"`
+	Expired    uint64 `json:"expired"`
+	// Shed counts the submissions dropped by WithLoadShedding
+	Shed uint64 `json:"shed"`
+	// BudgetViolations counts the jobs aborted by WithBudget
+	BudgetViolations uint64 `json:"budget_violations"`
+	// Utilization is the share of time the workers spent running jobs, see WorkerStats
+	Utilization float64 `json:"utilization"`
+	// QueueWait and Duration are the percentiles of the time jobs waited in the queue and
+	// ran since d was created, see Latencies
+	QueueWait Percentiles `json:"queue_wait"`
+	Duration  Percentiles `json:"duration"`
+
+	Breakers map[string]BreakerState `json:"breakers,omitempty"`
+	// Quarantined maps the keys quarantined by WithQuarantine to the end of their quarantine
+	Quarantined map[string]time.Time `json:"quarantined,omitempty"`
+	// Scaling are the latest resizes of the worker pool, oldest first
+	Scaling []ScaleDecision `json:"scaling,omitempty"`
+}
+
+func GetStats() Stats {
+	return instance.Stats()
+}
+
+// Stats returns the current counters of the Dispatcher
+func (d *Dispatcher) Stats() Stats {
+	d.mu.RLock()
+	workers := len(d.workers)
+	spilled := 0
+	if d.spill != nil {
+		spilled = d.spill.pending
+	}
+	d.mu.RUnlock()
+	var violations uint64
+	if d.budget != nil {
+		violations = atomic.LoadUint64(&d.budget.violations)
+	}
+	var breakers map[string]BreakerState
+	if d.breakers != nil {
+		breakers = d.breakers.states()
+	}
+	var shed uint64
+	if d.shedder != nil {
+		shed = atomic.LoadUint64(&d.shedder.shed)
+	}
+	var quarantined map[string]time.Time
+	if d.quarantine != nil {
+		quarantined = d.quarantine.quarantined()
+	}
+	return Stats{
+		Breakers:         breakers,
+		Quarantined:      quarantined,
+		Scaling:          d.scaleHistory.list(),
+		Workers:          workers,
+		Spilled:          spilled,
+		QueueDepth:       d.queueDepth(),
+		Running:          atomic.LoadInt64(&d.inflight),
+		Submitted:        atomic.LoadUint64(&d.submitted),
+		Succeeded:        atomic.LoadUint64(&d.succeeded),
+		Failed:           atomic.LoadUint64(&d.failed),
+		Detached:         atomic.LoadUint64(&d.detached),
+		Expired:          atomic.LoadUint64(&d.expired),
+		Shed:             shed,
+		BudgetViolations: violations,
+		Utilization:      Utilization(d.WorkerStats()),
+		QueueWait:        d.latency.wait.Percentiles(),
+		Duration:         d.latency.run.Percentiles(),
+	}
+}
+
+// PublishExpvar publishes the Stats of the global Dispatcher under name in expvar.
+// Like expvar.Publish it panics when name is already registered.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return instance.Stats()
+	}))
+}
+
+// PublishExpvar publishes the Stats of d under name in expvar, so /debug/vars exposes them.
+// Like expvar.Publish it panics when name is already registered.
+func (d *Dispatcher) PublishExpvar(name string) *Dispatcher {
+	expvar.Publish(name, expvar.Func(func() any {
+		return d.Stats()
+	}))
+	return d
+}
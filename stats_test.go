@@ -0,0 +1,46 @@
+package gorker
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDispatcher_PublishExpvar(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	<-d.Add(func() error {
+		return nil
+	})
+	<-d.Add(func() error {
+		return errors.New("fail")
+	})
+
+	name := fmt.Sprintf("gorker_test_stats_%p", d)
+	d.PublishExpvar(name)
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("expvar not published")
+	}
+	var got Stats
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Utilization < 0 || got.Utilization > 1 {
+		t.Errorf("utilization = %v, want between 0 and 1", got.Utilization)
+	}
+	// utilization and latencies depend on timing
+	if got.Duration.P50 > got.Duration.P99 {
+		t.Errorf("duration = %+v, want ascending percentiles", got.Duration)
+	}
+	got.Utilization = 0
+	got.QueueWait, got.Duration = Percentiles{}, Percentiles{}
+	want := Stats{Workers: 2, Submitted: 2, Succeeded: 1, Failed: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expvar = %+v, want %+v", got, want)
+	}
+}
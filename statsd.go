@@ -0,0 +1,89 @@
+package gorker
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsD is a StatsReporter sending metrics over UDP in the StatsD line protocol.
+// Tags are appended in the DogStatsD format, servers without tag support need none.
+//
+// Every report sends the gauges workers, queue_depth, running and utilization, the
+// counters submitted, succeeded and failed of the interval, and the gauges throughput,
+// completed jobs per second, and error_rate, the share of them which failed.
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+	tags   string
+
+	mu   sync.Mutex
+	last Stats
+	buf  bytes.Buffer
+}
+
+// NewStatsD creates a StatsD reporter sending to addr, metric names are prefixed by
+// prefix and a dot unless it is empty, tags are "key:value" pairs added to every metric
+func NewStatsD(addr, prefix string, tags ...string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &StatsD{conn: conn}
+	if prefix != "" {
+		s.prefix = prefix + "."
+	}
+	if len(tags) > 0 {
+		s.tags = "|#" + strings.Join(tags, ",")
+	}
+	return s, nil
+}
+
+// Report sends the metrics of st in a single datagram
+func (s *StatsD) Report(st Stats, elapsed time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	submitted := st.Submitted - s.last.Submitted
+	succeeded := st.Succeeded - s.last.Succeeded
+	failed := st.Failed - s.last.Failed
+	s.last = st
+
+	s.buf.Reset()
+	s.metric("workers", float64(st.Workers), "g")
+	s.metric("queue_depth", float64(st.QueueDepth), "g")
+	s.metric("running", float64(st.Running), "g")
+	s.metric("utilization", st.Utilization, "g")
+	s.metric("submitted", float64(submitted), "c")
+	s.metric("succeeded", float64(succeeded), "c")
+	s.metric("failed", float64(failed), "c")
+	var throughput, errorRate float64
+	if completed := succeeded + failed; completed > 0 {
+		if elapsed > 0 {
+			throughput = float64(completed) / elapsed.Seconds()
+		}
+		errorRate = float64(failed) / float64(completed)
+	}
+	s.metric("throughput", throughput, "g")
+	s.metric("error_rate", errorRate, "g")
+	_, err := s.conn.Write(bytes.TrimSuffix(s.buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func (s *StatsD) metric(name string, v float64, kind string) {
+	s.buf.WriteString(s.prefix)
+	s.buf.WriteString(name)
+	s.buf.WriteByte(':')
+	s.buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	s.buf.WriteByte('|')
+	s.buf.WriteString(kind)
+	s.buf.WriteString(s.tags)
+	s.buf.WriteByte('\n')
+}
+
+// Close closes the connection of s
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,75 @@
+package gorker
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenStatsD(t *testing.T) (net.PacketConn, func() string) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	read := func() string {
+		buf := make([]byte, 2048)
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+	return pc, read
+}
+
+func TestStatsD_Report(t *testing.T) {
+	pc, read := listenStatsD(t)
+	s, err := NewStatsD(pc.LocalAddr().String(), "app.pool", "env:test", "pool:a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Report(Stats{Submitted: 4, Succeeded: 2, Failed: 1}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	read()
+	if err := s.Report(Stats{Workers: 2, QueueDepth: 3, Running: 1, Utilization: 0.5, Submitted: 10, Succeeded: 5, Failed: 2}, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"app.pool.workers:2|g|#env:test,pool:a",
+		"app.pool.queue_depth:3|g|#env:test,pool:a",
+		"app.pool.running:1|g|#env:test,pool:a",
+		"app.pool.utilization:0.5|g|#env:test,pool:a",
+		"app.pool.submitted:6|c|#env:test,pool:a",
+		"app.pool.succeeded:3|c|#env:test,pool:a",
+		"app.pool.failed:1|c|#env:test,pool:a",
+		"app.pool.throughput:2|g|#env:test,pool:a",
+		"app.pool.error_rate:0.25|g|#env:test,pool:a",
+	}
+	if got := read(); got != strings.Join(want, "\n") {
+		t.Errorf("datagram = %q, want %q", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestWithStatsReporter(t *testing.T) {
+	pc, read := listenStatsD(t)
+	s, err := NewStatsD(pc.LocalAddr().String(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	d := New(1, WithStatsReporter(s, 10*time.Millisecond)).QueueRunner().Start()
+	defer d.Stop(true)
+	d.Add(func() error { return nil })
+	d.Wait()
+	if got := read(); !strings.HasPrefix(got, "workers:1|g\n") {
+		t.Errorf("datagram = %q, want the metrics of d", got)
+	}
+}
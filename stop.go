@@ -0,0 +1,84 @@
+package gorker
+
+import "context"
+
+// Stopping a Dispatcher happens in up to three stages:
+//
+//   - StopIntake rejects new jobs with ErrDraining. Queued and in-flight jobs keep running.
+//   - Flush waits for the queued and in-flight jobs, then stops d. Nothing is lost.
+//   - Kill stops d at once. In-flight jobs see their context canceled and queued jobs
+//     fail with a RejectionError matching ErrStopped.
+//
+// A stopped Dispatcher rejects new jobs with ErrStopped and can be started again.
+
+func StopIntake() *Dispatcher {
+	return instance.StopIntake()
+}
+
+// StopIntake stops accepting jobs, d keeps dispatching the queued ones. It is a no-op
+// unless d is running or paused, a paused Dispatcher is resumed to drain its queue.
+func (d *Dispatcher) StopIntake() *Dispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.transition(StateDraining, StateRunning, StatePaused) {
+		d.notify()
+	}
+	return d
+}
+
+func Flush(ctx context.Context) error {
+	return instance.Flush(ctx)
+}
+
+// Flush stops intake and blocks until every submitted job completed, then stops d.
+// It returns ctx.Err() when ctx is done first, d then keeps draining and Kill can be
+// used to give up on the remaining jobs.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	d.StopIntake()
+	if d.State() != StateDraining {
+		return nil
+	}
+	if err := d.WaitContext(ctx); err != nil {
+		return err
+	}
+	if d.transition(StateStopped, StateDraining) {
+		d.halt()
+	}
+	return nil
+}
+
+func Kill() *Dispatcher {
+	return instance.Kill()
+}
+
+// Kill stops d immediately. The contexts of in-flight jobs are canceled and the jobs
+// still queued or suspended complete with a RejectionError matching ErrStopped, jobs
+// pinned to a worker are kept.
+func (d *Dispatcher) Kill() *Dispatcher {
+	if d.transition(StateStopped, StateRunning, StatePaused, StateDraining) {
+		d.halt()
+	}
+	d.mu.Lock()
+	d.queue = append(append(d.undispatch(d.qout), drain(d.qin)...), d.queue...)
+	jobs := d.takeQueued(func(*job) bool {
+		return true
+	})
+	d.mu.Unlock()
+	for _, j := range append(jobs, d.suspended.take()...) {
+		d.reject(j, RejectedStopped, ErrStopped)
+	}
+	return d
+}
+
+// halt suspends the subsystems and cancels the workers of a Dispatcher which moved to StateStopped
+func (d *Dispatcher) halt() {
+	d.queueRunner.suspend()
+	d.observer.suspend()
+	d.tuner.suspend()
+	d.cancel()
+	d.mu.Lock()
+	for _, w := range d.workers {
+		w.running = false
+	}
+	d.mu.Unlock()
+}
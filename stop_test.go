@@ -0,0 +1,86 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_StopIntake(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Kill()
+
+	block := make(chan struct{})
+	running := d.Add(func() error {
+		<-block
+		return nil
+	})
+	queued := d.Add(func() error { return nil })
+	d.StopIntake()
+	if got := d.State(); got != StateDraining {
+		t.Fatalf("state = %v, want draining", got)
+	}
+	if err := <-d.Add(func() error { return nil }); !errors.Is(err, ErrDraining) {
+		t.Errorf("submit error = %v, want ErrDraining", err)
+	}
+	close(block)
+	for _, ech := range []chan error{running, queued} {
+		if err := <-ech; err != nil {
+			t.Errorf("job error = %v", err)
+		}
+	}
+}
+
+func TestDispatcher_Flush(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Kill()
+
+	block := make(chan struct{})
+	ech := d.Add(func() error {
+		<-block
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush = %v, want DeadlineExceeded", err)
+	}
+	if got := d.State(); got != StateDraining {
+		t.Fatalf("state after timeout = %v, want draining", got)
+	}
+	close(block)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ech; err != nil {
+		t.Errorf("job error = %v", err)
+	}
+	if got := d.State(); got != StateStopped {
+		t.Errorf("state = %v, want stopped", got)
+	}
+}
+
+func TestDispatcher_Kill(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+
+	started := make(chan struct{})
+	running := d.AddJob(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	queued := d.Add(func() error { return nil })
+	d.Kill()
+	if got := d.State(); got != StateStopped {
+		t.Fatalf("state = %v, want stopped", got)
+	}
+	if err := <-running; !errors.Is(err, context.Canceled) {
+		t.Errorf("in-flight error = %v, want context.Canceled", err)
+	}
+	var re *RejectionError
+	if err := <-queued; !errors.As(err, &re) || !errors.Is(err, ErrStopped) {
+		t.Errorf("queued error = %v, want a RejectionError matching ErrStopped", err)
+	}
+}
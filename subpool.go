@@ -0,0 +1,119 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+)
+
+// Subpool submits jobs to the workers of a parent Dispatcher while capping how many
+// of them run at the same time. Jobs above the cap wait in the Subpool and are handed
+// to the parent as running jobs finish, so they never occupy a parent worker while waiting.
+type Subpool struct {
+	d       *Dispatcher
+	max     int
+	mu      sync.Mutex
+	running int
+	pending []subpoolJob
+}
+
+type subpoolJob struct {
+	fn   JobFunc
+	opts []JobOption
+}
+
+// Subpool returns a Subpool running at most maxConcurrent jobs on the workers of d,
+// maxConcurrent is raised to 1 when lower
+func (d *Dispatcher) Subpool(maxConcurrent int) *Subpool {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &Subpool{
+		d:   d,
+		max: maxConcurrent,
+	}
+}
+
+// Add submits fn to the parent Dispatcher once the Subpool has room for it
+func (s *Subpool) Add(fn func() error, opts ...JobOption) chan error {
+	return s.AddJob(func(context.Context) error {
+		return fn()
+	}, opts...)
+}
+
+// AddJob submits fn to the parent Dispatcher once the Subpool has room for it
+func (s *Subpool) AddJob(fn JobFunc, opts ...JobOption) chan error {
+	ech := make(chan error, 1)
+	opts = append(opts[:len(opts):len(opts)], func(j *job) {
+		j.ech = ech
+	}, onDone(s.release))
+	s.mu.Lock()
+	if s.running >= s.max {
+		s.pending = append(s.pending, subpoolJob{fn: fn, opts: append(opts, withID(s.d.track()))})
+		s.mu.Unlock()
+		return ech
+	}
+	s.running++
+	s.mu.Unlock()
+	s.d.AddJob(fn, opts...)
+	return ech
+}
+
+// Max returns the number of jobs the Subpool runs at the same time
+func (s *Subpool) Max() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.max
+}
+
+// SetMax changes the number of jobs the Subpool runs at the same time, pending jobs are
+// submitted when it grows and running jobs finish when it shrinks
+func (s *Subpool) SetMax(maxConcurrent int) *Subpool {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	s.mu.Lock()
+	s.max = maxConcurrent
+	var next []subpoolJob
+	for s.running < s.max && len(s.pending) > 0 {
+		next = append(next, s.pending[0])
+		s.pending[0] = subpoolJob{}
+		s.pending = s.pending[1:]
+		s.running++
+	}
+	s.mu.Unlock()
+	for _, j := range next {
+		s.d.AddJob(j.fn, j.opts...)
+	}
+	return s
+}
+
+// Running returns the number of jobs of the Subpool submitted to the parent Dispatcher
+func (s *Subpool) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Pending returns the number of jobs waiting for room in the Subpool
+func (s *Subpool) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// release is called when a job of the Subpool completed and hands the slot to the
+// next pending job. Pending jobs take their ID when they are added, so Wait on the
+// parent also waits for the jobs still pending in the Subpool.
+func (s *Subpool) release(error) {
+	s.mu.Lock()
+	if len(s.pending) == 0 || s.running > s.max {
+		s.running--
+		s.mu.Unlock()
+		return
+	}
+	next := s.pending[0]
+	s.pending[0] = subpoolJob{}
+	s.pending = s.pending[1:]
+	s.mu.Unlock()
+	s.d.AddJob(next.fn, next.opts...)
+}
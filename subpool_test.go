@@ -0,0 +1,122 @@
+package gorker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Subpool(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		jobs int
+		want int32
+	}{
+		{name: "capped", max: 2, jobs: 10, want: 2},
+		{name: "single", max: 0, jobs: 5, want: 1},
+		{name: "below cap", max: 8, jobs: 3, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(8).QueueRunner().Start()
+			defer d.Stop(true)
+			sp := d.Subpool(tt.max)
+
+			var running, peak int32
+			fail := errors.New("fail")
+			echs := make([]chan error, 0, tt.jobs)
+			for i := 0; i < tt.jobs; i++ {
+				i := i
+				echs = append(echs, sp.Add(func() error {
+					n := atomic.AddInt32(&running, 1)
+					for {
+						p := atomic.LoadInt32(&peak)
+						if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+					if i%2 == 1 {
+						return fail
+					}
+					return nil
+				}))
+			}
+			d.Wait()
+			for i, ech := range echs {
+				err := <-ech
+				if (i%2 == 1) != errors.Is(err, fail) {
+					t.Errorf("job %d error = %v", i, err)
+				}
+			}
+			if got := atomic.LoadInt32(&peak); got != tt.want {
+				t.Errorf("peak concurrency = %d, want %d", got, tt.want)
+			}
+			if sp.Running() != 0 || sp.Pending() != 0 {
+				t.Errorf("running = %d, pending = %d after Wait", sp.Running(), sp.Pending())
+			}
+		})
+	}
+}
+
+func TestSubpool_sharesWorkers(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+	sp := d.Subpool(1)
+
+	block := make(chan struct{})
+	sp.Add(func() error {
+		<-block
+		return nil
+	})
+	sp.Add(func() error { return nil })
+	// the pending subpool job must not hold the second worker
+	select {
+	case err := <-d.Add(func() error { return nil }):
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("parent job starved by subpool")
+	}
+	if got := sp.Pending(); got != 1 {
+		t.Errorf("pending = %d, want 1", got)
+	}
+	close(block)
+	d.Wait()
+}
+
+func TestSubpool_KeepsDoneHook(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+	s := d.Subpool(1)
+
+	hooked := make(chan error, 2)
+	echs := make([]chan error, 2)
+	for i := range echs {
+		echs[i] = s.Add(func() error { return nil }, onDone(func(err error) {
+			hooked <- err
+		}))
+	}
+	for i, ech := range echs {
+		select {
+		case err := <-ech:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("job %d did not finish", i)
+		}
+		select {
+		case err := <-hooked:
+			if err != nil {
+				t.Errorf("completion hook of job %d got %v", i, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("completion hook of job %d was not called", i)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+)
+
+// subsystem is a reference counted background goroutine.
+// Only the first start spawns the goroutine and only the last stop terminates it.
+type subsystem struct {
+	mu     sync.Mutex
+	refs   int
+	run    func(ctx context.Context)
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *subsystem) start(run func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs++
+	s.run = run
+	s.spawn()
+}
+
+// spawn starts the goroutine unless it is alive, s.mu must be held
+func (s *subsystem) spawn() {
+	if s.cancel != nil {
+		return
+	}
+	run := s.run
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	s.cancel = cancel
+	s.done = done
+	go func() {
+		defer close(done)
+		run(ctx)
+		s.mu.Lock()
+		// the goroutine may also end on its own
+		if s.done == done {
+			s.refs = 0
+			s.cancel = nil
+			s.done = nil
+		}
+		s.mu.Unlock()
+	}()
+}
+
+func (s *subsystem) stop() {
+	s.mu.Lock()
+	if s.refs == 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.refs--
+	if s.refs > 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	s.suspend()
+}
+
+func (s *subsystem) running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refs > 0
+}
+
+// suspend stops the goroutine but keeps the references so that resume restarts it
+func (s *subsystem) suspend() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.done = nil
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (s *subsystem) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs > 0 {
+		s.spawn()
+	}
+}
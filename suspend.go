@@ -0,0 +1,210 @@
+package gorker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSuspended is the error returned by a job which suspended itself, see Suspend
+	ErrSuspended = errors.New("gorker: job suspended")
+	// ErrUnknownToken is returned by ResumeJob for tokens of no suspended job
+	ErrUnknownToken = errors.New("gorker: unknown resume token")
+	// ErrNotInJob is returned by Suspend called with a context not owned by a job
+	ErrNotInJob = errors.New("gorker: not called from a job")
+)
+
+// Token identifies a suspended job, see Suspend
+type Token string
+
+// SuspendedJob describes a job waiting to be resumed
+type SuspendedJob struct {
+	Token Token     `json:"token"`
+	Job   JobInfo   `json:"job"`
+	Since time.Time `json:"since"`
+}
+
+type jobKey struct{}
+
+// jobScope is the job owning a context, see Suspend and AttemptFrom
+type jobScope struct {
+	d *Dispatcher
+	j *job
+}
+
+type suspension struct {
+	j     *job
+	state any
+	since time.Time
+	// parked is set once the job returned, resumed when ResumeJob came first
+	parked  bool
+	resumed bool
+	value   any
+}
+
+// resumption is the state a resumed job was suspended with and the value it was resumed with
+type resumption struct {
+	state any
+	value any
+}
+
+type suspended struct {
+	mu   sync.Mutex
+	jobs map[Token]*suspension
+}
+
+func newSuspended() *suspended {
+	return &suspended{
+		jobs: make(map[Token]*suspension),
+	}
+}
+
+// Suspend parks the job owning ctx with state until ResumeJob is called with the returned
+// token, e.g. from a webhook. The job must return the returned error, it then frees its
+// worker and does not complete. Once resumed it runs again as a new execution, which reads
+// state and the value passed to ResumeJob with Resumed. A job returning another error after
+// calling Suspend completes with it as usual.
+func Suspend(ctx context.Context, state any) (Token, error) {
+	s, ok := ctx.Value(jobKey{}).(jobScope)
+	if !ok {
+		return "", ErrNotInJob
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := Token(hex.EncodeToString(b))
+	sp := s.d.suspended
+	sp.mu.Lock()
+	sp.jobs[token] = &suspension{
+		j:     s.j,
+		state: state,
+		since: s.d.clock.Now(),
+	}
+	sp.mu.Unlock()
+	s.j.token = token
+	return token, fmt.Errorf("%w: %s", ErrSuspended, token)
+}
+
+// Resumed returns the state a resumed job was suspended with and the value it was resumed
+// with, ok is false for jobs which were not resumed
+func Resumed(ctx context.Context) (state, value any, ok bool) {
+	s, found := ctx.Value(jobKey{}).(jobScope)
+	if !found || s.j.resumed == nil {
+		return nil, nil, false
+	}
+	return s.j.resumed.state, s.j.resumed.value, true
+}
+
+func ResumeJob(token Token, value any) error {
+	return instance.ResumeJob(token, value)
+}
+
+// ResumeJob queues the job suspended with token again, value can be read by the job with Resumed.
+// It returns ErrUnknownToken for tokens of no suspended job.
+func (d *Dispatcher) ResumeJob(token Token, value any) error {
+	sp := d.suspended
+	sp.mu.Lock()
+	s, ok := sp.jobs[token]
+	if !ok || s.resumed {
+		sp.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownToken, token)
+	}
+	s.value = value
+	s.resumed = true
+	if !s.parked {
+		// the job did not return yet, park requeues it
+		sp.mu.Unlock()
+		return nil
+	}
+	delete(sp.jobs, token)
+	sp.mu.Unlock()
+	d.wake(s)
+	return nil
+}
+
+func (d *Dispatcher) wake(s *suspension) {
+	j := s.j
+	j.resumed = &resumption{state: s.state, value: s.value}
+	d.requeue([]*job{j})
+}
+
+// park keeps j suspended when it returned ErrSuspended after calling Suspend and reports
+// whether it did, j is requeued right away when it was resumed while it was returning.
+// A parked job has no outcome yet, so the breaker and quarantine probes it took for group
+// and poisonKey are freed for the next job instead of being recorded.
+func (d *Dispatcher) park(j *job, err error, group, poisonKey string) bool {
+	token := j.token
+	j.token = ""
+	sp := d.suspended
+	sp.mu.Lock()
+	s, ok := sp.jobs[token]
+	if !ok {
+		sp.mu.Unlock()
+		return false
+	}
+	if !errors.Is(err, ErrSuspended) {
+		delete(sp.jobs, token)
+		sp.mu.Unlock()
+		return false
+	}
+	s.parked = true
+	resumed := s.resumed
+	if resumed {
+		delete(sp.jobs, token)
+	}
+	sp.mu.Unlock()
+	if d.quota != nil {
+		d.quota.release(d, j)
+	}
+	if j.holding {
+		d.releaseWorker(j)
+	}
+	if group != "" {
+		d.breakers.release(group)
+	}
+	if poisonKey != "" {
+		d.quarantine.release(poisonKey)
+	}
+	if resumed {
+		d.wake(s)
+	}
+	return true
+}
+
+// take removes every suspended job, see Kill
+func (sp *suspended) take() []*job {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	var jobs []*job
+	for token, s := range sp.jobs {
+		if s.parked {
+			jobs = append(jobs, s.j)
+			delete(sp.jobs, token)
+		}
+	}
+	return jobs
+}
+
+func Suspended() []SuspendedJob {
+	return instance.Suspended()
+}
+
+// Suspended returns the jobs waiting to be resumed
+func (d *Dispatcher) Suspended() []SuspendedJob {
+	sp := d.suspended
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	jobs := make([]SuspendedJob, 0, len(sp.jobs))
+	for token, s := range sp.jobs {
+		if s.parked {
+			jobs = append(jobs, SuspendedJob{Token: token, Job: s.j.info(), Since: s.since})
+		}
+	}
+	return jobs
+}
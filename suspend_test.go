@@ -0,0 +1,118 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSuspend(t *testing.T) {
+	tests := []struct {
+		name string
+		// early resumes the job before it returned from its first execution
+		early bool
+	}{
+		{name: "resume parked job"},
+		{name: "resume before return", early: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(1).QueueRunner().Start()
+			defer d.Kill()
+
+			tokens := make(chan Token, 1)
+			ech := d.AddJob(func(ctx context.Context) error {
+				if state, value, ok := Resumed(ctx); ok {
+					if state != "state" || value != "value" {
+						t.Errorf("Resumed = %v, %v, want state, value", state, value)
+					}
+					return nil
+				}
+				token, err := Suspend(ctx, "state")
+				if tt.early {
+					if err := d.ResumeJob(token, "value"); err != nil {
+						t.Error(err)
+					}
+				}
+				tokens <- token
+				return err
+			})
+			token := <-tokens
+			if !tt.early {
+				// the worker is free while the job is suspended
+				if err := <-d.Add(func() error { return nil }); err != nil {
+					t.Fatal(err)
+				}
+				if got := d.Suspended(); len(got) != 1 || got[0].Token != token {
+					t.Fatalf("Suspended = %+v, want %s", got, token)
+				}
+				if err := d.ResumeJob(token, "value"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			select {
+			case err := <-ech:
+				if err != nil {
+					t.Errorf("resumed job = %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("resumed job did not complete")
+			}
+			if err := d.ResumeJob(token, nil); !errors.Is(err, ErrUnknownToken) {
+				t.Errorf("second ResumeJob = %v, want ErrUnknownToken", err)
+			}
+		})
+	}
+}
+
+func TestSuspend_Kill(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	ech := d.AddJob(func(ctx context.Context) error {
+		_, err := Suspend(ctx, nil)
+		return err
+	})
+	deadline := time.Now().Add(time.Second)
+	for len(d.Suspended()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	d.Kill()
+	if err := <-ech; !errors.Is(err, ErrStopped) {
+		t.Errorf("suspended job after Kill = %v, want ErrStopped", err)
+	}
+	if _, err := Suspend(context.Background(), nil); !errors.Is(err, ErrNotInJob) {
+		t.Errorf("Suspend outside a job = %v, want ErrNotInJob", err)
+	}
+}
+
+func TestSuspend_BreakerProbe(t *testing.T) {
+	d := New(1, WithCircuitBreaker(BreakerConfig{
+		Key: func(info JobInfo) string {
+			return info.Tags[0]
+		},
+		FailureRate: 1,
+		MinRequests: 1,
+		Cooldown:    10 * time.Millisecond,
+	})).QueueRunner().Start()
+	defer d.Kill()
+
+	fail := errors.New("down")
+	if err := <-d.Add(func() error { return fail }, WithTags("g")); !errors.Is(err, fail) {
+		t.Fatalf("first job error = %v, want %v", err, fail)
+	}
+	time.Sleep(20 * time.Millisecond)
+	// the probe of the half-open breaker suspends
+	tokens := make(chan Token, 1)
+	d.AddJob(func(ctx context.Context) error {
+		token, err := Suspend(ctx, nil)
+		tokens <- token
+		return err
+	}, WithTags("g"))
+	<-tokens
+	if err := <-d.Add(func() error { return nil }, WithTags("g")); err != nil {
+		t.Errorf("job after a suspended probe = %v, want nil", err)
+	}
+	if got := d.Stats().Breakers["g"]; got != BreakerClosed {
+		t.Errorf("breaker = %s, want %s", got, BreakerClosed)
+	}
+}
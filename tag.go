@@ -0,0 +1,107 @@
+package gorker
+
+import (
+	"strings"
+	"sync"
+)
+
+// JobOption configures a single submission
+type JobOption func(*job)
+
+// WithTags attaches tags such as "tenant:acme" to a job.
+// Tags are part of JobInfo and are aggregated by StatsByTag.
+func WithTags(tags ...string) JobOption {
+	return func(j *job) {
+		j.tags = append(j.tags, tags...)
+	}
+}
+
+// TagStats are the counters of the jobs carrying a tag
+type TagStats struct {
+	Queued    int64 `json:"queued"`
+	Running   int64 `json:"running"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+}
+
+type tagStats struct {
+	mu    sync.Mutex
+	stats map[string]*TagStats
+}
+
+func newTagStats() *tagStats {
+	return &tagStats{
+		stats: make(map[string]*TagStats),
+	}
+}
+
+func (t *tagStats) update(tags []string, fn func(*TagStats)) {
+	if len(tags) == 0 {
+		return
+	}
+	t.mu.Lock()
+	for _, tag := range tags {
+		s, ok := t.stats[tag]
+		if !ok {
+			s = new(TagStats)
+			t.stats[tag] = s
+		}
+		fn(s)
+	}
+	t.mu.Unlock()
+}
+
+func (t *tagStats) submitted(tags []string) {
+	t.update(tags, func(s *TagStats) {
+		s.Queued++
+	})
+}
+
+func (t *tagStats) started(tags []string) {
+	t.update(tags, func(s *TagStats) {
+		s.Queued--
+		s.Running++
+	})
+}
+
+// retried moves a failed job which is going to run again back to queued
+func (t *tagStats) retried(tags []string) {
+	t.update(tags, func(s *TagStats) {
+		s.Running--
+		s.Queued++
+	})
+}
+
+// completed also accounts for jobs removed from the queue without running
+func (t *tagStats) completed(tags []string, started bool, err error) {
+	t.update(tags, func(s *TagStats) {
+		if started {
+			s.Running--
+		} else {
+			s.Queued--
+		}
+		if err != nil {
+			s.Failed++
+		} else {
+			s.Succeeded++
+		}
+	})
+}
+
+func StatsByTag(prefix string) map[string]TagStats {
+	return instance.StatsByTag(prefix)
+}
+
+// StatsByTag returns the counters of every tag starting with prefix,
+// an empty prefix returns every tag
+func (d *Dispatcher) StatsByTag(prefix string) map[string]TagStats {
+	d.tags.mu.Lock()
+	defer d.tags.mu.Unlock()
+	res := make(map[string]TagStats)
+	for tag, s := range d.tags.stats {
+		if strings.HasPrefix(tag, prefix) {
+			res[tag] = *s
+		}
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDispatcher_StatsByTag(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	<-d.Add(func() error {
+		return nil
+	}, WithTags("tenant:acme", "type:export"))
+	<-d.Add(func() error {
+		return errors.New("fail")
+	}, WithTags("tenant:acme"))
+	<-d.AddJob(func(context.Context) error {
+		return nil
+	}, WithTags("tenant:other"))
+	<-d.Add(func() error {
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   map[string]TagStats
+	}{
+		{
+			name:   "tenant prefix",
+			prefix: "tenant:",
+			want: map[string]TagStats{
+				"tenant:acme":  {Succeeded: 1, Failed: 1},
+				"tenant:other": {Succeeded: 1},
+			},
+		},
+		{
+			name:   "single tag",
+			prefix: "type:export",
+			want: map[string]TagStats{
+				"type:export": {Succeeded: 1},
+			},
+		},
+		{
+			name:   "no match",
+			prefix: "none",
+			want:   map[string]TagStats{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.StatsByTag(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StatsByTag(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package gorker
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrUnknownHandler is returned by tasks whose handler is not registered
+var ErrUnknownHandler = errors.New("gorker: unknown task handler")
+
+// Handler executes the payload of a registered task.
+// Tasks are jobs described only by a handler name and a payload,
+// so they can be serialized, spilled to disk and restored.
+type Handler func(ctx context.Context, payload []byte) error
+
+type task struct {
+	name        string
+	payload     []byte
+	contentType string
+}
+
+// Handle registers h as the handler of tasks named name
+func (d *Dispatcher) Handle(name string, h Handler) *Dispatcher {
+	d.mu.Lock()
+	if d.handlers == nil {
+		d.handlers = make(map[string]Handler)
+	}
+	d.handlers[name] = h
+	d.mu.Unlock()
+	return d
+}
+
+// AddTask adds a serializable job executed by the handler registered as name
+func (d *Dispatcher) AddTask(name string, payload []byte, opts ...JobOption) chan error {
+	return d.submit(d.newTask(taskRecord{
+		name:    name,
+		payload: payload,
+	}), opts)
+}
+
+func (d *Dispatcher) newTask(rec taskRecord) *job {
+	t := &task{
+		name:        rec.name,
+		payload:     rec.payload,
+		contentType: rec.contentType,
+	}
+	return &job{
+		fn:       d.taskFunc(t),
+		enqueued: rec.enqueued,
+		tags:     rec.tags,
+		task:     t,
+	}
+}
+
+// taskFunc resolves the handler at execution time so that tasks restored before
+// their handler was registered still run
+func (d *Dispatcher) taskFunc(t *task) JobFunc {
+	return func(ctx context.Context) error {
+		d.mu.RLock()
+		h, ok := d.handlers[t.name]
+		d.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownHandler, t.name)
+		}
+		if t.contentType != "" {
+			ctx = context.WithValue(ctx, contentTypeKey{}, t.contentType)
+		}
+		return h(ctx, t.payload)
+	}
+}
+
+// taskHeaderSize is the size of the fixed part of an encoded task:
+// id, enqueue time in unix nanoseconds, name length, payload length and tag count.
+// Each tag follows the payload prefixed by its 2 byte length. When the high bit of
+// the tag count is set the content type follows the tags, prefixed by its 2 byte length.
+const taskHeaderSize = 26
+
+// taskContentTypeFlag marks the tag count of tasks with a content type
+const taskContentTypeFlag = 1 << 15
+
+type taskRecord struct {
+	id          JobID
+	enqueued    time.Time
+	name        string
+	payload     []byte
+	tags        []string
+	contentType string
+}
+
+func appendTask(buf []byte, j *job) []byte {
+	var head [taskHeaderSize]byte
+	binary.BigEndian.PutUint64(head[0:], uint64(j.id))
+	binary.BigEndian.PutUint64(head[8:], uint64(j.enqueued.UnixNano()))
+	binary.BigEndian.PutUint32(head[16:], uint32(len(j.task.name)))
+	binary.BigEndian.PutUint32(head[20:], uint32(len(j.task.payload)))
+	count := uint16(len(j.tags))
+	if j.task.contentType != "" {
+		count |= taskContentTypeFlag
+	}
+	binary.BigEndian.PutUint16(head[24:], count)
+	buf = append(buf, head[:]...)
+	buf = append(buf, j.task.name...)
+	buf = append(buf, j.task.payload...)
+	for _, tag := range j.tags {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(tag)))
+		buf = append(buf, tag...)
+	}
+	if j.task.contentType != "" {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(j.task.contentType)))
+		buf = append(buf, j.task.contentType...)
+	}
+	return buf
+}
+
+// readTask decodes a single task from r and returns the number of bytes consumed.
+// It returns io.EOF only when r ends exactly at a task boundary.
+func readTask(r io.Reader) (taskRecord, int, error) {
+	var head [taskHeaderSize]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return taskRecord{}, 0, err
+	}
+	nlen := int(binary.BigEndian.Uint32(head[16:]))
+	body := make([]byte, nlen+int(binary.BigEndian.Uint32(head[20:])))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return taskRecord{}, 0, unexpectedEOF(err)
+	}
+	n := taskHeaderSize + len(body)
+	rec := taskRecord{
+		id:       JobID(binary.BigEndian.Uint64(head[0:])),
+		enqueued: time.Unix(0, int64(binary.BigEndian.Uint64(head[8:]))),
+		name:     string(body[:nlen]),
+		payload:  body[nlen:],
+	}
+	count := binary.BigEndian.Uint16(head[24:])
+	if tags := int(count &^ taskContentTypeFlag); tags > 0 {
+		rec.tags = make([]string, tags)
+		for i := range rec.tags {
+			tag, m, err := readString(r)
+			if err != nil {
+				return taskRecord{}, 0, err
+			}
+			rec.tags[i] = tag
+			n += m
+		}
+	}
+	if count&taskContentTypeFlag != 0 {
+		ct, m, err := readString(r)
+		if err != nil {
+			return taskRecord{}, 0, err
+		}
+		rec.contentType = ct
+		n += m
+	}
+	return rec, n, nil
+}
+
+// readString decodes a string prefixed by its 2 byte length and returns the number of bytes consumed
+func readString(r io.Reader) (string, int, error) {
+	var l [2]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return "", 0, unexpectedEOF(err)
+	}
+	b := make([]byte, binary.BigEndian.Uint16(l[:]))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", 0, unexpectedEOF(err)
+	}
+	return string(b), len(l) + len(b), nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
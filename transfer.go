@@ -0,0 +1,62 @@
+package gorker
+
+func TransferQueued(dst *Dispatcher, n int, filter func(JobInfo) bool) int {
+	return instance.TransferQueued(dst, n, filter)
+}
+
+// TransferQueued moves at most n jobs queued in d which match filter, or any job when
+// filter is nil, to dst and returns how many were moved, e.g. to rebalance a backed up
+// Dispatcher onto an idle one. Jobs are taken from the head of the queue, jobs already
+// buffered for a worker, spilled, pinned, reserved through a Slot or members of a
+// JobGroup stay in d. A moved job keeps its enqueue time and runs with the options and
+// limits of dst like a job forwarded by ForwardTo, its result is delivered through d so
+// that its error channel, Wait and the stats of d account for it.
+func (d *Dispatcher) TransferQueued(dst *Dispatcher, n int, filter func(JobInfo) bool) int {
+	if dst == nil || dst == d || n < 1 || !dst.State().accepting() {
+		return 0
+	}
+	taken := 0
+	d.mu.Lock()
+	jobs := d.takeQueued(func(j *job) bool {
+		if taken == n || j.reserved || j.group != nil || (filter != nil && !filter(j.info())) {
+			return false
+		}
+		taken++
+		return true
+	})
+	d.mu.Unlock()
+	for _, j := range jobs {
+		d.transfer(dst, j)
+	}
+	return len(jobs)
+}
+
+// transfer submits a copy of the queued job j to dst and completes j with its result
+func (d *Dispatcher) transfer(dst *Dispatcher, j *job) {
+	if j.run != nil {
+		dst.Go(j.run)
+		*j = job{ech: j.ech}
+		jobPool.Put(j)
+		return
+	}
+	moved := &job{
+		fn:        j.fn,
+		task:      j.task,
+		tags:      j.tags,
+		enqueued:  j.enqueued,
+		class:     j.class,
+		retry:     j.retry,
+		attempt:   j.attempt,
+		attempts:  j.attempts,
+		deadline:  j.deadline,
+		due:       j.due,
+		submitCtx: j.submitCtx,
+		values:    j.values,
+		resumed:   j.resumed,
+		forwarded: true,
+		done: func(err error) {
+			d.complete(j, err)
+		},
+	}
+	dst.submit(moved, nil)
+}
@@ -0,0 +1,96 @@
+package gorker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until the queue runner moved n submitted jobs to the queue of d
+func waitQueued(t *testing.T, d *Dispatcher, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.mu.RLock()
+		queued := len(d.queue)
+		d.mu.RUnlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcher_TransferQueued(t *testing.T) {
+	errJob := errors.New("failed")
+	tests := []struct {
+		name   string
+		n      int
+		filter func(JobInfo) bool
+		want   int
+	}{
+		{name: "all", n: 10, want: 4},
+		{name: "at most n", n: 3, want: 3},
+		{name: "none", n: 0, want: 0},
+		{
+			name: "filtered",
+			n:    10,
+			filter: func(info JobInfo) bool {
+				return len(info.Tags) > 0 && info.Tags[0] == "move"
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := New(1).QueueRunner().Start().Pause()
+			defer src.Stop(true)
+			dst := New(1).QueueRunner().Start()
+			defer dst.Stop(true)
+
+			echs := []chan error{
+				src.Add(func() error { return nil }, WithTags("move")),
+				src.Add(func() error { return errJob }, WithTags("move")),
+				src.Add(func() error { return nil }, WithTags("stay")),
+				src.Add(func() error { return nil }, WithTags("stay")),
+			}
+			waitQueued(t, src, len(echs))
+			if got := src.TransferQueued(dst, tt.n, tt.filter); got != tt.want {
+				t.Fatalf("TransferQueued = %d, want %d", got, tt.want)
+			}
+			dst.Wait()
+			if got := dst.Stats().Succeeded + dst.Stats().Failed; got != uint64(tt.want) {
+				t.Errorf("dst completed %d jobs, want %d", got, tt.want)
+			}
+			src.Resume()
+			for i, ech := range echs {
+				if err := <-ech; (err != nil) != (i == 1) {
+					t.Errorf("job %d error = %v", i, err)
+				}
+			}
+			src.Wait()
+			if st := src.Stats(); st.Succeeded != 3 || st.Failed != 1 {
+				t.Errorf("src stats = %d succeeded, %d failed, want 3 and 1", st.Succeeded, st.Failed)
+			}
+		})
+	}
+}
+
+func TestDispatcher_TransferQueued_Stopped(t *testing.T) {
+	src := New(1).QueueRunner().Start().Pause()
+	defer src.Stop(true)
+	dst := New(1).Start().Kill()
+
+	ech := src.Add(func() error { return nil })
+	waitQueued(t, src, 1)
+	if got := src.TransferQueued(dst, 1, nil); got != 0 {
+		t.Errorf("TransferQueued to a stopped Dispatcher = %d, want 0", got)
+	}
+	src.Resume()
+	if err := <-ech; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,121 @@
+package gorker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// hostTag prefixes the tag holding the host of a request sent through Transport
+const hostTag = "host:"
+
+type transport struct {
+	d    *Dispatcher
+	base http.RoundTripper
+	opts []JobOption
+}
+
+func Transport(base http.RoundTripper, opts ...JobOption) http.RoundTripper {
+	return instance.Transport(base, opts...)
+}
+
+// Transport returns an http.RoundTripper sending every request through base as a job of d
+// with opts, http.DefaultTransport when base is nil. A request holds its worker until its
+// response body is closed, so the worker count bounds the outbound concurrency. Requests are
+// tagged with "host:" and their host, WithQuota(TransportHost, 0, 1) serializes the requests
+// to each host and WithAffinity can keep a host on one worker.
+func (d *Dispatcher) Transport(base http.RoundTripper, opts ...JobOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{
+		d:    d,
+		base: base,
+		opts: opts,
+	}
+}
+
+// TransportHost returns the host of a request job submitted by Transport, "" for other jobs
+func TransportHost(info JobInfo) string {
+	for _, tag := range info.Tags {
+		if host, ok := strings.CutPrefix(tag, hostTag); ok {
+			return host
+		}
+	}
+	return ""
+}
+
+type roundTrip struct {
+	resp *http.Response
+	err  error
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	res := make(chan roundTrip, 1)
+	opts := append([]JobOption{WithTags(hostTag + req.URL.Host)}, t.opts...)
+	ech := t.d.AddJob(func(context.Context) error {
+		if err := ctx.Err(); err != nil {
+			res <- roundTrip{err: err}
+			return err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			res <- roundTrip{err: err}
+			return err
+		}
+		body := &releasingBody{
+			ReadCloser: resp.Body,
+			closed:     make(chan struct{}),
+		}
+		resp.Body = body
+		res <- roundTrip{resp: resp}
+		select {
+		case <-body.closed:
+		case <-ctx.Done():
+		}
+		return nil
+	}, opts...)
+
+	select {
+	case r := <-res:
+		return r.resp, r.err
+	case err := <-ech:
+		// a job which ran sent its result before completing
+		select {
+		case r := <-res:
+			return r.resp, r.err
+		default:
+			return nil, err
+		}
+	case <-ctx.Done():
+		go func() {
+			// close the body of a response arriving after the caller gave up
+			select {
+			case r := <-res:
+				if r.resp != nil {
+					r.resp.Body.Close()
+				}
+			case <-ech:
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// releasingBody frees the worker of a request once the response body was closed
+type releasingBody struct {
+	io.ReadCloser
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		close(b.closed)
+	})
+	return err
+}
@@ -0,0 +1,92 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Transport(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		inflight, max int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		mu.Lock()
+		if n > max {
+			max = n
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		workers int
+		opts    []Option
+		want    int32
+	}{
+		{name: "pool bound", workers: 2, want: 2},
+		{name: "per host", workers: 4, opts: []Option{WithQuota(TransportHost, 0, 1)}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			max = 0
+			d := New(tt.workers, tt.opts...).QueueRunner().Start()
+			defer d.Kill()
+			client := &http.Client{Transport: d.Transport(nil)}
+
+			wg := new(sync.WaitGroup)
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					resp, err := client.Get(srv.URL)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					defer resp.Body.Close()
+					if b, _ := io.ReadAll(resp.Body); string(b) != "ok" {
+						t.Errorf("body = %q", b)
+					}
+				}()
+			}
+			wg.Wait()
+			mu.Lock()
+			defer mu.Unlock()
+			if max != tt.want {
+				t.Errorf("max concurrent requests = %d, want %d", max, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Transport_Canceled(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Kill()
+
+	block := make(chan struct{})
+	d.Add(func() error {
+		<-block
+		return nil
+	})
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid", nil)
+	if _, err := d.Transport(nil).RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RoundTrip of a queued request = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,108 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// TypedPool runs a single handler over items of type T on a fixed set of worker
+// goroutines. Items travel through a typed channel, so sending one allocates neither a
+// closure nor a job envelope, which suits high volume homogeneous workloads better
+// than a Dispatcher.
+type TypedPool[T any] struct {
+	handler func(context.Context, T) error
+	onError func(T, error)
+	items   chan T
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	// mu guards closing items against concurrent senders
+	mu        sync.RWMutex
+	closed    bool
+	succeeded uint64
+	failed    uint64
+}
+
+// NewTypedPool starts workers goroutines running handler for the items sent to the pool,
+// up to buffer items wait for a worker. The workers stop when ctx is done.
+func NewTypedPool[T any](ctx context.Context, workers, buffer int, handler func(ctx context.Context, item T) error) *TypedPool[T] {
+	if workers < 1 {
+		workers = 1
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	p := &TypedPool[T]{
+		handler: handler,
+		items:   make(chan T, buffer),
+	}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// OnError registers fn to receive the items whose handler failed, it must be set before
+// the first Send and is called from the worker goroutines
+func (p *TypedPool[T]) OnError(fn func(item T, err error)) *TypedPool[T] {
+	p.onError = fn
+	return p
+}
+
+// Send hands item to the workers, blocking while the buffer is full. It fails with
+// ErrStopped after Close and with the context error once the pool context is done.
+func (p *TypedPool[T]) Send(item T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrStopped
+	}
+	select {
+	case p.items <- item:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Close stops accepting items and blocks until the sent items were handled
+func (p *TypedPool[T]) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.items)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	p.cancel()
+}
+
+// Processed returns the number of items whose handler succeeded and failed
+func (p *TypedPool[T]) Processed() (succeeded, failed uint64) {
+	return atomic.LoadUint64(&p.succeeded), atomic.LoadUint64(&p.failed)
+}
+
+func (p *TypedPool[T]) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case item, ok := <-p.items:
+			if !ok {
+				return
+			}
+			if err := p.handler(p.ctx, item); err != nil {
+				atomic.AddUint64(&p.failed, 1)
+				if p.onError != nil {
+					p.onError(item, err)
+				}
+				continue
+			}
+			atomic.AddUint64(&p.succeeded, 1)
+		}
+	}
+}
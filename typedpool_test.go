@@ -0,0 +1,92 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTypedPool(t *testing.T) {
+	var sum int64
+	errOdd := errors.New("odd")
+	p := NewTypedPool(context.Background(), 4, 8, func(_ context.Context, n int) error {
+		if n%2 == 1 {
+			return errOdd
+		}
+		atomic.AddInt64(&sum, int64(n))
+		return nil
+	})
+	var (
+		mu     sync.Mutex
+		failed []int
+	)
+	p.OnError(func(n int, err error) {
+		mu.Lock()
+		failed = append(failed, n)
+		mu.Unlock()
+	})
+	for i := 0; i < 100; i++ {
+		if err := p.Send(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Close()
+	if got := atomic.LoadInt64(&sum); got != 2450 {
+		t.Errorf("sum = %d, want 2450", got)
+	}
+	if ok, ko := p.Processed(); ok != 50 || ko != 50 || len(failed) != 50 {
+		t.Errorf("processed %d/%d with %d errors reported, want 50/50", ok, ko, len(failed))
+	}
+	if err := p.Send(1); !errors.Is(err, ErrStopped) {
+		t.Errorf("Send after Close = %v, want ErrStopped", err)
+	}
+}
+
+func TestTypedPool_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	block := make(chan struct{})
+	p := NewTypedPool(ctx, 1, 0, func(context.Context, int) error {
+		<-block
+		return nil
+	})
+	p.Send(1)
+	cancel()
+	if err := p.Send(2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Send = %v, want context.Canceled", err)
+	}
+	close(block)
+	p.Close()
+}
+
+func BenchmarkTypedPool(b *testing.B) {
+	b.ReportAllocs()
+	var sum int64
+	p := NewTypedPool(context.Background(), 4, 1024, func(_ context.Context, n int) error {
+		atomic.AddInt64(&sum, int64(n))
+		return nil
+	})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Send(i)
+	}
+	p.Close()
+}
+
+func BenchmarkTypedPool_Closures(b *testing.B) {
+	b.ReportAllocs()
+	var sum int64
+	d := New(4, WithBufferPolicy(BufferFixed, 1024)).QueueRunner().Start()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Add(func() error {
+			atomic.AddInt64(&sum, int64(i))
+			return nil
+		})
+	}
+	d.Wait()
+	b.StopTimer()
+	d.Stop(true)
+	d.StopQueueRunner()
+}
@@ -0,0 +1,77 @@
+package gorker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// completions wakes WaitN callers when jobs finish
+type completions struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	waiting int32
+}
+
+func newCompletions() *completions {
+	c := new(completions)
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *completions) notify() {
+	if atomic.LoadInt32(&c.waiting) == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.cond.Broadcast()
+	c.mu.Unlock()
+}
+
+func WaitContext(ctx context.Context) error {
+	return instance.WaitContext(ctx)
+}
+
+// WaitContext is Wait bounded by ctx, it returns ctx.Err() when ctx is done first
+func (d *Dispatcher) WaitContext(ctx context.Context) error {
+	if !d.State().active() {
+		return nil
+	}
+	return d.Barrier().WaitContext(ctx)
+}
+
+func WaitTimeout(timeout time.Duration) error {
+	return instance.WaitTimeout(timeout)
+}
+
+// WaitTimeout is Wait bounded by timeout, it returns context.DeadlineExceeded when it expires
+func (d *Dispatcher) WaitTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.WaitContext(ctx)
+}
+
+func WaitN(n int) {
+	instance.WaitN(n)
+}
+
+// WaitN blocks until n more jobs finished after the call, successfully or not
+func (d *Dispatcher) WaitN(n int) {
+	if n < 1 {
+		return
+	}
+	c := d.completions
+	target := d.finishedJobs() + uint64(n)
+	atomic.AddInt32(&c.waiting, 1)
+	defer atomic.AddInt32(&c.waiting, -1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for d.finishedJobs() < target {
+		c.cond.Wait()
+	}
+}
+
+func (d *Dispatcher) finishedJobs() uint64 {
+	return atomic.LoadUint64(&d.succeeded) + atomic.LoadUint64(&d.failed)
+}
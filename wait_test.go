@@ -0,0 +1,58 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_WaitTimeout(t *testing.T) {
+	d := New(1).QueueRunner().Start()
+	defer d.Stop(true)
+
+	release := make(chan struct{})
+	d.Add(func() error {
+		<-release
+		return nil
+	})
+	if err := d.WaitTimeout(10 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitTimeout() with a running job = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+	if err := d.WaitTimeout(time.Second); err != nil {
+		t.Errorf("WaitTimeout() = %v", err)
+	}
+}
+
+func TestDispatcher_WaitN(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	<-d.Add(func() error { return nil })
+
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		d.Add(func() error {
+			<-release
+			return nil
+		})
+	}
+	done := make(chan struct{})
+	go func() {
+		d.WaitN(3)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("WaitN(3) returned before any job finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WaitN(3) did not return")
+	}
+	d.Wait()
+}
@@ -0,0 +1,47 @@
+package gorker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// jobErrors collects the errors of failed jobs for WaitErrors
+type jobErrors struct {
+	mu      sync.Mutex
+	errs    []error
+	dropped int
+}
+
+func (e *jobErrors) add(id JobID, err error) {
+	e.mu.Lock()
+	if len(e.errs) < retainedFailures {
+		e.errs = append(e.errs, fmt.Errorf("job %d: %w", id, err))
+	} else {
+		e.dropped++
+	}
+	e.mu.Unlock()
+}
+
+func (e *jobErrors) take() error {
+	e.mu.Lock()
+	errs, dropped := e.errs, e.dropped
+	e.errs, e.dropped = nil, 0
+	e.mu.Unlock()
+	if dropped > 0 {
+		errs = append(errs, fmt.Errorf("gorker: %d more job errors dropped", dropped))
+	}
+	return errors.Join(errs...)
+}
+
+func WaitErrors() error {
+	return instance.WaitErrors()
+}
+
+// WaitErrors is Wait returning the errors of the jobs which failed since the previous call,
+// joined by errors.Join and annotated with the ID of their job, or nil when none failed.
+// The first 1024 errors are kept, later ones are only counted until the next call.
+func (d *Dispatcher) WaitErrors() error {
+	d.Wait()
+	return d.errs.take()
+}
@@ -0,0 +1,42 @@
+package gorker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDispatcher_WaitErrors(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	errA, errB := errors.New("a"), errors.New("b")
+	d.Add(func() error { return errA })
+	d.Add(func() error { return nil })
+	d.Add(func() error { return errB })
+	err := d.WaitErrors()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("WaitErrors() = %v, want a and b", err)
+	}
+	if err := d.WaitErrors(); err != nil {
+		t.Errorf("second WaitErrors() = %v, want nil", err)
+	}
+	d.Add(func() error { return nil })
+	if err := d.WaitErrors(); err != nil {
+		t.Errorf("WaitErrors() after success = %v, want nil", err)
+	}
+}
+
+func TestJobErrors_dropped(t *testing.T) {
+	e := new(jobErrors)
+	for i := 0; i < retainedFailures+3; i++ {
+		e.add(JobID(i+1), errors.New("failed"))
+	}
+	err := e.take()
+	if !strings.Contains(err.Error(), "3 more job errors dropped") {
+		t.Errorf("error does not report the dropped errors: %v", err)
+	}
+	if got := len(err.(interface{ Unwrap() []error }).Unwrap()); got != retainedFailures+1 {
+		t.Errorf("errors = %d, want %d", got, retainedFailures+1)
+	}
+}
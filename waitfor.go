@@ -0,0 +1,88 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownJob is returned by WaitFor for IDs which were not assigned by the Dispatcher
+// or whose result is no longer retained
+var ErrUnknownJob = errors.New("gorker: unknown job")
+
+// retainedFailures is how many errors of failed jobs are kept for WaitFor
+const retainedFailures = 1024
+
+// fail retains the error of a failed job, t.mu must be held
+func (t *tracker) fail(id JobID, err error) {
+	if len(t.failures) == retainedFailures {
+		old := t.failures[0]
+		delete(t.failed, old)
+		t.forgotten = max(t.forgotten, old)
+		t.failures = t.failures[1:]
+	}
+	t.failed[id] = err
+	t.failures = append(t.failures, id)
+}
+
+func AddWithID(fn JobFunc, opts ...JobOption) (JobID, chan error) {
+	return instance.AddWithID(fn, opts...)
+}
+
+// AddWithID is AddJob also returning the ID of the job, see WaitFor
+func (d *Dispatcher) AddWithID(fn JobFunc, opts ...JobOption) (JobID, chan error) {
+	id := d.track()
+	return id, d.AddJob(fn, append(opts, withID(id))...)
+}
+
+func WaitFor(ctx context.Context, ids ...JobID) error {
+	return instance.WaitFor(ctx, ids...)
+}
+
+// WaitFor blocks until the jobs of ids completed or ctx is done, and returns the errors of
+// the failed ones joined by errors.Join together with ctx.Err() when ctx was done first.
+// Each error is annotated with the ID of its job. The errors of the last 1024 failures are
+// retained, a job which completed before older failures were dropped fails with ErrUnknownJob.
+// The error channel of a job still receives its result.
+func (d *Dispatcher) WaitFor(ctx context.Context, ids ...JobID) error {
+	t := d.tracker
+	var (
+		errs    []error
+		waiting = make(map[JobID]chan error)
+	)
+	t.mu.Lock()
+	for _, id := range ids {
+		if _, ok := waiting[id]; ok {
+			continue
+		}
+		if chs, ok := t.pending[id]; ok {
+			ch := make(chan error, 1)
+			t.pending[id] = append(chs, ch)
+			waiting[id] = ch
+			continue
+		}
+		var err error
+		switch failed, ok := t.failed[id]; {
+		case ok:
+			err = failed
+		case id == 0 || id > t.last || id <= t.forgotten:
+			err = ErrUnknownJob
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job %d: %w", id, err))
+		}
+	}
+	t.mu.Unlock()
+
+	for id, ch := range waiting {
+		select {
+		case err := <-ch:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("job %d: %w", id, err))
+			}
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package gorker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_WaitFor(t *testing.T) {
+	d := New(2).QueueRunner().Start()
+	defer d.Stop(true)
+
+	fail := errors.New("fail")
+	block := make(chan struct{})
+	done, _ := d.AddWithID(func(context.Context) error { return nil })
+	failed, _ := d.AddWithID(func(context.Context) error { return fail })
+	blocked, _ := d.AddWithID(func(context.Context) error {
+		<-block
+		return fail
+	})
+	d.Go(func() {})
+
+	tests := []struct {
+		name    string
+		ids     []JobID
+		timeout time.Duration
+		want    []error
+	}{
+		{name: "succeeded", ids: []JobID{done}},
+		{name: "failed", ids: []JobID{done, failed}, want: []error{fail}},
+		{name: "unknown", ids: []JobID{0, blocked + 100}, want: []error{ErrUnknownJob}},
+		{name: "timeout", ids: []JobID{done, blocked}, timeout: 10 * time.Millisecond, want: []error{context.DeadlineExceeded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			err := d.WaitFor(ctx, tt.ids...)
+			if (err == nil) != (len(tt.want) == 0) {
+				t.Fatalf("WaitFor = %v, want %v", err, tt.want)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("WaitFor = %v, want it to contain %v", err, want)
+				}
+			}
+		})
+	}
+
+	close(block)
+	if err := d.WaitFor(context.Background(), blocked, blocked); !errors.Is(err, fail) {
+		t.Errorf("WaitFor = %v, want %v", err, fail)
+	}
+}
+
+func TestTracker_fail(t *testing.T) {
+	d := New(1)
+	first := d.track()
+	d.tracker.untrack(first, errors.New("first"))
+	for i := 0; i < retainedFailures; i++ {
+		d.tracker.untrack(d.track(), errors.New("later"))
+	}
+	succeeded := d.track()
+	d.tracker.untrack(succeeded, nil)
+
+	if err := d.WaitFor(context.Background(), first); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("WaitFor of a dropped failure = %v, want %v", err, ErrUnknownJob)
+	}
+	if err := d.WaitFor(context.Background(), succeeded); err != nil {
+		t.Errorf("WaitFor of a succeeded job = %v", err)
+	}
+	if n := len(d.tracker.failed); n != retainedFailures {
+		t.Errorf("%d failures retained, want %d", n, retainedFailures)
+	}
+}
@@ -0,0 +1,134 @@
+package gorker
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// hotFactor is how much more busy time than the average marks a worker as hot
+const hotFactor = 2
+
+// WorkerStat is a point in time view of a worker goroutine
+type WorkerStat struct {
+	// Index is the position of the worker, see AddToWorker
+	Index int `json:"index"`
+	// ID identifies the worker goroutine like WorkerScope.ID, 0 before it started
+	ID uint64 `json:"id"`
+	// Jobs is the number of jobs the worker finished
+	Jobs uint64 `json:"jobs"`
+	// Busy is the time spent running jobs and Idle the remaining time since the worker first started
+	Busy time.Duration `json:"busy_ns"`
+	Idle time.Duration `json:"idle_ns"`
+	// Current is the job being run, 0 when idle
+	Current JobID `json:"current,omitempty"`
+	// Hot is set when the worker was busy more than twice the average of the workers
+	Hot bool `json:"hot,omitempty"`
+}
+
+// workerStats holds the counters of a worker, updated by its goroutine
+type workerStats struct {
+	id      uint64
+	first   int64
+	jobs    uint64
+	busy    int64
+	current uint64
+	since   int64
+	// goroutine is the runtime ID of the worker goroutine and tags the tags of the current job, see DumpState
+	goroutine uint64
+	tags      atomic.Pointer[[]string]
+}
+
+func WorkerStats() []WorkerStat {
+	return instance.WorkerStats()
+}
+
+// WorkerStats returns the counters of the current workers of d, on demand workers are not reported
+func (d *Dispatcher) WorkerStats() []WorkerStat {
+	now := d.clock.Now().UnixNano()
+	d.mu.RLock()
+	stats := make([]WorkerStat, len(d.workers))
+	for i, w := range d.workers {
+		stats[i] = w.stats.stat(i, now)
+	}
+	d.mu.RUnlock()
+	var total time.Duration
+	for _, s := range stats {
+		total += s.Busy
+	}
+	if len(stats) > 1 && total > 0 {
+		avg := total / time.Duration(len(stats))
+		for i := range stats {
+			stats[i].Hot = stats[i].Busy > avg*hotFactor
+		}
+	}
+	return stats
+}
+
+// Utilization returns the share of time the workers in stats spent running jobs, between 0 and 1
+func Utilization(stats []WorkerStat) float64 {
+	var busy, idle time.Duration
+	for _, s := range stats {
+		busy += s.Busy
+		idle += s.Idle
+	}
+	if busy+idle <= 0 {
+		return 0
+	}
+	return float64(busy) / float64(busy+idle)
+}
+
+func (s *workerStats) started(id, goroutine uint64, now time.Time) {
+	atomic.StoreUint64(&s.id, id)
+	atomic.StoreUint64(&s.goroutine, goroutine)
+	atomic.CompareAndSwapInt64(&s.first, 0, now.UnixNano())
+}
+
+func (s *workerStats) begin(j *job, now time.Time) {
+	atomic.StoreInt64(&s.since, now.UnixNano())
+	atomic.StoreUint64(&s.current, uint64(j.id))
+	if len(j.tags) > 0 {
+		// the slice header is copied since reused jobs get new tags
+		tags := j.tags
+		s.tags.Store(&tags)
+	}
+}
+
+func (s *workerStats) end(now time.Time) {
+	atomic.StoreUint64(&s.current, 0)
+	s.tags.Store(nil)
+	atomic.AddInt64(&s.busy, now.UnixNano()-atomic.LoadInt64(&s.since))
+	atomic.AddUint64(&s.jobs, 1)
+}
+
+func (s *workerStats) stat(index int, now int64) WorkerStat {
+	st := WorkerStat{
+		Index:   index,
+		ID:      atomic.LoadUint64(&s.id),
+		Jobs:    atomic.LoadUint64(&s.jobs),
+		Busy:    time.Duration(atomic.LoadInt64(&s.busy)),
+		Current: JobID(atomic.LoadUint64(&s.current)),
+	}
+	if st.Current != 0 {
+		st.Busy += time.Duration(now - atomic.LoadInt64(&s.since))
+	}
+	if first := atomic.LoadInt64(&s.first); first != 0 && now-first > int64(st.Busy) {
+		st.Idle = time.Duration(now-first) - st.Busy
+	}
+	return st
+}
+
+// run runs j on w and accounts for it in the stats of w
+func (w *worker) run(j *job, scope *WorkerScope) {
+	d := w.dis
+	now := d.clock.Now()
+	w.stats.begin(j, now)
+	if d.burst != nil {
+		d.burst.dequeued(j, now)
+	}
+	if d.slo != nil {
+		d.slo.dequeued(j, now)
+	}
+	atomic.AddUint64(&d.health.dequeued, 1)
+	d.runJob(j, scope)
+	w.stats.end(d.clock.Now())
+}
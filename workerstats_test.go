@@ -0,0 +1,76 @@
+package gorker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDispatcher_WorkerStats(t *testing.T) {
+	d := New(4).QueueRunner().Start()
+	defer d.Stop(true)
+
+	for i := 0; i < 5; i++ {
+		d.AddToWorker(0, func() error {
+			time.Sleep(2 * time.Millisecond)
+			return nil
+		})
+	}
+	d.AddToWorker(1, func() error { return nil })
+	d.Wait()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	ech := d.AddToWorker(2, func() error {
+		close(started)
+		<-block
+		return nil
+	})
+	<-started
+
+	stats := d.WorkerStats()
+	if len(stats) != 4 {
+		t.Fatalf("len(WorkerStats()) = %d, want 4", len(stats))
+	}
+	for i, want := range []uint64{5, 1, 0, 0} {
+		if stats[i].Jobs != want {
+			t.Errorf("worker %d jobs = %d, want %d", i, stats[i].Jobs, want)
+		}
+		if stats[i].Index != i || stats[i].ID == 0 {
+			t.Errorf("worker %d index = %d, id = %d", i, stats[i].Index, stats[i].ID)
+		}
+	}
+	if !stats[0].Hot || stats[1].Hot {
+		t.Errorf("hot workers = %v %v, want true false", stats[0].Hot, stats[1].Hot)
+	}
+	if stats[0].Busy < 10*time.Millisecond {
+		t.Errorf("worker 0 busy = %s, want at least 10ms", stats[0].Busy)
+	}
+	if stats[2].Current == 0 || stats[3].Current != 0 {
+		t.Errorf("current jobs = %d %d", stats[2].Current, stats[3].Current)
+	}
+	if u := Utilization(stats); u <= 0 || u >= 1 {
+		t.Errorf("Utilization() = %v, want between 0 and 1", u)
+	}
+	close(block)
+	<-ech
+}
+
+func TestUtilization(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats []WorkerStat
+		want  float64
+	}{
+		{name: "no workers"},
+		{name: "idle", stats: []WorkerStat{{Idle: time.Second}}},
+		{name: "half busy", stats: []WorkerStat{{Busy: time.Second}, {Idle: time.Second}}, want: 0.5},
+		{name: "busy", stats: []WorkerStat{{Busy: time.Second}}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Utilization(tt.stats); got != tt.want {
+				t.Errorf("Utilization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}